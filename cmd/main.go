@@ -4,11 +4,21 @@ import (
 	"context"
 	"cosplayrent/internal/config"
 	"cosplayrent/internal/exception"
+	"cosplayrent/internal/repository"
+	"cosplayrent/internal/storage"
+	"cosplayrent/internal/usecase"
+	"database/sql"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"path"
+	"strings"
 	"syscall"
 	"time"
+
+	"github.com/knadh/koanf/v2"
+	"github.com/rs/zerolog"
 )
 
 func CORS(next http.Handler) http.Handler {
@@ -26,6 +36,40 @@ func CORS(next http.Handler) http.Handler {
 	})
 }
 
+// publicDir keeps private uploads such as identity cards out of the static file server.
+type publicDir struct {
+	http.Dir
+}
+
+func (dir publicDir) Open(name string) (http.File, error) {
+	if strings.HasPrefix(path.Clean("/"+name), "/identity_card") {
+		return nil, os.ErrNotExist
+	}
+	return dir.Dir.Open(name)
+}
+
+// encryptIdentityCards moves the identity card scans uploaded before encryption out of the public store. It runs on
+// every start, and "go run . encrypt-identity-cards" runs it alone to retry cards that failed to move.
+func encryptIdentityCards(db *sql.DB, publicStorage storage.Storage, privateStorage storage.Storage, log *zerolog.Logger, koanf *koanf.Koanf) {
+	userUsecase := &usecase.UserUsecase{
+		UserRepository: repository.NewUserRepository(log),
+		Storage:        publicStorage,
+		PrivateStorage: privateStorage,
+		DB:             db,
+		Log:            log,
+		Config:         koanf,
+	}
+
+	moved, left := userUsecase.EncryptLegacyIdentityCards(context.Background())
+
+	if moved > 0 {
+		log.Info().Msg(fmt.Sprintf("Encrypted %d legacy identity cards", moved))
+	}
+	if left > 0 {
+		log.Warn().Msg(fmt.Sprintf("%d legacy identity cards are still in the public store, run encrypt-identity-cards again", left))
+	}
+}
+
 func main() {
 	router := config.NewRouter()
 	zerolog := config.NewZeroLog()
@@ -33,17 +77,33 @@ func main() {
 	db := config.NewDB(koanf, &zerolog)
 	memcacheClient := config.NewMemcacheClient(koanf)
 	validator := config.NewValidator()
+	storage := config.NewStorage(koanf)
+	privateStorage := config.NewPrivateStorage(koanf)
+
+	if len(os.Args) > 1 && os.Args[1] == "encrypt-identity-cards" {
+		encryptIdentityCards(db, storage, privateStorage, &zerolog, koanf)
+		return
+	}
+
+	// the public store is readable by anyone, with the s3 driver straight from the bucket, so legacy scans are moved
+	// before the server starts, later starts find none left
+	encryptIdentityCards(db, storage, privateStorage, &zerolog, koanf)
 
 	config.Server(&config.ServerConfig{
-		Router:   router,
-		DB:       db,
-		Memcache: memcacheClient,
-		Log:      &zerolog,
-		Validate: validator,
-		Config:   koanf,
+		Router:         router,
+		DB:             db,
+		Memcache:       memcacheClient,
+		Storage:        storage,
+		PrivateStorage: privateStorage,
+		Log:            &zerolog,
+		Validate:       validator,
+		Config:         koanf,
 	})
 
-	router.ServeFiles("/static/*filepath", http.Dir("../static"))
+	// with the s3 driver media is served straight from the bucket
+	if koanf.String("STORAGE_DRIVER") != "s3" {
+		router.ServeFiles("/static/*filepath", publicDir{http.Dir(koanf.String("STORAGE_LOCAL_DIR"))})
+	}
 	router.PanicHandler = exception.ErrorHandler
 
 	GO_SERVER_PORT := koanf.String("GO_SERVER")
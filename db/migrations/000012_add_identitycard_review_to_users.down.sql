@@ -0,0 +1,7 @@
+ALTER TABLE users
+    DROP COLUMN IF EXISTS identitycard_reviewed_at,
+    DROP COLUMN IF EXISTS identitycard_reviewed_by,
+    DROP COLUMN IF EXISTS identitycard_submitted_at,
+    DROP COLUMN IF EXISTS identitycard_rejection_reason,
+    DROP COLUMN IF EXISTS identitycard_status,
+    DROP COLUMN IF EXISTS role;
@@ -0,0 +1,9 @@
+ALTER TABLE users
+    ADD COLUMN role varchar(5) NOT NULL DEFAULT 'User',
+    ADD COLUMN identitycard_status varchar(9),
+    ADD COLUMN identitycard_rejection_reason text,
+    ADD COLUMN identitycard_submitted_at timestamp,
+    ADD COLUMN identitycard_reviewed_by char(36),
+    ADD COLUMN identitycard_reviewed_at timestamp;
+
+UPDATE users SET identitycard_status = 'Submitted', identitycard_submitted_at = updated_at WHERE identitycard_picture IS NOT NULL;
@@ -0,0 +1 @@
+DELETE FROM notifications WHERE template_name IN ('identitycard_approved', 'identitycard_rejected');
@@ -0,0 +1,15 @@
+INSERT INTO notifications (template_name, template_subject, template_body, created_at, updated_at) VALUES
+(
+    'identitycard_approved',
+    'CosplayRent - Identity Card Approved',
+    '<p>Hi {{.Username}},</p><p>Your identity card has been approved. You can now rent and list costumes on CosplayRent.</p>',
+    now(),
+    now()
+),
+(
+    'identitycard_rejected',
+    'CosplayRent - Identity Card Rejected',
+    '<p>Hi {{.Username}},</p><p>Unfortunately your identity card could not be approved.</p><p>Reason: {{.Reason}}</p><p>Please upload a new identity card from your profile.</p>',
+    now(),
+    now()
+);
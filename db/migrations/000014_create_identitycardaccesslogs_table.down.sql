@@ -0,0 +1 @@
+DROP TABLE IF EXISTS identitycard_access_logs;
\ No newline at end of file
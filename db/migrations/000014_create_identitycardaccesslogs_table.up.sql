@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS identitycard_access_logs(
+    id serial PRIMARY KEY,
+    owner_id char(36) NOT NULL,
+    accessor_id char(36) NOT NULL,
+    access_role varchar(10) NOT NULL,
+    granted boolean NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (owner_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (accessor_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS identitycard_access_logs_owner_id_idx ON identitycard_access_logs(owner_id);
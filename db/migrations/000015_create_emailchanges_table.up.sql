@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS email_changes(
+    id serial PRIMARY KEY,
+    user_id char(36) UNIQUE NOT NULL,
+    new_email varchar(254) NOT NULL,
+    verification_code char(5) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    expired_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1 @@
+DELETE FROM notifications WHERE template_name IN ('email_change_verification', 'email_changed');
\ No newline at end of file
@@ -0,0 +1,15 @@
+INSERT INTO notifications (template_name, template_subject, template_body, created_at, updated_at) VALUES
+(
+    'email_change_verification',
+    'CosplayRent - Confirm Your New Email',
+    '<p>Hi {{.Username}},</p><p>Use this code to confirm your new email address: <b>{{.Code}}</b></p><p>The code expires in 5 minutes. If you did not request this change, you can ignore this email.</p>',
+    now(),
+    now()
+),
+(
+    'email_changed',
+    'CosplayRent - Your Email Has Been Changed',
+    '<p>Hi {{.Username}},</p><p>The email address on your CosplayRent account has been changed to {{.NewEmail}}.</p><p>If you did not make this change, please contact us immediately.</p>',
+    now(),
+    now()
+);
@@ -0,0 +1,13 @@
+ALTER TABLE users
+    ADD COLUMN totp_secret text,
+    ADD COLUMN totp_enabled boolean NOT NULL DEFAULT false,
+    ADD COLUMN totp_last_step bigint;
+
+CREATE TABLE IF NOT EXISTS user_recovery_codes(
+    id serial PRIMARY KEY,
+    user_id char(36) NOT NULL,
+    code_hash varchar(60) NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS orders_costume_id_idx;
+DROP INDEX IF EXISTS reviews_costume_id_idx;
+DROP INDEX IF EXISTS costumes_category_id_idx;
+DROP INDEX IF EXISTS costumes_available_price_idx;
+DROP INDEX IF EXISTS costumes_available_created_at_idx;
@@ -0,0 +1,5 @@
+CREATE INDEX IF NOT EXISTS costumes_available_created_at_idx ON costumes(available, created_at, id);
+CREATE INDEX IF NOT EXISTS costumes_available_price_idx ON costumes(available, price, id);
+CREATE INDEX IF NOT EXISTS costumes_category_id_idx ON costumes(category_id);
+CREATE INDEX IF NOT EXISTS reviews_costume_id_idx ON reviews(costume_id);
+CREATE INDEX IF NOT EXISTS orders_costume_id_idx ON orders(costume_id);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS costumes_name_trgm_idx;
+DROP INDEX IF EXISTS costumes_search_vector_idx;
+DROP TRIGGER IF EXISTS categories_search_vector_trigger ON categories;
+DROP FUNCTION IF EXISTS categories_search_vector_refresh();
+DROP TRIGGER IF EXISTS costumes_search_vector_trigger ON costumes;
+DROP FUNCTION IF EXISTS costumes_search_vector_update();
+ALTER TABLE costumes DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,41 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE costumes ADD COLUMN search_vector tsvector;
+
+CREATE OR REPLACE FUNCTION costumes_search_vector_update() RETURNS trigger AS $$
+DECLARE
+    category_name text;
+BEGIN
+    SELECT name INTO category_name FROM categories WHERE id = NEW.category_id;
+
+    NEW.search_vector :=
+        setweight(to_tsvector('simple', coalesce(NEW.name, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(NEW.name, '')), 'A') ||
+        setweight(to_tsvector('indonesian', coalesce(NEW.name, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(category_name, '')), 'B') ||
+        setweight(to_tsvector('simple', coalesce(NEW.material, '')), 'C') ||
+        setweight(to_tsvector('english', coalesce(NEW.description, '')), 'D') ||
+        setweight(to_tsvector('indonesian', coalesce(NEW.description, '')), 'D');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER costumes_search_vector_trigger
+    BEFORE INSERT OR UPDATE OF name, description, material, category_id ON costumes
+    FOR EACH ROW EXECUTE FUNCTION costumes_search_vector_update();
+
+CREATE OR REPLACE FUNCTION categories_search_vector_refresh() RETURNS trigger AS $$
+BEGIN
+    UPDATE costumes SET category_id = category_id WHERE category_id = NEW.id;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER categories_search_vector_trigger
+    AFTER UPDATE OF name ON categories
+    FOR EACH ROW EXECUTE FUNCTION categories_search_vector_refresh();
+
+UPDATE costumes SET name = name;
+
+CREATE INDEX IF NOT EXISTS costumes_search_vector_idx ON costumes USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS costumes_name_trgm_idx ON costumes USING GIN (name gin_trgm_ops);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS users_name_trgm_idx;
+DROP INDEX IF EXISTS categories_name_trgm_idx;
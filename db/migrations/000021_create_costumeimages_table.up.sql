@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS costume_images(
+    id serial PRIMARY KEY,
+    costume_id int NOT NULL,
+    path varchar(255) NOT NULL,
+    position int NOT NULL,
+    is_cover boolean NOT NULL DEFAULT false,
+    created_at timestamp NOT NULL,
+    FOREIGN KEY (costume_id) REFERENCES costumes(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS costume_images_costume_id_idx ON costume_images(costume_id, position);
+CREATE UNIQUE INDEX IF NOT EXISTS costume_images_cover_idx ON costume_images(costume_id) WHERE is_cover;
+
+INSERT INTO costume_images (costume_id, path, position, is_cover, created_at)
+SELECT id, costume_picture, 0, true, created_at FROM costumes;
@@ -0,0 +1,4 @@
+UPDATE costumes SET costume_picture = '/static/' || costume_picture WHERE costume_picture IS NOT NULL AND costume_picture NOT LIKE '/static/%';
+UPDATE costume_images SET path = '/static/' || path WHERE path NOT LIKE '/static/%';
+UPDATE users SET profile_picture = '/static/' || profile_picture WHERE profile_picture IS NOT NULL AND profile_picture NOT LIKE '/static/%';
+UPDATE reviews SET review_picture = '/static/' || review_picture WHERE review_picture IS NOT NULL AND review_picture NOT LIKE '/static/%';
@@ -0,0 +1,4 @@
+UPDATE costumes SET costume_picture = substr(costume_picture, 9) WHERE costume_picture LIKE '/static/%';
+UPDATE costume_images SET path = substr(path, 9) WHERE path LIKE '/static/%';
+UPDATE users SET profile_picture = substr(profile_picture, 9) WHERE profile_picture LIKE '/static/%';
+UPDATE reviews SET review_picture = substr(review_picture, 9) WHERE review_picture LIKE '/static/%';
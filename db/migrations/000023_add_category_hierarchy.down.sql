@@ -0,0 +1,8 @@
+ALTER TABLE costumes DROP CONSTRAINT IF EXISTS costumes_category_id_fkey;
+ALTER TABLE costumes ADD CONSTRAINT costumes_category_id_fkey FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE;
+
+DROP INDEX IF EXISTS categories_parent_id_idx;
+DROP INDEX IF EXISTS categories_slug_idx;
+
+ALTER TABLE categories DROP COLUMN IF EXISTS slug;
+ALTER TABLE categories DROP COLUMN IF EXISTS parent_id;
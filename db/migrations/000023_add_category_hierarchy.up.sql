@@ -0,0 +1,15 @@
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS parent_id int REFERENCES categories(id) ON DELETE RESTRICT;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS slug varchar(255);
+
+UPDATE categories SET slug = trim(BOTH '-' FROM regexp_replace(lower(name), '[^a-z0-9]+', '-', 'g'));
+UPDATE categories SET slug = 'category-' || id WHERE slug = '';
+UPDATE categories c SET slug = c.slug || '-' || c.id
+WHERE EXISTS (SELECT 1 FROM categories o WHERE o.slug = c.slug AND o.id < c.id);
+
+ALTER TABLE categories ALTER COLUMN slug SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS categories_slug_idx ON categories(slug);
+CREATE INDEX IF NOT EXISTS categories_parent_id_idx ON categories(parent_id);
+
+ALTER TABLE costumes DROP CONSTRAINT IF EXISTS costumes_category_id_fkey;
+ALTER TABLE costumes ADD CONSTRAINT costumes_category_id_fkey FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE RESTRICT;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS costume_attributes;
+DROP TABLE IF EXISTS category_attributes;
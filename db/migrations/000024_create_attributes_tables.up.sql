@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS category_attributes(
+    id serial PRIMARY KEY,
+    category_id int NOT NULL,
+    key varchar(50) NOT NULL,
+    label varchar(100) NOT NULL,
+    type varchar(10) NOT NULL CHECK (type IN ('text', 'number', 'boolean', 'enum', 'multi_enum')),
+    options jsonb NOT NULL DEFAULT '[]',
+    required boolean NOT NULL DEFAULT false,
+    created_at timestamp NOT NULL,
+    updated_at timestamp NOT NULL,
+    FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE,
+    UNIQUE (category_id, key)
+);
+
+CREATE TABLE IF NOT EXISTS costume_attributes(
+    costume_id int NOT NULL,
+    attribute_id int NOT NULL,
+    value jsonb NOT NULL,
+    PRIMARY KEY (costume_id, attribute_id),
+    FOREIGN KEY (costume_id) REFERENCES costumes(id) ON DELETE CASCADE,
+    FOREIGN KEY (attribute_id) REFERENCES category_attributes(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS costume_attributes_attribute_id_idx ON costume_attributes(attribute_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_measurements;
+DROP TABLE IF EXISTS costume_measurements;
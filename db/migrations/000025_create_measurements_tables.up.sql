@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS costume_measurements(
+    costume_id int PRIMARY KEY,
+    chest decimal(5,1),
+    waist decimal(5,1),
+    hip decimal(5,1),
+    height_min decimal(5,1),
+    height_max decimal(5,1),
+    shoe_size decimal(4,1),
+    updated_at timestamp NOT NULL,
+    FOREIGN KEY (costume_id) REFERENCES costumes(id) ON DELETE CASCADE,
+    CHECK (height_min IS NULL OR height_max IS NULL OR height_min <= height_max)
+);
+
+CREATE TABLE IF NOT EXISTS user_measurements(
+    user_id char(36) PRIMARY KEY,
+    chest decimal(5,1),
+    waist decimal(5,1),
+    hip decimal(5,1),
+    height decimal(5,1),
+    shoe_size decimal(4,1),
+    updated_at timestamp NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
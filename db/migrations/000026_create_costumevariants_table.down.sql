@@ -0,0 +1,4 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS reservation_released;
+ALTER TABLE orders DROP COLUMN IF EXISTS variant_id;
+
+DROP TABLE IF EXISTS costume_variants;
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS costume_variants(
+    id serial PRIMARY KEY,
+    costume_id int NOT NULL,
+    size varchar(30) NOT NULL,
+    quantity int NOT NULL,
+    reserved int NOT NULL DEFAULT 0,
+    price decimal(10,2),
+    created_at timestamp NOT NULL,
+    updated_at timestamp NOT NULL,
+    FOREIGN KEY (costume_id) REFERENCES costumes(id) ON DELETE CASCADE,
+    CHECK (quantity >= 0 AND reserved >= 0 AND reserved <= quantity)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS costume_variants_size_idx ON costume_variants(costume_id, lower(size));
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS variant_id int REFERENCES costume_variants(id) ON DELETE SET NULL;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS reservation_released boolean NOT NULL DEFAULT false;
+
+-- every existing listing becomes a single variant, and each order that is still running holds one of its units
+WITH latest AS (
+    SELECT o.id, o.costume_id,
+        COALESCE((SELECT e.status FROM order_events e WHERE e.order_id = o.id ORDER BY e.created_at DESC LIMIT 1), '') IN ('Completed', 'Cancelled') AS finished
+    FROM orders o
+)
+INSERT INTO costume_variants (costume_id, size, quantity, reserved, created_at, updated_at)
+SELECT c.id, c.size, GREATEST(1, active.count), active.count, c.created_at, c.updated_at
+FROM costumes c
+CROSS JOIN LATERAL (SELECT COUNT(*)::int AS count FROM latest WHERE latest.costume_id = c.id AND NOT latest.finished) active;
+
+UPDATE orders o SET variant_id = v.id FROM costume_variants v WHERE v.costume_id = o.costume_id;
+
+UPDATE orders o SET reservation_released = true
+WHERE COALESCE((SELECT e.status FROM order_events e WHERE e.order_id = o.id ORDER BY e.created_at DESC LIMIT 1), '') IN ('Completed', 'Cancelled');
@@ -0,0 +1,17 @@
+ALTER TABLE reviews DROP CONSTRAINT IF EXISTS reviews_costume_id_fkey;
+ALTER TABLE reviews ADD CONSTRAINT reviews_costume_id_fkey FOREIGN KEY (costume_id) REFERENCES costumes(id) ON DELETE CASCADE;
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_costume_id_fkey;
+ALTER TABLE orders ADD CONSTRAINT orders_costume_id_fkey FOREIGN KEY (costume_id) REFERENCES costumes(id) ON DELETE CASCADE;
+
+DROP INDEX IF EXISTS costumes_status_price_idx;
+DROP INDEX IF EXISTS costumes_status_created_at_idx;
+
+ALTER TABLE costumes ADD COLUMN IF NOT EXISTS available VARCHAR(13) DEFAULT 'Ready';
+UPDATE costumes SET available = CASE WHEN status = 'published' THEN 'Ready' ELSE 'Not Ready' END;
+
+CREATE INDEX IF NOT EXISTS costumes_available_created_at_idx ON costumes(available, created_at, id);
+CREATE INDEX IF NOT EXISTS costumes_available_price_idx ON costumes(available, price, id);
+
+ALTER TABLE costumes DROP CONSTRAINT IF EXISTS costumes_status_check;
+ALTER TABLE costumes DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE costumes DROP COLUMN IF EXISTS status;
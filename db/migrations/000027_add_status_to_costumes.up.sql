@@ -0,0 +1,19 @@
+ALTER TABLE costumes ADD COLUMN IF NOT EXISTS status varchar(10) NOT NULL DEFAULT 'published';
+ALTER TABLE costumes ADD COLUMN IF NOT EXISTS archived_at timestamp;
+
+UPDATE costumes SET status = CASE WHEN available = 'Ready' THEN 'published' ELSE 'paused' END;
+
+ALTER TABLE costumes ADD CONSTRAINT costumes_status_check CHECK (status IN ('draft', 'published', 'paused', 'archived'));
+
+DROP INDEX IF EXISTS costumes_available_created_at_idx;
+DROP INDEX IF EXISTS costumes_available_price_idx;
+ALTER TABLE costumes DROP COLUMN IF EXISTS available;
+
+CREATE INDEX IF NOT EXISTS costumes_status_created_at_idx ON costumes(status, created_at, id);
+CREATE INDEX IF NOT EXISTS costumes_status_price_idx ON costumes(status, price, id);
+
+-- listings are archived instead of deleted, so orders and reviews must never be removed through them
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_costume_id_fkey;
+ALTER TABLE orders ADD CONSTRAINT orders_costume_id_fkey FOREIGN KEY (costume_id) REFERENCES costumes(id);
+ALTER TABLE reviews DROP CONSTRAINT IF EXISTS reviews_costume_id_fkey;
+ALTER TABLE reviews ADD CONSTRAINT reviews_costume_id_fkey FOREIGN KEY (costume_id) REFERENCES costumes(id);
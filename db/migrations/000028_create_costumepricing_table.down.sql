@@ -0,0 +1,4 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS end_date;
+ALTER TABLE orders DROP COLUMN IF EXISTS start_date;
+
+DROP TABLE IF EXISTS costume_pricing;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS costume_pricing(
+    costume_id int PRIMARY KEY,
+    min_days int NOT NULL DEFAULT 1,
+    max_days int,
+    weekend_surcharge_percent decimal(5,2) NOT NULL DEFAULT 0,
+    discounts jsonb NOT NULL DEFAULT '[]',
+    date_rules jsonb NOT NULL DEFAULT '[]',
+    updated_at timestamp NOT NULL,
+    FOREIGN KEY (costume_id) REFERENCES costumes(id) ON DELETE CASCADE,
+    CHECK (min_days >= 1 AND (max_days IS NULL OR max_days >= min_days))
+);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS start_date date;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS end_date date;
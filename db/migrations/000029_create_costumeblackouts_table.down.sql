@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS orders_variant_id_start_date_idx;
+
+ALTER TABLE costume_variants DROP CONSTRAINT IF EXISTS costume_variants_check;
+ALTER TABLE costume_variants ADD CONSTRAINT costume_variants_check CHECK (quantity >= 0 AND reserved >= 0 AND reserved <= quantity) NOT VALID;
+
+ALTER TABLE costumes DROP CONSTRAINT IF EXISTS costumes_turnaround_days_check;
+ALTER TABLE costumes DROP COLUMN IF EXISTS turnaround_days;
+
+DROP TABLE IF EXISTS costume_blackouts;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS costume_blackouts(
+    id serial PRIMARY KEY,
+    costume_id int NOT NULL,
+    start_date date NOT NULL,
+    end_date date NOT NULL,
+    reason varchar(100),
+    created_at timestamp NOT NULL,
+    FOREIGN KEY (costume_id) REFERENCES costumes(id) ON DELETE CASCADE,
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS costume_blackouts_costume_id_idx ON costume_blackouts (costume_id, start_date);
+
+ALTER TABLE costumes ADD COLUMN IF NOT EXISTS turnaround_days int NOT NULL DEFAULT 0;
+ALTER TABLE costumes ADD CONSTRAINT costumes_turnaround_days_check CHECK (turnaround_days BETWEEN 0 AND 30);
+
+-- units are booked per date now, a variant can hold more future orders than it has units
+ALTER TABLE costume_variants DROP CONSTRAINT IF EXISTS costume_variants_check;
+ALTER TABLE costume_variants ADD CONSTRAINT costume_variants_check CHECK (quantity >= 0 AND reserved >= 0);
+
+CREATE INDEX IF NOT EXISTS orders_variant_id_start_date_idx ON orders (variant_id, start_date);
@@ -0,0 +1,5 @@
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_vacation_check,
+    DROP COLUMN IF EXISTS vacation_start,
+    DROP COLUMN IF EXISTS vacation_end,
+    DROP COLUMN IF EXISTS vacation_message;
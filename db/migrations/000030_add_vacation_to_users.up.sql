@@ -0,0 +1,5 @@
+ALTER TABLE users
+    ADD COLUMN vacation_start date,
+    ADD COLUMN vacation_end date,
+    ADD COLUMN vacation_message varchar(500),
+    ADD CONSTRAINT users_vacation_check CHECK (vacation_end >= vacation_start);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS order_events_order_id_idx;
+DROP INDEX IF EXISTS orders_seller_id_created_at_idx;
+DROP INDEX IF EXISTS costumes_user_id_idx;
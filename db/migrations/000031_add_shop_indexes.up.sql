@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS costumes_user_id_idx ON costumes(user_id, status);
+CREATE INDEX IF NOT EXISTS orders_seller_id_created_at_idx ON orders(seller_id, created_at);
+CREATE INDEX IF NOT EXISTS order_events_order_id_idx ON order_events(order_id, status);
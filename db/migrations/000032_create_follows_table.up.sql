@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS follows(
+    follower_id char(36) NOT NULL,
+    seller_id char(36) NOT NULL,
+    created_at timestamp NOT NULL,
+    PRIMARY KEY (follower_id, seller_id),
+    FOREIGN KEY (follower_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (seller_id) REFERENCES users(id) ON DELETE CASCADE,
+    CHECK (follower_id <> seller_id)
+);
+
+CREATE INDEX IF NOT EXISTS follows_seller_id_idx ON follows(seller_id);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS costume_activities(
+    id serial PRIMARY KEY,
+    costume_id int NOT NULL,
+    seller_id char(36) NOT NULL,
+    type varchar(20) NOT NULL,
+    created_at timestamp NOT NULL,
+    FOREIGN KEY (costume_id) REFERENCES costumes(id) ON DELETE CASCADE,
+    FOREIGN KEY (seller_id) REFERENCES users(id) ON DELETE CASCADE,
+    CHECK (type IN ('new_listing', 'restock'))
+);
+
+CREATE INDEX IF NOT EXISTS costume_activities_seller_id_idx ON costume_activities(seller_id, created_at, id);
+CREATE INDEX IF NOT EXISTS costume_activities_costume_id_idx ON costume_activities(costume_id, type, created_at);
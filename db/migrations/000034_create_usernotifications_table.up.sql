@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS user_notifications(
+    id serial PRIMARY KEY,
+    user_id char(36) NOT NULL,
+    type varchar(30) NOT NULL,
+    title varchar(255) NOT NULL,
+    body text NOT NULL,
+    costume_id int,
+    read_at timestamp,
+    created_at timestamp NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (costume_id) REFERENCES costumes(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS user_notifications_user_id_idx ON user_notifications(user_id, created_at, id);
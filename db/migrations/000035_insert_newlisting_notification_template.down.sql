@@ -0,0 +1 @@
+DELETE FROM notifications WHERE template_name = 'new_listing';
@@ -0,0 +1,8 @@
+INSERT INTO notifications (template_name, template_subject, template_body, created_at, updated_at) VALUES
+(
+    'new_listing',
+    'CosplayRent - New Costume From A Seller You Follow',
+    '<p>Hi {{.Username}},</p><p>{{.Seller}}, a seller you follow, just listed <b>{{.Costume}}</b>.</p><p>Open CosplayRent to see it before someone else rents it.</p>',
+    now(),
+    now()
+);
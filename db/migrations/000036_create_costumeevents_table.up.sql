@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS costume_events(
+    id serial PRIMARY KEY,
+    costume_id int NOT NULL,
+    type varchar(20) NOT NULL,
+    user_id char(36),
+    order_id char(36) UNIQUE,
+    created_at timestamp NOT NULL,
+    FOREIGN KEY (costume_id) REFERENCES costumes(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL,
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    CHECK (type IN ('view', 'wishlist', 'rental'))
+);
+
+CREATE INDEX IF NOT EXISTS costume_events_created_at_idx ON costume_events(created_at);
+CREATE UNIQUE INDEX IF NOT EXISTS costume_events_wishlist_idx ON costume_events(costume_id, user_id) WHERE type = 'wishlist';
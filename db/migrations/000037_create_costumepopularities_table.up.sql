@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS costume_popularities(
+    costume_id int PRIMARY KEY,
+    score double precision NOT NULL,
+    updated_at timestamp NOT NULL,
+    FOREIGN KEY (costume_id) REFERENCES costumes(id) ON DELETE CASCADE
+);
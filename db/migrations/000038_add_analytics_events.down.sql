@@ -0,0 +1,13 @@
+DELETE FROM costume_events WHERE type IN ('wishlist_remove', 'checkout');
+DELETE FROM costume_events a USING costume_events b
+WHERE a.type = 'wishlist' AND b.type = 'wishlist' AND a.costume_id = b.costume_id AND a.user_id = b.user_id AND a.id < b.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS costume_events_wishlist_idx ON costume_events(costume_id, user_id) WHERE type = 'wishlist';
+
+ALTER TABLE costume_events DROP CONSTRAINT IF EXISTS costume_events_order_id_type_key;
+ALTER TABLE costume_events ADD CONSTRAINT costume_events_order_id_key UNIQUE (order_id);
+
+ALTER TABLE costume_events DROP CONSTRAINT IF EXISTS costume_events_type_check;
+ALTER TABLE costume_events ADD CONSTRAINT costume_events_type_check CHECK (type IN ('view', 'wishlist', 'rental'));
+
+ALTER TABLE costume_events DROP COLUMN IF EXISTS amount;
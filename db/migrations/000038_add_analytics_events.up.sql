@@ -0,0 +1,11 @@
+ALTER TABLE costume_events ADD COLUMN IF NOT EXISTS amount decimal(10,2);
+
+ALTER TABLE costume_events DROP CONSTRAINT IF EXISTS costume_events_type_check;
+ALTER TABLE costume_events ADD CONSTRAINT costume_events_type_check CHECK (type IN ('view', 'wishlist', 'wishlist_remove', 'checkout', 'rental'));
+
+-- an order now leaves both a checkout and a rental event
+ALTER TABLE costume_events DROP CONSTRAINT IF EXISTS costume_events_order_id_key;
+ALTER TABLE costume_events ADD CONSTRAINT costume_events_order_id_type_key UNIQUE (order_id, type);
+
+-- every wishlist add is kept for the analytics, the trending score counts each user once itself
+DROP INDEX IF EXISTS costume_events_wishlist_idx;
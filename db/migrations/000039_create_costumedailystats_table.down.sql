@@ -0,0 +1 @@
+DROP TABLE IF EXISTS costume_daily_stats;
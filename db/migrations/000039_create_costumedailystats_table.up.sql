@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS costume_daily_stats(
+    costume_id int NOT NULL,
+    day date NOT NULL,
+    views int NOT NULL DEFAULT 0,
+    wishlist_adds int NOT NULL DEFAULT 0,
+    wishlist_removes int NOT NULL DEFAULT 0,
+    checkouts int NOT NULL DEFAULT 0,
+    completed_orders int NOT NULL DEFAULT 0,
+    revenue decimal(12,2) NOT NULL DEFAULT 0,
+    PRIMARY KEY (costume_id, day),
+    FOREIGN KEY (costume_id) REFERENCES costumes(id) ON DELETE CASCADE
+);
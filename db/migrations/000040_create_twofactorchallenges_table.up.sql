@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS two_factor_challenges(
+    id char(36) PRIMARY KEY,
+    user_id char(36) NOT NULL,
+    attempts int NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS two_factor_challenges_user_id_idx ON two_factor_challenges(user_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS costume_events_view_idx;
+
+ALTER TABLE costume_events DROP COLUMN IF EXISTS visitor_id;
@@ -0,0 +1,4 @@
+-- anonymous views carry a hash of the client so repeated views can be throttled like signed in ones
+ALTER TABLE costume_events ADD COLUMN IF NOT EXISTS visitor_id char(40);
+
+CREATE INDEX IF NOT EXISTS costume_events_view_idx ON costume_events(costume_id, created_at) WHERE type = 'view';
@@ -0,0 +1 @@
+-- the removed events cannot be restored
@@ -0,0 +1,6 @@
+-- completions used to be accepted from any signed in user, keep only the rentals the order's seller completed
+DELETE FROM costume_events ce
+WHERE ce.type = 'rental' AND NOT EXISTS (
+    SELECT 1 FROM orders o JOIN order_events e ON e.order_id = o.id
+    WHERE o.id = ce.order_id AND e.status = 'Completed' AND e.user_id = o.seller_id
+);
@@ -0,0 +1 @@
+-- the reservations restored by the up migration are still held by their orders
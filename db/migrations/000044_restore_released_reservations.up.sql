@@ -0,0 +1,15 @@
+-- closing events used to be accepted from any signed in user, an order only gives its unit back once its seller or
+-- customer closed it
+UPDATE orders o SET reservation_released = false
+WHERE o.reservation_released AND NOT EXISTS (
+    SELECT 1 FROM order_events e
+    WHERE e.order_id = o.id AND e.status IN ('Completed', 'Cancelled') AND e.user_id IN (o.seller_id, o.customer_id)
+);
+
+UPDATE costume_variants v SET reserved = LEAST(v.quantity, held.count)
+FROM (
+    SELECT cv.id, COUNT(o.id)::int AS count
+    FROM costume_variants cv LEFT JOIN orders o ON o.variant_id = cv.id AND NOT o.reservation_released
+    GROUP BY cv.id
+) held
+WHERE held.id = v.id AND held.count <> v.reserved;
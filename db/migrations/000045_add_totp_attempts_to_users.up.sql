@@ -0,0 +1,4 @@
+-- wrong two factor codes in a row on the account, 5 lock every code check for 15 minutes
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS totp_attempts int NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS totp_locked_until timestamp;
@@ -5,6 +5,7 @@ import (
 	"cosplayrent/internal/delivery/http/middleware"
 	"cosplayrent/internal/delivery/http/route"
 	"cosplayrent/internal/repository"
+	"cosplayrent/internal/storage"
 	"cosplayrent/internal/usecase"
 	"database/sql"
 
@@ -16,32 +17,60 @@ import (
 )
 
 type ServerConfig struct {
-	Router   *httprouter.Router
-	DB       *sql.DB
-	Memcache *memcache.Client
-	Log      *zerolog.Logger
-	Validate *validator.Validate
-	Config   *koanf.Koanf
+	Router         *httprouter.Router
+	DB             *sql.DB
+	Memcache       *memcache.Client
+	Storage        storage.Storage
+	PrivateStorage storage.Storage
+	Log            *zerolog.Logger
+	Validate       *validator.Validate
+	Config         *koanf.Koanf
 }
 
 func Server(config *ServerConfig) {
 	notificationRepository := repository.NewNotificationRepository(config.Log)
-	notificationUsecase := usecase.NewNotificationUsecase(notificationRepository, config.DB, config.Validate, config.Log, config.Config)
+	notificationUsecase := usecase.NewNotificationUsecase(notificationRepository, repository.NewUserNotificationRepository(config.Log), config.DB, config.Validate, config.Log, config.Config)
+	notificationController := controller.NewNotificationController(notificationUsecase, config.Log)
 
 	userRepository := repository.NewUserRepository(config.Log)
-	userUsecase := usecase.NewUserUsecase(userRepository, repository.NewCostumeRepository(config.Log), notificationUsecase, config.DB, config.Validate, config.Log, config.Config)
-	userController := controller.NewUserController(userUsecase, config.Log)
+	userMeasurementRepository := repository.NewUserMeasurementRepository(config.Log)
+	userUsecase := usecase.NewUserUsecase(userRepository, repository.NewCostumeRepository(config.Log), repository.NewOrderRepository(config.Log), userMeasurementRepository, notificationUsecase, config.Storage, config.PrivateStorage, config.DB, config.Validate, config.Log, config.Config)
+	userController := controller.NewUserController(userUsecase, config.Storage, config.Log)
 
 	costumeRepository := repository.NewCostumeRepository(config.Log)
-	costumeUsecase := usecase.NewCostumeUsecase(userRepository, costumeRepository, repository.NewCategoryRepository(config.Log), config.DB, config.Validate, config.Log, config.Config)
-	costumeController := controller.NewCostumeController(costumeUsecase, config.Log)
+	costumeImageRepository := repository.NewCostumeImageRepository(config.Log)
+	categoryAttributeRepository := repository.NewCategoryAttributeRepository(config.Log)
+	costumeAttributeRepository := repository.NewCostumeAttributeRepository(config.Log)
+	costumeMeasurementRepository := repository.NewCostumeMeasurementRepository(config.Log)
+	costumeVariantRepository := repository.NewCostumeVariantRepository(config.Log)
+	costumePricingRepository := repository.NewCostumePricingRepository(config.Log)
+	costumeBlackoutRepository := repository.NewCostumeBlackoutRepository(config.Log)
+	followRepository := repository.NewFollowRepository(config.Log)
+	costumeActivityRepository := repository.NewCostumeActivityRepository(config.Log)
+	costumeEventRepository := repository.NewCostumeEventRepository(config.Log)
+	costumeUsecase := usecase.NewCostumeUsecase(userRepository, costumeRepository, costumeImageRepository, repository.NewCategoryRepository(config.Log), categoryAttributeRepository, costumeAttributeRepository, costumeMeasurementRepository, userMeasurementRepository, costumeVariantRepository, costumePricingRepository, costumeBlackoutRepository, followRepository, costumeActivityRepository, costumeEventRepository, notificationUsecase, config.Storage, config.DB, config.Validate, config.Log, config.Config)
+	costumeController := controller.NewCostumeController(costumeUsecase, config.Storage, config.Log)
+
+	costumeDailyStatRepository := repository.NewCostumeDailyStatRepository(config.Log)
+	trendingUsecase := usecase.NewTrendingUsecase(costumeEventRepository, repository.NewCostumePopularityRepository(config.Log), costumeDailyStatRepository, costumeUsecase, config.Memcache, config.DB, config.Validate, config.Log, config.Config)
+	trendingController := controller.NewTrendingController(trendingUsecase, config.Log)
+	go trendingUsecase.RunPopularityRefresh()
+
+	analyticsUsecase := usecase.NewAnalyticsUsecase(costumeDailyStatRepository, config.Storage, config.DB, config.Validate, config.Log, config.Config)
+	analyticsController := controller.NewAnalyticsController(analyticsUsecase, config.Log)
+
+	shopUsecase := usecase.NewShopUsecase(userRepository, followRepository, costumeActivityRepository, costumeUsecase, config.Storage, config.DB, config.Validate, config.Log, config.Config)
+	shopController := controller.NewShopController(shopUsecase, config.Log)
+
+	searchUsecase := usecase.NewSearchUsecase(userRepository, costumeRepository, costumeImageRepository, costumeAttributeRepository, costumeMeasurementRepository, userMeasurementRepository, repository.NewCategoryRepository(config.Log), config.Storage, config.Memcache, config.DB, config.Validate, config.Log, config.Config)
+	searchController := controller.NewSearchController(searchUsecase, config.Log)
 
 	categoryRepository := repository.NewCategoryRepository(config.Log)
-	categoryUsecase := usecase.NewCategoryUsecase(categoryRepository, config.DB, config.Validate, config.Log, config.Config)
+	categoryUsecase := usecase.NewCategoryUsecase(categoryRepository, categoryAttributeRepository, config.DB, config.Validate, config.Log, config.Config)
 	categoryController := controller.NewCategoryController(categoryUsecase, config.Log)
 
 	wishlistRepository := repository.NewWishlistRepository(config.Log)
-	wishlistUsecase := usecase.NewWishlistUsecase(wishlistRepository, costumeRepository, config.DB, config.Validate, config.Log, config.Config)
+	wishlistUsecase := usecase.NewWishlistUsecase(wishlistRepository, costumeRepository, costumeEventRepository, config.Storage, config.DB, config.Validate, config.Log, config.Config)
 	wishlistController := controller.NewWishlistController(wishlistUsecase, config.Log)
 
 	midtransUsecase := usecase.NewMidtransUsecase(userRepository, repository.NewOrderRepository(config.Log), repository.NewTopUpOrderRepository(config.Log), config.DB, config.Validate, config.Log, config.Config)
@@ -52,12 +81,12 @@ func Server(config *ServerConfig) {
 	topUpOrderController := controller.NewTopUpOrderController(topUpOrderUsecase, config.Log)
 
 	orderRepository := repository.NewOrderRepository(config.Log)
-	orderUsecase := usecase.NewOrderUsecase(userRepository, costumeRepository, categoryRepository, orderRepository, midtransUsecase, config.DB, config.Validate, config.Log, config.Config)
+	orderUsecase := usecase.NewOrderUsecase(userRepository, costumeRepository, costumeVariantRepository, costumePricingRepository, costumeBlackoutRepository, categoryRepository, orderRepository, costumeEventRepository, midtransUsecase, config.Storage, config.DB, config.Validate, config.Log, config.Config)
 	orderController := controller.NewOrderController(orderUsecase, config.Log)
 
 	reviewRepository := repository.NewReviewRepository(config.Log)
-	reviewUsecase := usecase.NewReviewUsecase(userRepository, costumeRepository, reviewRepository, config.DB, config.Validate, config.Log, config.Config)
-	reviewController := controller.NewReviewController(reviewUsecase, orderUsecase, config.Log)
+	reviewUsecase := usecase.NewReviewUsecase(userRepository, costumeRepository, reviewRepository, config.Storage, config.DB, config.Validate, config.Log, config.Config)
+	reviewController := controller.NewReviewController(reviewUsecase, orderUsecase, config.Storage, config.Log)
 
 	rajaongkirUsecase := usecase.NewRajaOngkirUsecase(config.Memcache, config.Validate, config.Log, config.Config)
 	rajaongkirController := controller.NewRajaOngkirController(rajaongkirUsecase, config.Log)
@@ -65,17 +94,22 @@ func Server(config *ServerConfig) {
 	authMiddleware := middleware.NewAuthMiddleware(config.Router, config.Log, config.Config, userUsecase)
 
 	routeConfig := route.RouteConfig{
-		Router:               config.Router,
-		UserController:       userController,
-		CostumeController:    costumeController,
-		CategoryController:   categoryController,
-		WishlistController:   wishlistController,
-		OrderController:      orderController,
-		ReviewController:     reviewController,
-		TopUpOrderController: topUpOrderController,
-		MidtransController:   midtransController,
-		RajaOngkirController: rajaongkirController,
-		AuthMiddleware:       authMiddleware,
+		Router:                 config.Router,
+		UserController:         userController,
+		CostumeController:      costumeController,
+		ShopController:         shopController,
+		TrendingController:     trendingController,
+		AnalyticsController:    analyticsController,
+		NotificationController: notificationController,
+		SearchController:       searchController,
+		CategoryController:     categoryController,
+		WishlistController:     wishlistController,
+		OrderController:        orderController,
+		ReviewController:       reviewController,
+		TopUpOrderController:   topUpOrderController,
+		MidtransController:     midtransController,
+		RajaOngkirController:   rajaongkirController,
+		AuthMiddleware:         authMiddleware,
 	}
 
 	routeConfig.SetupRoute()
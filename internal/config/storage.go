@@ -0,0 +1,38 @@
+package config
+
+import (
+	"cosplayrent/internal/storage"
+
+	"github.com/knadh/koanf/v2"
+)
+
+// NewStorage returns the store for public media, STORAGE_DRIVER picks the local directory or an S3 compatible bucket.
+func NewStorage(config *koanf.Koanf) storage.Storage {
+	if config.String("STORAGE_DRIVER") == "s3" {
+		return storage.NewS3(storage.S3Config{
+			Endpoint:  config.String("S3_ENDPOINT"),
+			Region:    config.String("S3_REGION"),
+			Bucket:    config.String("S3_BUCKET"),
+			AccessKey: config.String("S3_ACCESS_KEY"),
+			SecretKey: config.String("S3_SECRET_KEY"),
+			PublicURL: config.String("S3_PUBLIC_URL"),
+		})
+	}
+
+	return storage.NewLocal(config.String("STORAGE_LOCAL_DIR"), config.String("STORAGE_PUBLIC_URL"))
+}
+
+// NewPrivateStorage returns the store for uploads that are only served through access checked endpoints, such as identity cards.
+func NewPrivateStorage(config *koanf.Koanf) storage.Storage {
+	if config.String("STORAGE_DRIVER") == "s3" {
+		return storage.NewS3(storage.S3Config{
+			Endpoint:  config.String("S3_ENDPOINT"),
+			Region:    config.String("S3_REGION"),
+			Bucket:    config.String("S3_PRIVATE_BUCKET"),
+			AccessKey: config.String("S3_ACCESS_KEY"),
+			SecretKey: config.String("S3_SECRET_KEY"),
+		})
+	}
+
+	return storage.NewLocal(config.String("IDENTITY_CARD_DIR"), "")
+}
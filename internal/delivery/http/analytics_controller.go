@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"cosplayrent/internal/helper"
+	"cosplayrent/internal/model/web"
+	"cosplayrent/internal/model/web/analytics"
+	"cosplayrent/internal/usecase"
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog"
+	"net/http"
+)
+
+type AnalyticsController struct {
+	AnalyticsUsecase *usecase.AnalyticsUsecase
+	Log              *zerolog.Logger
+}
+
+func NewAnalyticsController(analyticsUsecase *usecase.AnalyticsUsecase, zerolog *zerolog.Logger) *AnalyticsController {
+	return &AnalyticsController{
+		AnalyticsUsecase: analyticsUsecase,
+		Log:              zerolog,
+	}
+}
+
+func (controller AnalyticsController) FindSellerAnalytics(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	analyticsRequest := analytics.SellerAnalyticsRequest{
+		Start_date: request.URL.Query().Get("start_date"),
+		End_date:   request.URL.Query().Get("end_date"),
+	}
+	analyticsRequest.Seller_id, _ = request.Context().Value("user_uuid").(string)
+
+	analyticsResponse, err := controller.AnalyticsUsecase.FindSellerAnalytics(request.Context(), analyticsRequest)
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   analyticsResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
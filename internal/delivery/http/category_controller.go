@@ -3,10 +3,13 @@ package controller
 import (
 	"cosplayrent/internal/helper"
 	"cosplayrent/internal/model/web"
+	"cosplayrent/internal/model/web/category"
 	"cosplayrent/internal/usecase"
+	"errors"
 	"github.com/julienschmidt/httprouter"
 	"github.com/rs/zerolog"
 	"net/http"
+	"strconv"
 )
 
 type CategoryController struct {
@@ -45,3 +48,214 @@ func (controller CategoryController) FindAllCategory(writer http.ResponseWriter,
 
 	helper.WriteToResponseBody(writer, webResponse)
 }
+
+func (controller CategoryController) FindCategoryTree(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	categoryTree := controller.CategoryUsecase.FindCategoryTree(request.Context())
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   categoryTree,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller CategoryController) Create(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	categoryRequest := category.CategoryCreateRequest{}
+	helper.ReadFromRequestBody(request, &categoryRequest)
+
+	categoryResponse, err := controller.CategoryUsecase.Create(request.Context(), categoryRequest)
+	if err != nil {
+		writeCategoryBadRequest(writer, err)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   categoryResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller CategoryController) Update(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	id, err := strconv.Atoi(params.ByName("categoryID"))
+	if err != nil {
+		writeCategoryBadRequest(writer, errors.New("invalid category id"))
+		return
+	}
+
+	categoryRequest := category.CategoryUpdateRequest{}
+	helper.ReadFromRequestBody(request, &categoryRequest)
+	categoryRequest.Id = id
+
+	categoryResponse, err := controller.CategoryUsecase.Update(request.Context(), categoryRequest)
+	if err != nil {
+		writeCategoryBadRequest(writer, err)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   categoryResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller CategoryController) Delete(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	id, err := strconv.Atoi(params.ByName("categoryID"))
+	if err != nil {
+		writeCategoryBadRequest(writer, errors.New("invalid category id"))
+		return
+	}
+
+	categoryRequest := category.CategoryDeleteRequest{
+		Id: id,
+	}
+
+	if reassignTo := request.URL.Query().Get("reassign_to"); reassignTo != "" {
+		reassignId, err := strconv.Atoi(reassignTo)
+		if err != nil {
+			writeCategoryBadRequest(writer, errors.New("reassign_to must be a number"))
+			return
+		}
+		categoryRequest.Reassign_to = &reassignId
+	}
+
+	err = controller.CategoryUsecase.Delete(request.Context(), categoryRequest)
+	if err != nil {
+		writeCategoryBadRequest(writer, err)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller CategoryController) FindAttributes(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	categoryID, err := strconv.Atoi(params.ByName("categoryID"))
+	if err != nil {
+		writeCategoryBadRequest(writer, errors.New("invalid category id"))
+		return
+	}
+
+	attributes, err := controller.CategoryUsecase.FindAttributes(request.Context(), categoryID)
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusNotFound)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusNotFound,
+			Status: "Not Found",
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   attributes,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller CategoryController) CreateAttribute(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	categoryID, err := strconv.Atoi(params.ByName("categoryID"))
+	if err != nil {
+		writeCategoryBadRequest(writer, errors.New("invalid category id"))
+		return
+	}
+
+	attributeRequest := category.CategoryAttributeCreateRequest{}
+	helper.ReadFromRequestBody(request, &attributeRequest)
+	attributeRequest.Category_id = categoryID
+
+	attribute, err := controller.CategoryUsecase.CreateAttribute(request.Context(), attributeRequest)
+	if err != nil {
+		writeCategoryBadRequest(writer, err)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   attribute,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller CategoryController) UpdateAttribute(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	categoryID, categoryErr := strconv.Atoi(params.ByName("categoryID"))
+	attributeID, attributeErr := strconv.Atoi(params.ByName("attributeID"))
+	if categoryErr != nil || attributeErr != nil {
+		writeCategoryBadRequest(writer, errors.New("invalid category or attribute id"))
+		return
+	}
+
+	attributeRequest := category.CategoryAttributeUpdateRequest{}
+	helper.ReadFromRequestBody(request, &attributeRequest)
+	attributeRequest.Id = attributeID
+	attributeRequest.Category_id = categoryID
+
+	attribute, err := controller.CategoryUsecase.UpdateAttribute(request.Context(), attributeRequest)
+	if err != nil {
+		writeCategoryBadRequest(writer, err)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   attribute,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller CategoryController) DeleteAttribute(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	categoryID, categoryErr := strconv.Atoi(params.ByName("categoryID"))
+	attributeID, attributeErr := strconv.Atoi(params.ByName("attributeID"))
+	if categoryErr != nil || attributeErr != nil {
+		writeCategoryBadRequest(writer, errors.New("invalid category or attribute id"))
+		return
+	}
+
+	err := controller.CategoryUsecase.DeleteAttribute(request.Context(), categoryID, attributeID)
+	if err != nil {
+		writeCategoryBadRequest(writer, err)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func writeCategoryBadRequest(writer http.ResponseWriter, err error) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(http.StatusBadRequest)
+
+	webResponse := web.WebResponse{
+		Code:   http.StatusBadRequest,
+		Status: "Bad Request",
+		Data:   err.Error(),
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
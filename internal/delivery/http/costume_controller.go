@@ -1,31 +1,40 @@
 package controller
 
 import (
+	"archive/zip"
 	"cosplayrent/internal/helper"
 	"cosplayrent/internal/model/web"
 	"cosplayrent/internal/model/web/costume"
+	"cosplayrent/internal/storage"
 	"cosplayrent/internal/usecase"
+	"encoding/csv"
+	"encoding/json"
 	"errors"
-	"fmt"
 	"github.com/julienschmidt/httprouter"
 	"github.com/rs/zerolog"
-	"io"
+	"mime/multipart"
 	"net/http"
-	"os"
-	"path/filepath"
+	"net/url"
 	"strconv"
 	"strings"
-	"time"
+)
+
+const (
+	costumeImageMaxBytes  = 5 * 1024 * 1024
+	costumeUploadMaxBytes = 8 * costumeImageMaxBytes
+	costumeImportMaxBytes = 20 * costumeImageMaxBytes
 )
 
 type CostumeController struct {
 	CostumeUsecase *usecase.CostumeUsecase
+	Storage        storage.Storage
 	Log            *zerolog.Logger
 }
 
-func NewCostumeController(costumeUsecase *usecase.CostumeUsecase, zerolog *zerolog.Logger) *CostumeController {
+func NewCostumeController(costumeUsecase *usecase.CostumeUsecase, storage storage.Storage, zerolog *zerolog.Logger) *CostumeController {
 	return &CostumeController{
 		CostumeUsecase: costumeUsecase,
+		Storage:        storage,
 		Log:            zerolog,
 	}
 }
@@ -33,82 +42,22 @@ func NewCostumeController(costumeUsecase *usecase.CostumeUsecase, zerolog *zerol
 func (controller CostumeController) Create(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
 	userUUID, _ := request.Context().Value("user_uuid").(string)
 
-	request.Body = http.MaxBytesReader(writer, request.Body, 5*1024*1024) // 5 MB
-
-	file, fileHeader, err := request.FormFile("costume_picture")
-
-	var costumePicturePath *string
+	request.Body = http.MaxBytesReader(writer, request.Body, costumeUploadMaxBytes)
 
+	costumeImagePaths, err := controller.saveCostumeImages(request, "costume_picture", "costume_images")
 	if err != nil {
-		if err.Error() == "http: no such file" {
-
-		} else if err.Error() == "http: request body too large" {
-			respErr := errors.New("request exceeded 5 mb")
-
-			writer.Header().Set("Content-Type", "application/json")
-			writer.WriteHeader(http.StatusBadRequest)
-
-			webResponse := web.WebResponse{
-				Code:   http.StatusBadRequest,
-				Status: "Bad Request",
-				Data:   respErr.Error(),
-			}
-
-			controller.Log.Warn().Err(err).Msg(respErr.Error())
-			helper.WriteToResponseBody(writer, webResponse)
-			return
-		} else {
-			respErr := errors.New("unexpected error handling file upload")
-			controller.Log.Panic().Err(err).Msg(respErr.Error())
-		}
-	} else if file != nil {
-		defer file.Close()
-
-		fileType := fileHeader.Header.Get("Content-Type")
-		if fileType != "image/jpeg" && fileType != "image/png" {
-			respErr := errors.New("file is not image")
-
-			writer.Header().Set("Content-Type", "application/json")
-			writer.WriteHeader(http.StatusBadRequest)
-
-			webResponse := web.WebResponse{
-				Code:   http.StatusBadRequest,
-				Status: "Bad Request",
-				Data:   respErr.Error(),
-			}
-
-			controller.Log.Warn().Err(err).Msg(respErr.Error())
-			helper.WriteToResponseBody(writer, webResponse)
-			return
-		}
-
-		_, err = os.Stat("../static/costume/")
-		if os.IsNotExist(err) {
-			err = os.MkdirAll("../static/costume/", os.ModePerm)
-			if err != nil {
-				respErr := errors.New("failed to create directory")
-				controller.Log.Panic().Err(err).Msg(respErr.Error())
-			}
-		}
-
-		fileName := fmt.Sprintf("%d%s", time.Now().UnixNano(), filepath.Ext(fileHeader.Filename))
-		costumeImagePath := filepath.Join("../static/costume/", fileName)
-
-		destFile, err := os.Create(costumeImagePath)
-		if err != nil {
-			respErr := errors.New("failed to create file in the directory path")
-			controller.Log.Panic().Err(err).Msg(respErr.Error())
-		}
-		defer destFile.Close()
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
 
-		_, err = io.Copy(destFile, file)
-		if err != nil {
-			respErr := errors.New("failed to copy a created file from request's file")
-			controller.Log.Panic().Err(err).Msg(respErr.Error())
+		webResponse := web.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Data:   err.Error(),
 		}
 
-		costumeImageTrimPath := strings.TrimPrefix(costumeImagePath, "..")
-		costumePicturePath = &costumeImageTrimPath
+		controller.Log.Warn().Msg(err.Error())
+		helper.WriteToResponseBody(writer, webResponse)
+		return
 	}
 
 	costumeName := request.FormValue("name")
@@ -118,6 +67,7 @@ func (controller CostumeController) Create(writer http.ResponseWriter, request *
 	costumeBerat := request.FormValue("berat")
 	costumeKategori := request.FormValue("kategori")
 	costumePrice := request.FormValue("price")
+	costumeStatus := request.FormValue("status")
 
 	var fixPrice float64
 	if costumePrice != "" {
@@ -146,6 +96,46 @@ func (controller CostumeController) Create(writer http.ResponseWriter, request *
 		}
 	}
 
+	costumeAttributes, err := helper.FormJSON[map[string]json.RawMessage](request, "attributes")
+	if err != nil {
+		for _, path := range costumeImagePaths {
+			helper.RemoveImage(request.Context(), controller.Storage, path)
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Data:   err.Error(),
+		}
+
+		controller.Log.Warn().Msg(err.Error())
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	costumeVariants, err := helper.FormJSON[[]costume.CostumeVariantRequest](request, "variants")
+	if err != nil {
+		for _, path := range costumeImagePaths {
+			helper.RemoveImage(request.Context(), controller.Storage, path)
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Data:   err.Error(),
+		}
+
+		controller.Log.Warn().Msg(err.Error())
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
 	userRequest := costume.CostumeCreateRequest{
 		Name:        costumeName,
 		Description: costumeDescription,
@@ -154,11 +144,18 @@ func (controller CostumeController) Create(writer http.ResponseWriter, request *
 		Berat:       fixBerat,
 		Kategori:    fixKategoriId,
 		Price:       fixPrice,
-		Picture:     costumePicturePath,
+		Images:      costumeImagePaths,
+		Attributes:  costumeAttributes.Value,
+		Variants:    costumeVariants.Value,
+		Status:      costumeStatus,
 	}
 
 	err = controller.CostumeUsecase.Create(request.Context(), userRequest, userUUID)
 	if err != nil {
+		for _, path := range costumeImagePaths {
+			helper.RemoveImage(request.Context(), controller.Storage, path)
+		}
+
 		writer.Header().Set("Content-Type", "application/json")
 		writer.WriteHeader(http.StatusBadRequest)
 
@@ -185,20 +182,31 @@ func (controller CostumeController) Update(writer http.ResponseWriter, request *
 
 	request.Body = http.MaxBytesReader(writer, request.Body, 5*1024*1024) // 5 MB
 
-	file, fileHeader, err := request.FormFile("costume_picture")
+	costumeID, err := strconv.Atoi(params.ByName("costumeID"))
+	if err != nil {
+		respErr := errors.New("invalid costume id")
+
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
 
-	var costumePicturePath *string
+		webResponse := web.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Data:   respErr.Error(),
+		}
 
-	if file == nil {
-		var emptyPicture string = ""
-		costumePicturePath = &emptyPicture
+		controller.Log.Warn().Err(err).Msg(respErr.Error())
+		helper.WriteToResponseBody(writer, webResponse)
+		return
 	}
 
-	if err != nil {
-		if err.Error() == "http: no such file" {
+	costumeRequest := costume.CostumeUpdateRequest{}
 
-		} else if err.Error() == "http: request body too large" {
-			respErr := errors.New("request exceeded 5 mb")
+	if helper.IsJSONRequest(request) {
+		helper.ReadFromRequestBody(request, &costumeRequest)
+
+		if costumeRequest.Picture.Set {
+			respErr := errors.New("costume_picture must be uploaded as a file")
 
 			writer.Header().Set("Content-Type", "application/json")
 			writer.WriteHeader(http.StatusBadRequest)
@@ -209,129 +217,127 @@ func (controller CostumeController) Update(writer http.ResponseWriter, request *
 				Data:   respErr.Error(),
 			}
 
-			controller.Log.Warn().Err(err).Msg(respErr.Error())
+			controller.Log.Warn().Msg(respErr.Error())
 			helper.WriteToResponseBody(writer, webResponse)
 			return
-		} else {
-			respErr := errors.New("unexpected error handling file upload")
-			controller.Log.Panic().Err(err).Msg(respErr.Error())
 		}
-	} else if file != nil {
-		defer file.Close()
+	} else {
+		file, _, err := request.FormFile("costume_picture")
 
-		fileType := fileHeader.Header.Get("Content-Type")
-		if fileType != "image/jpeg" && fileType != "image/png" {
-			respErr := errors.New("file is not image")
+		if err != nil {
+			if err.Error() == "http: no such file" {
 
-			writer.Header().Set("Content-Type", "application/json")
-			writer.WriteHeader(http.StatusBadRequest)
+			} else if err.Error() == "http: request body too large" {
+				respErr := errors.New("request exceeded 5 mb")
 
-			webResponse := web.WebResponse{
-				Code:   http.StatusBadRequest,
-				Status: "Bad Request",
-				Data:   respErr.Error(),
-			}
+				writer.Header().Set("Content-Type", "application/json")
+				writer.WriteHeader(http.StatusBadRequest)
 
-			controller.Log.Warn().Err(err).Msg(respErr.Error())
-			helper.WriteToResponseBody(writer, webResponse)
-			return
-		}
+				webResponse := web.WebResponse{
+					Code:   http.StatusBadRequest,
+					Status: "Bad Request",
+					Data:   respErr.Error(),
+				}
 
-		_, err := os.Stat("../static/costume/")
-		if os.IsNotExist(err) {
-			err = os.MkdirAll("../static/costume/", os.ModePerm)
-			if err != nil {
-				respErr := errors.New("failed to create directory")
+				controller.Log.Warn().Err(err).Msg(respErr.Error())
+				helper.WriteToResponseBody(writer, webResponse)
+				return
+			} else {
+				respErr := errors.New("unexpected error handling file upload")
 				controller.Log.Panic().Err(err).Msg(respErr.Error())
 			}
-		}
+		} else if file != nil {
+			defer file.Close()
 
-		fileName := fmt.Sprintf("%d%s", time.Now().UnixNano(), filepath.Ext(fileHeader.Filename))
-		costumeImagePath := filepath.Join("../static/costume/", fileName)
+			costumeImageTrimPath, err := helper.SaveImage(request.Context(), controller.Storage, file, "costume")
+			if err != nil {
+				if !errors.Is(err, helper.ErrInvalidImage) {
+					respErr := errors.New("failed to save image")
+					controller.Log.Panic().Err(err).Msg(respErr.Error())
+				}
+
+				writer.Header().Set("Content-Type", "application/json")
+				writer.WriteHeader(http.StatusBadRequest)
+
+				webResponse := web.WebResponse{
+					Code:   http.StatusBadRequest,
+					Status: "Bad Request",
+					Data:   err.Error(),
+				}
+
+				controller.Log.Warn().Err(err).Msg("rejected uploaded image")
+				helper.WriteToResponseBody(writer, webResponse)
+				return
+			}
 
-		destFile, err := os.Create(costumeImagePath)
-		if err != nil {
-			respErr := errors.New("failed to create file in the directory path")
-			controller.Log.Panic().Err(err).Msg(respErr.Error())
+			costumeRequest.Picture = web.Some(costumeImageTrimPath)
 		}
 
-		defer destFile.Close()
+		costumeBerat, beratErr := helper.FormInt(request, "berat")
+		costumeKategori, kategoriErr := helper.FormInt(request, "kategori")
+		costumePrice, priceErr := helper.FormFloat(request, "price")
+		costumeAttributes, attributesErr := helper.FormJSON[map[string]json.RawMessage](request, "attributes")
+		if beratErr != nil || kategoriErr != nil || priceErr != nil || attributesErr != nil {
+			respErr := beratErr
+			if respErr == nil {
+				respErr = kategoriErr
+			}
+			if respErr == nil {
+				respErr = priceErr
+			}
+			if respErr == nil {
+				respErr = attributesErr
+			}
 
-		_, err = io.Copy(destFile, file)
-		if err != nil {
-			respErr := errors.New("failed to copy a created file from request's file")
-			controller.Log.Panic().Err(err).Msg(respErr.Error())
-		}
+			if costumeRequest.Picture.Set {
+				helper.RemoveImage(request.Context(), controller.Storage, costumeRequest.Picture.Value)
+			}
 
-		costumeImageTrimPath := strings.TrimPrefix(costumeImagePath, "..")
+			writer.Header().Set("Content-Type", "application/json")
+			writer.WriteHeader(http.StatusBadRequest)
 
-		costumePicturePath = &costumeImageTrimPath
-	}
+			webResponse := web.WebResponse{
+				Code:   http.StatusBadRequest,
+				Status: "Bad Request",
+				Data:   respErr.Error(),
+			}
 
-	costumeID := params.ByName("costumeID")
-	costumeName := request.FormValue("name")
-	costumeDescription := request.FormValue("description")
-	costumeBahan := request.FormValue("bahan")
-	costumeUkuran := request.FormValue("ukuran")
-	costumeBerat := request.FormValue("berat")
-	costumeKategoriId := request.FormValue("kategori")
-	costumeAvailable := request.FormValue("available")
-	costumePrice := request.FormValue("price")
+			controller.Log.Warn().Msg(respErr.Error())
+			helper.WriteToResponseBody(writer, webResponse)
+			return
+		}
 
-	fixId, err := strconv.Atoi(costumeID)
-	if err != nil {
-		respErr := errors.New("error converting string to int")
-		controller.Log.Panic().Err(err).Msg(respErr.Error())
+		costumeRequest.Name = helper.FormString(request, "name")
+		costumeRequest.Description = helper.FormString(request, "description")
+		costumeRequest.Bahan = helper.FormString(request, "bahan")
+		costumeRequest.Ukuran = helper.FormString(request, "ukuran")
+		costumeRequest.Berat = costumeBerat
+		costumeRequest.Kategori = costumeKategori
+		costumeRequest.Price = costumePrice
+		costumeRequest.Attributes = costumeAttributes
 	}
 
-	var fixPrice float64
-	if costumePrice != "" {
-		fixPrice, err = strconv.ParseFloat(costumePrice, 64)
-		if err != nil {
-			respErr := errors.New("error converting string to float64")
-			controller.Log.Panic().Err(err).Msg(respErr.Error())
-		}
-	}
+	costumeRequest.Id = costumeID
 
-	var fixBerat int
-	if costumeBerat != "" {
-		fixBerat, err = strconv.Atoi(costumeBerat)
-		if err != nil {
-			respErr := errors.New("error converting string to int")
-			controller.Log.Panic().Err(err).Msg(respErr.Error())
+	costumeResponse, err := controller.CostumeUsecase.Update(request.Context(), costumeRequest, userUUID)
+	if err != nil {
+		if costumeRequest.Picture.Set {
+			helper.RemoveImage(request.Context(), controller.Storage, costumeRequest.Picture.Value)
 		}
-	}
 
-	var fixKategoriId int
-	if costumeKategoriId != "" {
-		fixKategoriId, err = strconv.Atoi(costumeKategoriId)
-		if err != nil {
-			respErr := errors.New("error converting string to int")
-			controller.Log.Panic().Err(err).Msg(respErr.Error())
+		statusCode := http.StatusBadRequest
+		status := "Bad Request"
+		if err.Error() == "costume not found" {
+			statusCode = http.StatusNotFound
+			status = "Not Found"
 		}
-	}
-
-	costumeRequest := costume.CostumeUpdateRequest{
-		Id:          fixId,
-		Name:        costumeName,
-		Description: costumeDescription,
-		Bahan:       costumeBahan,
-		Ukuran:      costumeUkuran,
-		Berat:       fixBerat,
-		Kategori:    fixKategoriId,
-		Available:   costumeAvailable,
-		Price:       fixPrice,
-		Picture:     costumePicturePath,
-	}
 
-	err = controller.CostumeUsecase.Update(request.Context(), costumeRequest, userUUID)
-	if err != nil {
 		writer.Header().Set("Content-Type", "application/json")
-		writer.WriteHeader(http.StatusNotFound)
+		writer.WriteHeader(statusCode)
 
 		webResponse := web.WebResponse{
-			Code:   http.StatusNotFound,
-			Status: "Not Found",
+			Code:   statusCode,
+			Status: status,
 			Data:   err.Error(),
 		}
 
@@ -342,20 +348,28 @@ func (controller CostumeController) Update(writer http.ResponseWriter, request *
 	webResponse := web.WebResponse{
 		Code:   200,
 		Status: "OK",
+		Data:   costumeResponse,
 	}
 
 	helper.WriteToResponseBody(writer, webResponse)
 }
 
 func (controller CostumeController) FindAll(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	costumeResponse, err := controller.CostumeUsecase.FindAll(request.Context())
+	searchRequest, err := costumeSearchRequestFromQuery(request.URL.Query())
+	searchRequest.Buyer_id, _ = request.Context().Value("user_uuid").(string)
+	searchRequest.Unpaged = !request.URL.Query().Has("cursor") && !request.URL.Query().Has("limit")
+
+	costumeResponse := costume.CostumeListResponse{}
+	if err == nil {
+		costumeResponse, err = controller.CostumeUsecase.FindAll(request.Context(), searchRequest)
+	}
 	if err != nil {
 		writer.Header().Set("Content-Type", "application/json")
-		writer.WriteHeader(http.StatusNotFound)
+		writer.WriteHeader(http.StatusBadRequest)
 
 		webResponse := web.WebResponse{
-			Code:   http.StatusNotFound,
-			Status: "Not Found",
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
 			Data:   err.Error(),
 		}
 
@@ -369,6 +383,11 @@ func (controller CostumeController) FindAll(writer http.ResponseWriter, request
 		Data:   costumeResponse,
 	}
 
+	// clients from before cursor paging send neither cursor nor limit and read data as a plain array of the whole catalog
+	if searchRequest.Unpaged {
+		webResponse.Data = costumeResponse.Costumes
+	}
+
 	helper.WriteToResponseBody(writer, webResponse)
 }
 
@@ -399,56 +418,39 @@ func (controller CostumeController) FindSellerCostume(writer http.ResponseWriter
 	helper.WriteToResponseBody(writer, webResponse)
 }
 
-func (controller CostumeController) FindById(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	costumeID := params.ByName("costumeID")
-	id, err := strconv.Atoi(costumeID)
-	if err != nil {
-		respErr := errors.New("error converting string to int")
-		controller.Log.Panic().Err(err).Msg(respErr.Error())
-	}
+func (controller CostumeController) Import(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	request.Body = http.MaxBytesReader(writer, request.Body, costumeImportMaxBytes)
 
-	costumeResponse, err := controller.CostumeUsecase.FindById(request.Context(), id)
+	csvFile, imagesFile, images, err := controller.openImportFiles(request)
 	if err != nil {
 		writer.Header().Set("Content-Type", "application/json")
-		writer.WriteHeader(http.StatusNotFound)
+		writer.WriteHeader(http.StatusBadRequest)
 
 		webResponse := web.WebResponse{
-			Code:   http.StatusNotFound,
-			Status: "Not Found",
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
 			Data:   err.Error(),
 		}
 
+		controller.Log.Warn().Msg(err.Error())
 		helper.WriteToResponseBody(writer, webResponse)
 		return
 	}
-
-	webResponse := web.WebResponse{
-		Code:   200,
-		Status: "OK",
-		Data:   costumeResponse,
-	}
-
-	helper.WriteToResponseBody(writer, webResponse)
-}
-
-func (controller CostumeController) FindSellerCostumeByCostumeID(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	userUUID, _ := request.Context().Value("user_uuid").(string)
-
-	costumeID := params.ByName("costumeID")
-	finalCostumeID, err := strconv.Atoi(costumeID)
-	if err != nil {
-		respErr := errors.New("error converting string to int")
-		controller.Log.Panic().Err(err).Msg(respErr.Error())
+	defer csvFile.Close()
+	if imagesFile != nil {
+		defer imagesFile.Close()
 	}
 
-	costumeReturn, err := controller.CostumeUsecase.FindSellerCostumeByCostumeID(request.Context(), userUUID, finalCostumeID)
+	report, err := controller.CostumeUsecase.Import(request.Context(), userUUID, csvFile, images)
 	if err != nil {
 		writer.Header().Set("Content-Type", "application/json")
-		writer.WriteHeader(http.StatusNotFound)
+		writer.WriteHeader(http.StatusBadRequest)
 
 		webResponse := web.WebResponse{
-			Code:   http.StatusNotFound,
-			Status: "Not Found",
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
 			Data:   err.Error(),
 		}
 
@@ -459,41 +461,887 @@ func (controller CostumeController) FindSellerCostumeByCostumeID(writer http.Res
 	webResponse := web.WebResponse{
 		Code:   200,
 		Status: "OK",
-		Data:   costumeReturn,
+		Data:   report,
 	}
 
 	helper.WriteToResponseBody(writer, webResponse)
 }
 
-func (controller CostumeController) Delete(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	userUUID, _ := request.Context().Value("user_uuid").(string)
-
-	costumeID := params.ByName("costumeID")
-	id, err := strconv.Atoi(costumeID)
+// openImportFiles returns the uploaded csv and, when one was sent, the zip the csv's image names refer to.
+func (controller CostumeController) openImportFiles(request *http.Request) (multipart.File, multipart.File, *zip.Reader, error) {
+	err := request.ParseMultipartForm(costumeImageMaxBytes)
 	if err != nil {
-		respErr := errors.New("error converting string to int")
+		if err.Error() == "http: request body too large" {
+			return nil, nil, nil, errors.New("request exceeded 100 mb")
+		}
+		if err == http.ErrNotMultipart {
+			return nil, nil, nil, errors.New("csv file is required")
+		}
+		respErr := errors.New("unexpected error handling file upload")
 		controller.Log.Panic().Err(err).Msg(respErr.Error())
 	}
 
-	err = controller.CostumeUsecase.Delete(request.Context(), id, userUUID)
+	csvFile, _, err := request.FormFile("file")
 	if err != nil {
-		writer.Header().Set("Content-Type", "application/json")
-		writer.WriteHeader(http.StatusUnauthorized)
-
-		webResponse := web.WebResponse{
-			Code:   http.StatusUnauthorized,
-			Status: "Unauthorized",
-			Data:   err.Error(),
-		}
-
-		helper.WriteToResponseBody(writer, webResponse)
-		return
+		return nil, nil, nil, errors.New("csv file is required")
 	}
 
-	webResponse := web.WebResponse{
-		Code:   200,
+	imagesFile, imagesHeader, err := request.FormFile("images")
+	if err == http.ErrMissingFile {
+		return csvFile, nil, nil, nil
+	}
+	if err != nil {
+		csvFile.Close()
+		respErr := errors.New("unexpected error handling file upload")
+		controller.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	images, err := zip.NewReader(imagesFile, imagesHeader.Size)
+	if err != nil {
+		csvFile.Close()
+		imagesFile.Close()
+		return nil, nil, nil, errors.New("images must be a zip archive")
+	}
+
+	return csvFile, imagesFile, images, nil
+}
+
+func (controller CostumeController) Export(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	records := controller.CostumeUsecase.Export(request.Context(), userUUID)
+
+	writer.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	writer.Header().Set("Content-Disposition", `attachment; filename="costumes.csv"`)
+
+	err := csv.NewWriter(writer).WriteAll(records)
+	if err != nil {
+		controller.Log.Warn().Err(err).Msg("failed to write costume export")
+	}
+}
+
+func (controller CostumeController) FindById(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	costumeID := params.ByName("costumeID")
+	id, err := strconv.Atoi(costumeID)
+	if err != nil {
+		respErr := errors.New("error converting string to int")
+		controller.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	query := request.URL.Query()
+	quoteRequest := costume.CostumeQuoteRequest{
+		Start_date: query.Get("start_date"),
+		End_date:   query.Get("end_date"),
+	}
+	if query.Get("variant_id") != "" {
+		quoteRequest.Variant_id, err = strconv.Atoi(query.Get("variant_id"))
+		if err != nil {
+			writer.Header().Set("Content-Type", "application/json")
+			writer.WriteHeader(http.StatusBadRequest)
+
+			webResponse := web.WebResponse{
+				Code:   http.StatusBadRequest,
+				Status: "Bad Request",
+				Data:   "invalid variant id",
+			}
+
+			helper.WriteToResponseBody(writer, webResponse)
+			return
+		}
+	}
+
+	viewerUUID, _ := request.Context().Value("user_uuid").(string)
+
+	costumeResponse, err := controller.CostumeUsecase.FindById(request.Context(), id, quoteRequest, viewerUUID, helper.VisitorId(request))
+	if err != nil {
+		// listing lookups answer not found, a date range that cannot be quoted is the client's mistake
+		statusCode := http.StatusNotFound
+		status := "Not Found"
+		if !strings.HasSuffix(err.Error(), "not found") {
+			statusCode = http.StatusBadRequest
+			status = "Bad Request"
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(statusCode)
+
+		webResponse := web.WebResponse{
+			Code:   statusCode,
+			Status: status,
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   costumeResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller CostumeController) FindSellerCostumeByCostumeID(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	costumeID := params.ByName("costumeID")
+	finalCostumeID, err := strconv.Atoi(costumeID)
+	if err != nil {
+		respErr := errors.New("error converting string to int")
+		controller.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	costumeReturn, err := controller.CostumeUsecase.FindSellerCostumeByCostumeID(request.Context(), userUUID, finalCostumeID)
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusNotFound)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusNotFound,
+			Status: "Not Found",
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   costumeReturn,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller CostumeController) Delete(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	costumeID := params.ByName("costumeID")
+	id, err := strconv.Atoi(costumeID)
+	if err != nil {
+		respErr := errors.New("error converting string to int")
+		controller.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	err = controller.CostumeUsecase.Delete(request.Context(), id, userUUID)
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusUnauthorized)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusUnauthorized,
+			Status: "Unauthorized",
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func costumeSearchRequestFromQuery(query url.Values) (costume.CostumeSearchRequest, error) {
+	searchRequest := costume.CostumeSearchRequest{
+		Q:      query.Get("q"),
+		Ukuran: query.Get("ukuran"),
+		Bahan:  query.Get("bahan"),
+		Sort:   query.Get("sort"),
+		Cursor: query.Get("cursor"),
+	}
+
+	intParams := map[string]*int{
+		"kategori":    &searchRequest.Kategori,
+		"city_id":     &searchRequest.City_id,
+		"province_id": &searchRequest.Province_id,
+		"limit":       &searchRequest.Limit,
+	}
+	for key, target := range intParams {
+		if query.Get(key) == "" {
+			continue
+		}
+		value, err := strconv.Atoi(query.Get(key))
+		if err != nil {
+			return searchRequest, errors.New(key + " must be a number")
+		}
+		*target = value
+	}
+
+	floatParams := map[string]**float64{
+		"min_price": &searchRequest.Min_price,
+		"max_price": &searchRequest.Max_price,
+	}
+	for key, target := range floatParams {
+		if query.Get(key) == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(query.Get(key), 64)
+		if err != nil {
+			return searchRequest, errors.New(key + " must be a number")
+		}
+		*target = &value
+	}
+
+	// attribute filters are passed as attr.<key>=<value>, e.g. attr.franchise=Genshin Impact
+	for key := range query {
+		if attributeKey, ok := strings.CutPrefix(key, "attr."); ok {
+			if searchRequest.Attributes == nil {
+				searchRequest.Attributes = map[string]string{}
+			}
+			searchRequest.Attributes[attributeKey] = query.Get(key)
+		}
+	}
+
+	if query.Get("min_rating") != "" {
+		value, err := strconv.ParseFloat(query.Get("min_rating"), 64)
+		if err != nil {
+			return searchRequest, errors.New("min_rating must be a number")
+		}
+		searchRequest.Min_rating = value
+	}
+
+	return searchRequest, nil
+}
+
+// saveCostumeImages processes every uploaded file under the given form keys into the costume storage prefix,
+// a rejected file removes the ones already written by the same request.
+func (controller CostumeController) saveCostumeImages(request *http.Request, keys ...string) ([]string, error) {
+	err := request.ParseMultipartForm(costumeImageMaxBytes)
+	if err != nil {
+		if err.Error() == "http: request body too large" {
+			return nil, errors.New("request exceeded 40 mb")
+		}
+		if err == http.ErrNotMultipart {
+			return nil, nil
+		}
+		respErr := errors.New("unexpected error handling file upload")
+		controller.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	fileHeaders := []*multipart.FileHeader{}
+	for _, key := range keys {
+		fileHeaders = append(fileHeaders, request.MultipartForm.File[key]...)
+	}
+
+	for _, fileHeader := range fileHeaders {
+		if fileHeader.Size > costumeImageMaxBytes {
+			return nil, errors.New("each image must not exceed 5 mb")
+		}
+	}
+
+	costumeImagePaths := []string{}
+	for _, fileHeader := range fileHeaders {
+		file, err := fileHeader.Open()
+		if err != nil {
+			respErr := errors.New("unexpected error handling file upload")
+			controller.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+
+		costumeImagePath, err := helper.SaveImage(request.Context(), controller.Storage, file, "costume")
+		file.Close()
+		if err != nil {
+			for _, path := range costumeImagePaths {
+				helper.RemoveImage(request.Context(), controller.Storage, path)
+			}
+			if errors.Is(err, helper.ErrInvalidImage) {
+				return nil, err
+			}
+			respErr := errors.New("failed to save costume image")
+			controller.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+
+		costumeImagePaths = append(costumeImagePaths, costumeImagePath)
+	}
+
+	return costumeImagePaths, nil
+}
+
+func costumeAndImageIDs(params httprouter.Params) (int, int, error) {
+	costumeID, err := strconv.Atoi(params.ByName("costumeID"))
+	if err != nil {
+		return 0, 0, errors.New("invalid costume id")
+	}
+
+	if params.ByName("imageID") == "" {
+		return costumeID, 0, nil
+	}
+
+	imageID, err := strconv.Atoi(params.ByName("imageID"))
+	if err != nil {
+		return 0, 0, errors.New("invalid image id")
+	}
+
+	return costumeID, imageID, nil
+}
+
+func (controller CostumeController) writeImageResult(writer http.ResponseWriter, images []costume.CostumeImageResponse, err error) {
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		status := "Bad Request"
+		if err.Error() == "costume not found" || err.Error() == "image not found" {
+			statusCode = http.StatusNotFound
+			status = "Not Found"
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(statusCode)
+
+		webResponse := web.WebResponse{
+			Code:   statusCode,
+			Status: status,
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   images,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller CostumeController) AddImages(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	request.Body = http.MaxBytesReader(writer, request.Body, costumeUploadMaxBytes)
+
+	costumeID, _, err := costumeAndImageIDs(params)
+	if err != nil {
+		controller.writeImageResult(writer, nil, err)
+		return
+	}
+
+	costumeImagePaths, err := controller.saveCostumeImages(request, "costume_images")
+	if err == nil && len(costumeImagePaths) == 0 {
+		err = errors.New("costume_images is required")
+	}
+	if err != nil {
+		controller.writeImageResult(writer, nil, err)
+		return
+	}
+
+	images, err := controller.CostumeUsecase.AddImages(request.Context(), userUUID, costumeID, costumeImagePaths)
+	if err != nil {
+		for _, path := range costumeImagePaths {
+			helper.RemoveImage(request.Context(), controller.Storage, path)
+		}
+	}
+
+	controller.writeImageResult(writer, images, err)
+}
+
+func (controller CostumeController) ReorderImages(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	costumeID, _, err := costumeAndImageIDs(params)
+	if err != nil {
+		controller.writeImageResult(writer, nil, err)
+		return
+	}
+
+	orderRequest := costume.CostumeImageOrderRequest{}
+	helper.ReadFromRequestBody(request, &orderRequest)
+
+	images, err := controller.CostumeUsecase.ReorderImages(request.Context(), userUUID, costumeID, orderRequest)
+	controller.writeImageResult(writer, images, err)
+}
+
+func (controller CostumeController) SetCoverImage(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	costumeID, imageID, err := costumeAndImageIDs(params)
+	if err != nil {
+		controller.writeImageResult(writer, nil, err)
+		return
+	}
+
+	images, err := controller.CostumeUsecase.SetCoverImage(request.Context(), userUUID, costumeID, imageID)
+	controller.writeImageResult(writer, images, err)
+}
+
+func (controller CostumeController) DeleteImage(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	costumeID, imageID, err := costumeAndImageIDs(params)
+	if err != nil {
+		controller.writeImageResult(writer, nil, err)
+		return
+	}
+
+	images, err := controller.CostumeUsecase.DeleteImage(request.Context(), userUUID, costumeID, imageID)
+	controller.writeImageResult(writer, images, err)
+}
+
+func (controller CostumeController) UpdateMeasurements(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	costumeID, _, err := costumeAndImageIDs(params)
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	measurementRequest := costume.CostumeMeasurementRequest{}
+	helper.ReadFromRequestBody(request, &measurementRequest)
+	measurementRequest.Costume_id = costumeID
+
+	measurementResponse, err := controller.CostumeUsecase.UpdateMeasurements(request.Context(), userUUID, measurementRequest)
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		status := "Bad Request"
+		if err.Error() == "costume not found" {
+			statusCode = http.StatusNotFound
+			status = "Not Found"
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(statusCode)
+
+		webResponse := web.WebResponse{
+			Code:   statusCode,
+			Status: status,
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   measurementResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller CostumeController) FindFit(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	costumeID, err := strconv.Atoi(params.ByName("costumeID"))
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Data:   "invalid costume id",
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	fitResponse, err := controller.CostumeUsecase.FindFit(request.Context(), userUUID, costumeID)
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		status := "Bad Request"
+		if err.Error() == "costume not found" {
+			statusCode = http.StatusNotFound
+			status = "Not Found"
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(statusCode)
+
+		webResponse := web.WebResponse{
+			Code:   statusCode,
+			Status: status,
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   fitResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func costumeAndVariantIDs(params httprouter.Params) (int, int, error) {
+	costumeID, err := strconv.Atoi(params.ByName("costumeID"))
+	if err != nil {
+		return 0, 0, errors.New("invalid costume id")
+	}
+
+	if params.ByName("variantID") == "" {
+		return costumeID, 0, nil
+	}
+
+	variantID, err := strconv.Atoi(params.ByName("variantID"))
+	if err != nil {
+		return 0, 0, errors.New("invalid variant id")
+	}
+
+	return costumeID, variantID, nil
+}
+
+func (controller CostumeController) writeVariantResult(writer http.ResponseWriter, variants []costume.CostumeVariantResponse, err error) {
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		status := "Bad Request"
+		if err.Error() == "costume not found" || err.Error() == "variant not found" {
+			statusCode = http.StatusNotFound
+			status = "Not Found"
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(statusCode)
+
+		webResponse := web.WebResponse{
+			Code:   statusCode,
+			Status: status,
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   variants,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller CostumeController) FindVariants(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	costumeID, _, err := costumeAndVariantIDs(params)
+	if err != nil {
+		controller.writeVariantResult(writer, nil, err)
+		return
+	}
+
+	variants, err := controller.CostumeUsecase.FindVariants(request.Context(), costumeID)
+	controller.writeVariantResult(writer, variants, err)
+}
+
+func (controller CostumeController) CreateVariant(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	costumeID, _, err := costumeAndVariantIDs(params)
+	if err != nil {
+		controller.writeVariantResult(writer, nil, err)
+		return
+	}
+
+	variantRequest := costume.CostumeVariantRequest{}
+	helper.ReadFromRequestBody(request, &variantRequest)
+	variantRequest.Costume_id = costumeID
+
+	variants, err := controller.CostumeUsecase.CreateVariant(request.Context(), userUUID, variantRequest)
+	controller.writeVariantResult(writer, variants, err)
+}
+
+func (controller CostumeController) UpdateVariant(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	costumeID, variantID, err := costumeAndVariantIDs(params)
+	if err != nil {
+		controller.writeVariantResult(writer, nil, err)
+		return
+	}
+
+	variantRequest := costume.CostumeVariantUpdateRequest{}
+	helper.ReadFromRequestBody(request, &variantRequest)
+	variantRequest.Id = variantID
+	variantRequest.Costume_id = costumeID
+
+	variants, err := controller.CostumeUsecase.UpdateVariant(request.Context(), userUUID, variantRequest)
+	controller.writeVariantResult(writer, variants, err)
+}
+
+func (controller CostumeController) DeleteVariant(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	costumeID, variantID, err := costumeAndVariantIDs(params)
+	if err != nil {
+		controller.writeVariantResult(writer, nil, err)
+		return
+	}
+
+	variants, err := controller.CostumeUsecase.DeleteVariant(request.Context(), userUUID, costumeID, variantID)
+	controller.writeVariantResult(writer, variants, err)
+}
+
+func (controller CostumeController) UpdateStatus(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	costumeID, err := strconv.Atoi(params.ByName("costumeID"))
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Data:   "invalid costume id",
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	statusRequest := costume.CostumeStatusRequest{}
+	helper.ReadFromRequestBody(request, &statusRequest)
+	statusRequest.Id = costumeID
+
+	costumeResponse, err := controller.CostumeUsecase.UpdateStatus(request.Context(), userUUID, statusRequest)
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		status := "Bad Request"
+		if err.Error() == "costume not found" {
+			statusCode = http.StatusNotFound
+			status = "Not Found"
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(statusCode)
+
+		webResponse := web.WebResponse{
+			Code:   statusCode,
+			Status: status,
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   costumeResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller CostumeController) UpdatePricing(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	costumeID, err := strconv.Atoi(params.ByName("costumeID"))
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Data:   "invalid costume id",
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	pricingRequest := costume.CostumePricingRequest{}
+	helper.ReadFromRequestBody(request, &pricingRequest)
+	pricingRequest.Costume_id = costumeID
+
+	pricingResponse, err := controller.CostumeUsecase.UpdatePricing(request.Context(), userUUID, pricingRequest)
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		status := "Bad Request"
+		if err.Error() == "costume not found" {
+			statusCode = http.StatusNotFound
+			status = "Not Found"
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(statusCode)
+
+		webResponse := web.WebResponse{
+			Code:   statusCode,
+			Status: status,
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   pricingResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func costumeAndBlackoutIDs(params httprouter.Params) (int, int, error) {
+	costumeID, err := strconv.Atoi(params.ByName("costumeID"))
+	if err != nil {
+		return 0, 0, errors.New("invalid costume id")
+	}
+
+	if params.ByName("blackoutID") == "" {
+		return costumeID, 0, nil
+	}
+
+	blackoutID, err := strconv.Atoi(params.ByName("blackoutID"))
+	if err != nil {
+		return 0, 0, errors.New("invalid blackout id")
+	}
+
+	return costumeID, blackoutID, nil
+}
+
+func (controller CostumeController) writeAvailabilityResult(writer http.ResponseWriter, availability interface{}, err error) {
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		status := "Bad Request"
+		if err.Error() == "costume not found" || err.Error() == "variant not found" || err.Error() == "blackout not found" {
+			statusCode = http.StatusNotFound
+			status = "Not Found"
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(statusCode)
+
+		webResponse := web.WebResponse{
+			Code:   statusCode,
+			Status: status,
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
 		Status: "OK",
+		Data:   availability,
 	}
 
 	helper.WriteToResponseBody(writer, webResponse)
 }
+
+func (controller CostumeController) FindAvailability(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	costumeID, _, err := costumeAndBlackoutIDs(params)
+	if err != nil {
+		controller.writeAvailabilityResult(writer, nil, err)
+		return
+	}
+
+	query := request.URL.Query()
+	availabilityRequest := costume.CostumeAvailabilityRequest{
+		Start_date: query.Get("start_date"),
+		End_date:   query.Get("end_date"),
+	}
+	if query.Get("variant_id") != "" {
+		availabilityRequest.Variant_id, err = strconv.Atoi(query.Get("variant_id"))
+		if err != nil {
+			controller.writeAvailabilityResult(writer, nil, errors.New("invalid variant id"))
+			return
+		}
+	}
+
+	availability, err := controller.CostumeUsecase.FindAvailability(request.Context(), costumeID, availabilityRequest)
+	controller.writeAvailabilityResult(writer, availability, err)
+}
+
+func (controller CostumeController) FindSimilar(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	costumeID, _, err := costumeAndBlackoutIDs(params)
+	if err != nil {
+		controller.writeAvailabilityResult(writer, nil, err)
+		return
+	}
+
+	similarRequest := costume.CostumeSimilarRequest{
+		Costume_id: costumeID,
+	}
+	similarRequest.Buyer_id, _ = request.Context().Value("user_uuid").(string)
+	if request.URL.Query().Get("limit") != "" {
+		similarRequest.Limit, err = strconv.Atoi(request.URL.Query().Get("limit"))
+		if err != nil {
+			controller.writeAvailabilityResult(writer, nil, errors.New("limit must be a number"))
+			return
+		}
+	}
+
+	costumes, err := controller.CostumeUsecase.FindSimilar(request.Context(), similarRequest)
+	controller.writeAvailabilityResult(writer, costumes, err)
+}
+
+func (controller CostumeController) UpdateTurnaround(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	costumeID, _, err := costumeAndBlackoutIDs(params)
+	if err != nil {
+		controller.writeAvailabilityResult(writer, nil, err)
+		return
+	}
+
+	turnaroundRequest := costume.CostumeTurnaroundRequest{}
+	helper.ReadFromRequestBody(request, &turnaroundRequest)
+	turnaroundRequest.Costume_id = costumeID
+
+	settings, err := controller.CostumeUsecase.UpdateTurnaround(request.Context(), userUUID, turnaroundRequest)
+	controller.writeAvailabilityResult(writer, settings, err)
+}
+
+func (controller CostumeController) CreateBlackout(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	costumeID, _, err := costumeAndBlackoutIDs(params)
+	if err != nil {
+		controller.writeAvailabilityResult(writer, nil, err)
+		return
+	}
+
+	blackoutRequest := costume.CostumeBlackoutRequest{}
+	helper.ReadFromRequestBody(request, &blackoutRequest)
+	blackoutRequest.Costume_id = costumeID
+
+	settings, err := controller.CostumeUsecase.CreateBlackout(request.Context(), userUUID, blackoutRequest)
+	controller.writeAvailabilityResult(writer, settings, err)
+}
+
+func (controller CostumeController) DeleteBlackout(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	costumeID, blackoutID, err := costumeAndBlackoutIDs(params)
+	if err != nil {
+		controller.writeAvailabilityResult(writer, nil, err)
+		return
+	}
+
+	settings, err := controller.CostumeUsecase.DeleteBlackout(request.Context(), userUUID, costumeID, blackoutID)
+	controller.writeAvailabilityResult(writer, settings, err)
+}
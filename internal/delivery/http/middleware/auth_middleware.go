@@ -95,6 +95,20 @@ func (middleware *AuthMiddleware) ServeHTTP(next httprouter.Handle) httprouter.H
 
 		var id string
 		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+			if _, isChallenge := claims["purpose"]; isChallenge {
+				writer.Header().Set("Content-Type", "application/json")
+				writer.WriteHeader(http.StatusUnauthorized)
+
+				webResponse := web.WebResponse{
+					Code:   http.StatusUnauthorized,
+					Status: "Unauthorized",
+					Data:   "Invalid Token",
+				}
+
+				middleware.Log.Warn().Msg("Unauthorized, two factor challenge token used as session token")
+				helper.WriteToResponseBody(writer, webResponse)
+				return
+			}
 			if val, exists := claims["id"]; exists {
 				if strVal, ok := val.(string); ok {
 					id = strVal
@@ -198,6 +212,20 @@ func (middleware *AuthMiddleware) WebMiddleware(writer http.ResponseWriter, requ
 
 	var id string
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+		if _, isChallenge := claims["purpose"]; isChallenge {
+			writer.Header().Set("Content-Type", "application/json")
+			writer.WriteHeader(http.StatusUnauthorized)
+
+			webResponse := web.WebResponse{
+				Code:   http.StatusUnauthorized,
+				Status: "Unauthorized",
+				Data:   "Invalid Token",
+			}
+
+			middleware.Log.Warn().Msg("Unauthorized, two factor challenge token used as session token")
+			helper.WriteToResponseBody(writer, webResponse)
+			return
+		}
 		if val, exists := claims["id"]; exists {
 			if strVal, ok := val.(string); ok {
 				id = strVal
@@ -306,6 +334,20 @@ func (middleware *AuthMiddleware) EmailMiddleware(next httprouter.Handle) httpro
 
 		var id string
 		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+			if _, isChallenge := claims["purpose"]; isChallenge {
+				writer.Header().Set("Content-Type", "application/json")
+				writer.WriteHeader(http.StatusUnauthorized)
+
+				webResponse := web.WebResponse{
+					Code:   http.StatusUnauthorized,
+					Status: "Unauthorized",
+					Data:   "Invalid Token",
+				}
+
+				middleware.Log.Warn().Msg("Unauthorized, two factor challenge token used as session token")
+				helper.WriteToResponseBody(writer, webResponse)
+				return
+			}
 			if val, exists := claims["id"]; exists {
 				if strVal, ok := val.(string); ok {
 					id = strVal
@@ -347,3 +389,65 @@ func (middleware *AuthMiddleware) EmailMiddleware(next httprouter.Handle) httpro
 		next(writer, request.WithContext(ctx), p)
 	}
 }
+
+func (middleware *AuthMiddleware) AdminMiddleware(next httprouter.Handle) httprouter.Handle {
+	return middleware.ServeHTTP(func(writer http.ResponseWriter, request *http.Request, p httprouter.Params) {
+		userUUID, _ := request.Context().Value(userUUIDkey).(string)
+
+		err := middleware.UserUsecase.CheckAdmin(request.Context(), userUUID)
+		if err != nil {
+			writer.Header().Set("Content-Type", "application/json")
+			writer.WriteHeader(http.StatusForbidden)
+
+			webResponse := web.WebResponse{
+				Code:   http.StatusForbidden,
+				Status: "Forbidden",
+				Data:   "Admin access required",
+			}
+
+			middleware.Log.Warn().Msg("Forbidden, user is not an admin")
+			helper.WriteToResponseBody(writer, webResponse)
+			return
+		}
+
+		next(writer, request, p)
+	})
+}
+
+func (middleware *AuthMiddleware) TwoFactorMiddleware(next httprouter.Handle) httprouter.Handle {
+	return middleware.ServeHTTP(func(writer http.ResponseWriter, request *http.Request, p httprouter.Params) {
+		userUUID, _ := request.Context().Value(userUUIDkey).(string)
+
+		err := middleware.UserUsecase.VerifyTwoFactor(request.Context(), userUUID, request.Header.Get("X-TOTP-Code"))
+		if err != nil {
+			writer.Header().Set("Content-Type", "application/json")
+			writer.WriteHeader(http.StatusForbidden)
+
+			webResponse := web.WebResponse{
+				Code:   http.StatusForbidden,
+				Status: "Forbidden",
+				Data:   err.Error(),
+			}
+
+			middleware.Log.Warn().Msg("Forbidden, " + err.Error())
+			helper.WriteToResponseBody(writer, webResponse)
+			return
+		}
+
+		next(writer, request, p)
+	})
+}
+
+// OptionalMiddleware lets anonymous requests through and authenticates the rest, so public endpoints can personalise
+// their response for signed in users.
+func (middleware *AuthMiddleware) OptionalMiddleware(next httprouter.Handle) httprouter.Handle {
+	authenticated := middleware.ServeHTTP(next)
+	return func(writer http.ResponseWriter, request *http.Request, p httprouter.Params) {
+		if request.Header.Get("Authorization") == "" {
+			next(writer, request, p)
+			return
+		}
+
+		authenticated(writer, request, p)
+	}
+}
@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"cosplayrent/internal/helper"
+	"cosplayrent/internal/model/web"
+	"cosplayrent/internal/usecase"
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog"
+	"net/http"
+)
+
+type NotificationController struct {
+	NotificationUsecase *usecase.NotificationUsecase
+	Log                 *zerolog.Logger
+}
+
+func NewNotificationController(notificationUsecase *usecase.NotificationUsecase, zerolog *zerolog.Logger) *NotificationController {
+	return &NotificationController{
+		NotificationUsecase: notificationUsecase,
+		Log:                 zerolog,
+	}
+}
+
+func (controller NotificationController) FindAll(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	notifications := controller.NotificationUsecase.FindUserNotifications(request.Context(), userUUID)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   notifications,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller NotificationController) MarkAllRead(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	controller.NotificationUsecase.MarkAllRead(request.Context(), userUUID)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
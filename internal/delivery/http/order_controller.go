@@ -29,6 +29,7 @@ func (controller OrderController) Create(writer http.ResponseWriter, request *ht
 
 	orderRequest := order.OrderRequest{}
 	helper.ReadFromRequestBody(request, &orderRequest)
+	orderRequest.Totp_code = request.Header.Get("X-TOTP-Code")
 
 	midtransResult, err := controller.OrderUsecase.Create(request.Context(), userUUID, orderRequest)
 	if err != nil {
@@ -4,30 +4,28 @@ import (
 	"cosplayrent/internal/helper"
 	"cosplayrent/internal/model/web"
 	"cosplayrent/internal/model/web/review"
+	"cosplayrent/internal/storage"
 	"cosplayrent/internal/usecase"
 	"errors"
 	"fmt"
 	"github.com/julienschmidt/httprouter"
 	"github.com/rs/zerolog"
-	"io"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
-	"strings"
-	"time"
 )
 
 type ReviewController struct {
 	ReviewUsecase *usecase.ReviewUsecase
 	OrderUsecase  *usecase.OrderUsecase
+	Storage       storage.Storage
 	Log           *zerolog.Logger
 }
 
-func NewReviewController(reviewUsecase *usecase.ReviewUsecase, orderUsecase *usecase.OrderUsecase, zerolog *zerolog.Logger) *ReviewController {
+func NewReviewController(reviewUsecase *usecase.ReviewUsecase, orderUsecase *usecase.OrderUsecase, storage storage.Storage, zerolog *zerolog.Logger) *ReviewController {
 	return &ReviewController{
 		ReviewUsecase: reviewUsecase,
 		OrderUsecase:  orderUsecase,
+		Storage:       storage,
 		Log:           zerolog,
 	}
 }
@@ -37,7 +35,7 @@ func (controller ReviewController) Create(writer http.ResponseWriter, request *h
 
 	request.Body = http.MaxBytesReader(writer, request.Body, 5*1024*1024) // 5 MB
 
-	file, fileHeader, err := request.FormFile("review_picture")
+	file, _, err := request.FormFile("review_picture")
 
 	var reviewPicturePath *string
 
@@ -66,9 +64,12 @@ func (controller ReviewController) Create(writer http.ResponseWriter, request *h
 	} else if file != nil {
 		defer file.Close()
 
-		fileType := fileHeader.Header.Get("Content-Type")
-		if fileType != "image/jpeg" && fileType != "image/png" {
-			respErr := errors.New("file is not image")
+		reviewImageTrimPath, err := helper.SaveImage(request.Context(), controller.Storage, file, "review")
+		if err != nil {
+			if !errors.Is(err, helper.ErrInvalidImage) {
+				respErr := errors.New("failed to save image")
+				controller.Log.Panic().Err(err).Msg(respErr.Error())
+			}
 
 			writer.Header().Set("Content-Type", "application/json")
 			writer.WriteHeader(http.StatusBadRequest)
@@ -76,40 +77,13 @@ func (controller ReviewController) Create(writer http.ResponseWriter, request *h
 			webResponse := web.WebResponse{
 				Code:   http.StatusBadRequest,
 				Status: "Bad Request",
-				Data:   respErr.Error(),
+				Data:   err.Error(),
 			}
 
-			controller.Log.Warn().Err(err).Msg(respErr.Error())
+			controller.Log.Warn().Err(err).Msg("rejected uploaded image")
 			helper.WriteToResponseBody(writer, webResponse)
 			return
 		}
-
-		_, err = os.Stat("../static/review/")
-		if os.IsNotExist(err) {
-			err = os.MkdirAll("../static/review/", os.ModePerm)
-			if err != nil {
-				respErr := errors.New("failed to create directory")
-				controller.Log.Panic().Err(err).Msg(respErr.Error())
-			}
-		}
-
-		fileName := fmt.Sprintf("%d%s", time.Now().UnixNano(), filepath.Ext(fileHeader.Filename))
-		reviewImagePath := filepath.Join("../static/review/", fileName)
-
-		destFile, err := os.Create(reviewImagePath)
-		if err != nil {
-			respErr := errors.New("failed to create file in the directory path")
-			controller.Log.Panic().Err(err).Msg(respErr.Error())
-		}
-		defer destFile.Close()
-
-		_, err = io.Copy(destFile, file)
-		if err != nil {
-			respErr := errors.New("failed to copy a created file from request's file")
-			controller.Log.Panic().Err(err).Msg(respErr.Error())
-		}
-
-		reviewImageTrimPath := strings.TrimPrefix(reviewImagePath, "..")
 		reviewPicturePath = &reviewImageTrimPath
 	}
 
@@ -168,15 +142,31 @@ func (controller ReviewController) Update(writer http.ResponseWriter, request *h
 
 	request.Body = http.MaxBytesReader(writer, request.Body, 5*1024*1024) // 5 MB
 
-	file, fileHeader, err := request.FormFile("review_picture")
+	reviewID, err := strconv.Atoi(params.ByName("reviewID"))
+	if err != nil {
+		respErr := errors.New("invalid review id")
 
-	var reviewPicturePath *string
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
 
-	if err != nil {
-		if err.Error() == "http: no such file" {
+		webResponse := web.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Data:   respErr.Error(),
+		}
 
-		} else if err.Error() == "http: request body too large" {
-			respErr := errors.New("request exceeded 5 mb")
+		controller.Log.Warn().Err(err).Msg(respErr.Error())
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	reviewRequest := review.ReviewUpdateRequest{}
+
+	if helper.IsJSONRequest(request) {
+		helper.ReadFromRequestBody(request, &reviewRequest)
+
+		if reviewRequest.Review_picture.Set {
+			respErr := errors.New("review_picture must be uploaded as a file")
 
 			writer.Header().Set("Content-Type", "application/json")
 			writer.WriteHeader(http.StatusBadRequest)
@@ -187,87 +177,91 @@ func (controller ReviewController) Update(writer http.ResponseWriter, request *h
 				Data:   respErr.Error(),
 			}
 
-			controller.Log.Warn().Err(err).Msg(respErr.Error())
+			controller.Log.Warn().Msg(respErr.Error())
 			helper.WriteToResponseBody(writer, webResponse)
 			return
-		} else {
-			respErr := errors.New("unexpected error handling file upload")
-			controller.Log.Panic().Err(err).Msg(respErr.Error())
 		}
-	} else if file != nil {
-		defer file.Close()
+	} else {
+		file, _, err := request.FormFile("review_picture")
 
-		fileType := fileHeader.Header.Get("Content-Type")
-		if fileType != "image/jpeg" && fileType != "image/png" {
-			respErr := errors.New("file is not image")
+		if err != nil {
+			if err.Error() == "http: no such file" {
 
-			writer.Header().Set("Content-Type", "application/json")
-			writer.WriteHeader(http.StatusBadRequest)
+			} else if err.Error() == "http: request body too large" {
+				respErr := errors.New("request exceeded 5 mb")
 
-			webResponse := web.WebResponse{
-				Code:   http.StatusBadRequest,
-				Status: "Bad Request",
-				Data:   respErr.Error(),
-			}
+				writer.Header().Set("Content-Type", "application/json")
+				writer.WriteHeader(http.StatusBadRequest)
 
-			controller.Log.Warn().Err(err).Msg(respErr.Error())
-			helper.WriteToResponseBody(writer, webResponse)
-			return
-		}
+				webResponse := web.WebResponse{
+					Code:   http.StatusBadRequest,
+					Status: "Bad Request",
+					Data:   respErr.Error(),
+				}
 
-		_, err = os.Stat("../static/review/")
-		if os.IsNotExist(err) {
-			err = os.MkdirAll("../static/review/", os.ModePerm)
-			if err != nil {
-				respErr := errors.New("failed to create directory")
+				controller.Log.Warn().Err(err).Msg(respErr.Error())
+				helper.WriteToResponseBody(writer, webResponse)
+				return
+			} else {
+				respErr := errors.New("unexpected error handling file upload")
 				controller.Log.Panic().Err(err).Msg(respErr.Error())
 			}
-		}
+		} else if file != nil {
+			defer file.Close()
 
-		fileName := fmt.Sprintf("%d%s", time.Now().UnixNano(), filepath.Ext(fileHeader.Filename))
-		reviewImagePath := filepath.Join("../static/review/", fileName)
-
-		destFile, err := os.Create(reviewImagePath)
-		if err != nil {
-			respErr := errors.New("failed to create file in the directory path")
-			controller.Log.Panic().Err(err).Msg(respErr.Error())
+			reviewImageTrimPath, err := helper.SaveImage(request.Context(), controller.Storage, file, "review")
+			if err != nil {
+				if !errors.Is(err, helper.ErrInvalidImage) {
+					respErr := errors.New("failed to save image")
+					controller.Log.Panic().Err(err).Msg(respErr.Error())
+				}
+
+				writer.Header().Set("Content-Type", "application/json")
+				writer.WriteHeader(http.StatusBadRequest)
+
+				webResponse := web.WebResponse{
+					Code:   http.StatusBadRequest,
+					Status: "Bad Request",
+					Data:   err.Error(),
+				}
+
+				controller.Log.Warn().Err(err).Msg("rejected uploaded image")
+				helper.WriteToResponseBody(writer, webResponse)
+				return
+			}
+			reviewRequest.Review_picture = web.Some(reviewImageTrimPath)
 		}
-		defer destFile.Close()
 
-		_, err = io.Copy(destFile, file)
+		reviewRating, err := helper.FormInt(request, "rating")
 		if err != nil {
-			respErr := errors.New("failed to copy a created file from request's file")
-			controller.Log.Panic().Err(err).Msg(respErr.Error())
-		}
-
-		reviewImageTrimPath := strings.TrimPrefix(reviewImagePath, "..")
-		reviewPicturePath = &reviewImageTrimPath
-	}
+			if reviewRequest.Review_picture.Set {
+				helper.RemoveImage(request.Context(), controller.Storage, reviewRequest.Review_picture.Value)
+			}
 
-	reviewID := params.ByName("reviewID")
-	reviewDescription := request.FormValue("description")
-	reviewRating := request.FormValue("rating")
+			writer.Header().Set("Content-Type", "application/json")
+			writer.WriteHeader(http.StatusBadRequest)
 
-	finalReviewId, err := strconv.Atoi(reviewID)
-	if err != nil {
-		respErr := errors.New("error converting string to int")
-		controller.Log.Panic().Err(err).Msg(respErr.Error())
-	}
+			webResponse := web.WebResponse{
+				Code:   http.StatusBadRequest,
+				Status: "Bad Request",
+				Data:   err.Error(),
+			}
 
-	finalRating, err := strconv.Atoi(reviewRating)
-	if err != nil {
-		respErr := errors.New("error converting string to int")
-		controller.Log.Panic().Err(err).Msg(respErr.Error())
-	}
+			controller.Log.Warn().Msg(err.Error())
+			helper.WriteToResponseBody(writer, webResponse)
+			return
+		}
 
-	reviewRequest := review.ReviewUpdateRequest{
-		Review_picture: reviewPicturePath,
-		Description:    reviewDescription,
-		Rating:         finalRating,
+		reviewRequest.Description = helper.FormString(request, "description")
+		reviewRequest.Rating = reviewRating
 	}
 
-	err = controller.ReviewUsecase.Update(request.Context(), reviewRequest, userUUID, finalReviewId)
+	reviewResponse, err := controller.ReviewUsecase.Update(request.Context(), reviewRequest, userUUID, reviewID)
 	if err != nil {
+		if reviewRequest.Review_picture.Set {
+			helper.RemoveImage(request.Context(), controller.Storage, reviewRequest.Review_picture.Value)
+		}
+
 		writer.Header().Set("Content-Type", "application/json")
 		writer.WriteHeader(http.StatusBadRequest)
 
@@ -284,6 +278,7 @@ func (controller ReviewController) Update(writer http.ResponseWriter, request *h
 	webResponse := web.WebResponse{
 		Code:   200,
 		Status: "OK",
+		Data:   reviewResponse,
 	}
 
 	helper.WriteToResponseBody(writer, webResponse)
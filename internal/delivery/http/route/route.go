@@ -4,20 +4,26 @@ import (
 	"cosplayrent/internal/delivery/http"
 	"cosplayrent/internal/delivery/http/middleware"
 	"github.com/julienschmidt/httprouter"
+	"net/http"
 )
 
 type RouteConfig struct {
-	Router               *httprouter.Router
-	UserController       *controller.UserController
-	CostumeController    *controller.CostumeController
-	CategoryController   *controller.CategoryController
-	WishlistController   *controller.WishlistController
-	OrderController      *controller.OrderController
-	ReviewController     *controller.ReviewController
-	TopUpOrderController *controller.TopUpOrderController
-	MidtransController   *controller.MidtransController
-	RajaOngkirController *controller.RajaOngkirController
-	AuthMiddleware       *middleware.AuthMiddleware
+	Router                 *httprouter.Router
+	UserController         *controller.UserController
+	CostumeController      *controller.CostumeController
+	ShopController         *controller.ShopController
+	TrendingController     *controller.TrendingController
+	AnalyticsController    *controller.AnalyticsController
+	NotificationController *controller.NotificationController
+	SearchController       *controller.SearchController
+	CategoryController     *controller.CategoryController
+	WishlistController     *controller.WishlistController
+	OrderController        *controller.OrderController
+	ReviewController       *controller.ReviewController
+	TopUpOrderController   *controller.TopUpOrderController
+	MidtransController     *controller.MidtransController
+	RajaOngkirController   *controller.RajaOngkirController
+	AuthMiddleware         *middleware.AuthMiddleware
 }
 
 func (c *RouteConfig) SetupRoute() {
@@ -26,13 +32,29 @@ func (c *RouteConfig) SetupRoute() {
 	c.Router.POST("/api/register", c.UserController.Register)
 	c.Router.GET("/api/identitycard", c.AuthMiddleware.ServeHTTP(c.UserController.GetIdentityCard))
 	c.Router.PUT("/api/identitycard", c.AuthMiddleware.ServeHTTP(c.UserController.AddOrUpdateIdentityCard))
+	c.Router.GET("/api/identitycard/:userID", c.AuthMiddleware.ServeHTTP(c.UserController.GetIdentityCardImage))
+	c.Router.GET("/api/usermeasurements", c.AuthMiddleware.ServeHTTP(c.UserController.GetMeasurements))
+	c.Router.PUT("/api/usermeasurements", c.AuthMiddleware.ServeHTTP(c.UserController.SaveMeasurements))
+	c.Router.GET("/api/vacation", c.AuthMiddleware.ServeHTTP(c.UserController.GetVacation))
+	c.Router.PUT("/api/vacation", c.AuthMiddleware.ServeHTTP(c.UserController.UpdateVacation))
+	c.Router.DELETE("/api/vacation", c.AuthMiddleware.ServeHTTP(c.UserController.EndVacation))
+	c.Router.GET("/api/admin/identitycard", c.AuthMiddleware.AdminMiddleware(c.UserController.FindPendingIdentityCards))
+	c.Router.PUT("/api/admin/identitycard/:userID/approve", c.AuthMiddleware.AdminMiddleware(c.UserController.ApproveIdentityCard))
+	c.Router.PUT("/api/admin/identitycard/:userID/reject", c.AuthMiddleware.AdminMiddleware(c.UserController.RejectIdentityCard))
 	c.Router.GET("/api/emoney", c.AuthMiddleware.ServeHTTP(c.UserController.GetEMoneyAmount))
 	c.Router.GET("/api/emoneyhistory", c.AuthMiddleware.ServeHTTP(c.UserController.GetEMoneyTransactionHistory))
 	c.Router.POST("/api/login", c.UserController.Login)
+	c.Router.POST("/api/login/twofactor", c.UserController.LoginTwoFactor)
+	c.Router.POST("/api/twofactor/enroll", c.AuthMiddleware.ServeHTTP(c.UserController.EnrollTwoFactor))
+	c.Router.POST("/api/twofactor/confirm", c.AuthMiddleware.ServeHTTP(c.UserController.ConfirmTwoFactor))
+	c.Router.DELETE("/api/twofactor", c.AuthMiddleware.ServeHTTP(c.UserController.DisableTwoFactor))
 	c.Router.GET("/api/userdetail", c.AuthMiddleware.ServeHTTP(c.UserController.FindByUUID))
 	c.Router.GET("/api/user", c.AuthMiddleware.ServeHTTP(c.UserController.FindAll))
 	c.Router.POST("/api/userverification", c.AuthMiddleware.EmailMiddleware(c.UserController.VerifyCode))
 	c.Router.PATCH("/api/userdetail", c.AuthMiddleware.ServeHTTP(c.UserController.Update))
+	c.Router.PUT("/api/userpassword", c.AuthMiddleware.ServeHTTP(c.UserController.ChangePassword))
+	c.Router.POST("/api/useremail", c.AuthMiddleware.ServeHTTP(c.UserController.RequestEmailChange))
+	c.Router.POST("/api/useremail/verification", c.AuthMiddleware.ServeHTTP(c.UserController.VerifyEmailChange))
 	c.Router.DELETE("/api/useraccount", c.AuthMiddleware.ServeHTTP(c.UserController.Delete))
 	c.Router.GET("/api/checksellerstatus", c.AuthMiddleware.ServeHTTP(c.UserController.CheckSellerStatus))
 	c.Router.GET("/api/checkuserstatus/:costumeID", c.AuthMiddleware.ServeHTTP(c.UserController.CheckUserStatus))
@@ -40,15 +62,50 @@ func (c *RouteConfig) SetupRoute() {
 	//c.Router.GET("/api/checkappversion", c.UserController.CheckAppVersion)
 
 	c.Router.POST("/api/costume", c.AuthMiddleware.ServeHTTP(c.CostumeController.Create))
-	c.Router.GET("/api/costume", c.CostumeController.FindAll)
+	c.Router.GET("/api/costume", c.AuthMiddleware.OptionalMiddleware(c.CostumeController.FindAll))
+	c.Router.GET("/api/search/costume", c.AuthMiddleware.OptionalMiddleware(c.SearchController.SearchCostume))
+	c.Router.GET("/api/search/suggest", c.SearchController.Suggest)
 	c.Router.GET("/api/seller", c.AuthMiddleware.ServeHTTP(c.CostumeController.FindSellerCostume))
-	c.Router.GET("/api/costume/:costumeID", c.CostumeController.FindById)
+	c.Router.GET("/api/shop/:sellerID", c.AuthMiddleware.OptionalMiddleware(c.ShopController.FindShop))
+	c.Router.POST("/api/shop/:sellerID/follow", c.AuthMiddleware.ServeHTTP(c.ShopController.Follow))
+	c.Router.DELETE("/api/shop/:sellerID/follow", c.AuthMiddleware.ServeHTTP(c.ShopController.Unfollow))
+	c.Router.GET("/api/feed", c.AuthMiddleware.ServeHTTP(c.ShopController.Feed))
+	c.Router.GET("/api/notifications", c.AuthMiddleware.ServeHTTP(c.NotificationController.FindAll))
+	c.Router.PUT("/api/notifications/read", c.AuthMiddleware.ServeHTTP(c.NotificationController.MarkAllRead))
+	c.Router.POST("/api/costumeimport", c.AuthMiddleware.ServeHTTP(c.CostumeController.Import))
+	c.Router.GET("/api/costumeexport", c.AuthMiddleware.ServeHTTP(c.CostumeController.Export))
+	c.Router.GET("/api/analytics", c.AuthMiddleware.ServeHTTP(c.AnalyticsController.FindSellerAnalytics))
+	c.Router.GET("/api/costume/:costumeID", c.AuthMiddleware.OptionalMiddleware(c.findCostumeOrTrending))
 	c.Router.GET("/api/seller/:costumeID", c.AuthMiddleware.ServeHTTP(c.CostumeController.FindSellerCostumeByCostumeID)) // find by costume id
 	c.Router.PATCH("/api/seller/:costumeID", c.AuthMiddleware.ServeHTTP(c.CostumeController.Update))
 	c.Router.DELETE("/api/seller/:costumeID", c.AuthMiddleware.ServeHTTP(c.CostumeController.Delete))
+	c.Router.POST("/api/seller/:costumeID/images", c.AuthMiddleware.ServeHTTP(c.CostumeController.AddImages))
+	c.Router.PUT("/api/seller/:costumeID/images", c.AuthMiddleware.ServeHTTP(c.CostumeController.ReorderImages))
+	c.Router.PUT("/api/seller/:costumeID/images/:imageID/cover", c.AuthMiddleware.ServeHTTP(c.CostumeController.SetCoverImage))
+	c.Router.DELETE("/api/seller/:costumeID/images/:imageID", c.AuthMiddleware.ServeHTTP(c.CostumeController.DeleteImage))
+	c.Router.PUT("/api/seller/:costumeID/status", c.AuthMiddleware.ServeHTTP(c.CostumeController.UpdateStatus))
+	c.Router.PUT("/api/seller/:costumeID/measurements", c.AuthMiddleware.ServeHTTP(c.CostumeController.UpdateMeasurements))
+	c.Router.PUT("/api/seller/:costumeID/pricing", c.AuthMiddleware.ServeHTTP(c.CostumeController.UpdatePricing))
+	c.Router.GET("/api/costume/:costumeID/variants", c.CostumeController.FindVariants)
+	c.Router.POST("/api/seller/:costumeID/variants", c.AuthMiddleware.ServeHTTP(c.CostumeController.CreateVariant))
+	c.Router.PATCH("/api/seller/:costumeID/variants/:variantID", c.AuthMiddleware.ServeHTTP(c.CostumeController.UpdateVariant))
+	c.Router.DELETE("/api/seller/:costumeID/variants/:variantID", c.AuthMiddleware.ServeHTTP(c.CostumeController.DeleteVariant))
+	c.Router.GET("/api/costume/:costumeID/availability", c.CostumeController.FindAvailability)
+	c.Router.GET("/api/costume/:costumeID/similar", c.AuthMiddleware.OptionalMiddleware(c.CostumeController.FindSimilar))
+	c.Router.PUT("/api/seller/:costumeID/turnaround", c.AuthMiddleware.ServeHTTP(c.CostumeController.UpdateTurnaround))
+	c.Router.POST("/api/seller/:costumeID/blackouts", c.AuthMiddleware.ServeHTTP(c.CostumeController.CreateBlackout))
+	c.Router.DELETE("/api/seller/:costumeID/blackouts/:blackoutID", c.AuthMiddleware.ServeHTTP(c.CostumeController.DeleteBlackout))
+	c.Router.GET("/api/costume/:costumeID/fit", c.AuthMiddleware.ServeHTTP(c.CostumeController.FindFit))
 
 	c.Router.GET("/api/categories", c.CategoryController.FindAllCategory)
-	//c.Router.DELETE("/api/category", c.C)
+	c.Router.GET("/api/categories/tree", c.CategoryController.FindCategoryTree)
+	c.Router.POST("/api/admin/category", c.AuthMiddleware.AdminMiddleware(c.CategoryController.Create))
+	c.Router.PATCH("/api/admin/category/:categoryID", c.AuthMiddleware.AdminMiddleware(c.CategoryController.Update))
+	c.Router.DELETE("/api/admin/category/:categoryID", c.AuthMiddleware.AdminMiddleware(c.CategoryController.Delete))
+	c.Router.GET("/api/category/:categoryID/attributes", c.CategoryController.FindAttributes)
+	c.Router.POST("/api/admin/category/:categoryID/attributes", c.AuthMiddleware.AdminMiddleware(c.CategoryController.CreateAttribute))
+	c.Router.PATCH("/api/admin/category/:categoryID/attributes/:attributeID", c.AuthMiddleware.AdminMiddleware(c.CategoryController.UpdateAttribute))
+	c.Router.DELETE("/api/admin/category/:categoryID/attributes/:attributeID", c.AuthMiddleware.AdminMiddleware(c.CategoryController.DeleteAttribute))
 
 	c.Router.GET("/api/review", c.AuthMiddleware.ServeHTTP(c.ReviewController.FindUserReview))
 	c.Router.POST("/api/review", c.AuthMiddleware.ServeHTTP(c.ReviewController.Create))
@@ -71,7 +128,7 @@ func (c *RouteConfig) SetupRoute() {
 	c.Router.POST("/api/checkbalancewithorderamount", c.AuthMiddleware.ServeHTTP(c.OrderController.CheckBalanceWithOrderAmount))
 	c.Router.POST("/api/orderevents/:orderID", c.AuthMiddleware.ServeHTTP(c.OrderController.CreateOrderEvents))
 
-	c.Router.PUT("/api/topup", c.AuthMiddleware.ServeHTTP(c.TopUpOrderController.CreateTopUpOrder))
+	c.Router.PUT("/api/topup", c.AuthMiddleware.TwoFactorMiddleware(c.TopUpOrderController.CreateTopUpOrder))
 	c.Router.GET("/api/checktopuporder/:orderID", c.TopUpOrderController.CheckTopUpOrderByOrderId)
 
 	c.Router.GET("/api/wishlist", c.AuthMiddleware.ServeHTTP(c.WishlistController.FindAllWishListByUserId))
@@ -85,3 +142,12 @@ func (c *RouteConfig) SetupRoute() {
 
 	c.Router.POST("/api/midtrans/callback", c.MidtransController.MidtransCallBack)
 }
+
+// findCostumeOrTrending serves /api/costume/trending, httprouter cannot register it next to /api/costume/:costumeID.
+func (c *RouteConfig) findCostumeOrTrending(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	if params.ByName("costumeID") == "trending" {
+		c.TrendingController.FindTrending(writer, request, params)
+		return
+	}
+	c.CostumeController.FindById(writer, request, params)
+}
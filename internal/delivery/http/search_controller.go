@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"cosplayrent/internal/helper"
+	"cosplayrent/internal/model/web"
+	"cosplayrent/internal/model/web/costume"
+	"cosplayrent/internal/usecase"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog"
+	"net/http"
+	"strconv"
+)
+
+type SearchController struct {
+	SearchUsecase *usecase.SearchUsecase
+	Log           *zerolog.Logger
+}
+
+func NewSearchController(searchUsecase *usecase.SearchUsecase, zerolog *zerolog.Logger) *SearchController {
+	return &SearchController{
+		SearchUsecase: searchUsecase,
+		Log:           zerolog,
+	}
+}
+
+func (controller SearchController) SearchCostume(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	searchRequest := costume.CostumeTextSearchRequest{
+		Q:      request.URL.Query().Get("q"),
+		Cursor: request.URL.Query().Get("cursor"),
+	}
+	searchRequest.Buyer_id, _ = request.Context().Value("user_uuid").(string)
+
+	var err error
+	if limit := request.URL.Query().Get("limit"); limit != "" {
+		searchRequest.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			err = errors.New("limit must be a number")
+		}
+	}
+
+	searchResponse := costume.CostumeSearchListResponse{}
+	if err == nil {
+		searchResponse, err = controller.SearchUsecase.SearchCostume(request.Context(), searchRequest)
+	}
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   searchResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller SearchController) Suggest(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	suggestResponse, err := controller.SearchUsecase.Suggest(request.Context(), request.URL.Query().Get("q"))
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   suggestResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
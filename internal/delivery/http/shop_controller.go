@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"cosplayrent/internal/helper"
+	"cosplayrent/internal/model/web"
+	"cosplayrent/internal/model/web/shop"
+	"cosplayrent/internal/usecase"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog"
+	"net/http"
+	"strconv"
+)
+
+type ShopController struct {
+	ShopUsecase *usecase.ShopUsecase
+	Log         *zerolog.Logger
+}
+
+func NewShopController(shopUsecase *usecase.ShopUsecase, zerolog *zerolog.Logger) *ShopController {
+	return &ShopController{
+		ShopUsecase: shopUsecase,
+		Log:         zerolog,
+	}
+}
+
+func (controller ShopController) FindShop(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	searchRequest, err := costumeSearchRequestFromQuery(request.URL.Query())
+	searchRequest.Buyer_id, _ = request.Context().Value("user_uuid").(string)
+
+	shopResponse := shop.ShopResponse{}
+	if err == nil {
+		shopResponse, err = controller.ShopUsecase.FindShop(request.Context(), params.ByName("sellerID"), searchRequest)
+	}
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		status := "Bad Request"
+		if err.Error() == "shop not found" {
+			statusCode = http.StatusNotFound
+			status = "Not Found"
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(statusCode)
+
+		webResponse := web.WebResponse{
+			Code:   statusCode,
+			Status: status,
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   shopResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller ShopController) Follow(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	err := controller.ShopUsecase.Follow(request.Context(), userUUID, params.ByName("sellerID"))
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		status := "Bad Request"
+		if err.Error() == "shop not found" {
+			statusCode = http.StatusNotFound
+			status = "Not Found"
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(statusCode)
+
+		webResponse := web.WebResponse{
+			Code:   statusCode,
+			Status: status,
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller ShopController) Unfollow(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	controller.ShopUsecase.Unfollow(request.Context(), userUUID, params.ByName("sellerID"))
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller ShopController) Feed(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	feedRequest := shop.FeedRequest{
+		Cursor: request.URL.Query().Get("cursor"),
+	}
+	feedRequest.Follower_id, _ = request.Context().Value("user_uuid").(string)
+
+	var err error
+	if limit := request.URL.Query().Get("limit"); limit != "" {
+		feedRequest.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			err = errors.New("limit must be a number")
+		}
+	}
+
+	feedResponse := shop.FeedResponse{}
+	if err == nil {
+		feedResponse, err = controller.ShopUsecase.Feed(request.Context(), feedRequest)
+	}
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   feedResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
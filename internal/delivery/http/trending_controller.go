@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"cosplayrent/internal/helper"
+	"cosplayrent/internal/model/web"
+	"cosplayrent/internal/model/web/costume"
+	"cosplayrent/internal/usecase"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog"
+	"net/http"
+	"strconv"
+)
+
+type TrendingController struct {
+	TrendingUsecase *usecase.TrendingUsecase
+	Log             *zerolog.Logger
+}
+
+func NewTrendingController(trendingUsecase *usecase.TrendingUsecase, zerolog *zerolog.Logger) *TrendingController {
+	return &TrendingController{
+		TrendingUsecase: trendingUsecase,
+		Log:             zerolog,
+	}
+}
+
+func (controller TrendingController) FindTrending(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	trendingRequest := costume.CostumeTrendingRequest{}
+
+	var err error
+	intParams := map[string]*int{
+		"kategori": &trendingRequest.Kategori,
+		"city_id":  &trendingRequest.City_id,
+		"limit":    &trendingRequest.Limit,
+	}
+	for key, target := range intParams {
+		if request.URL.Query().Get(key) == "" {
+			continue
+		}
+		*target, err = strconv.Atoi(request.URL.Query().Get(key))
+		if err != nil {
+			err = errors.New(key + " must be a number")
+			break
+		}
+	}
+
+	costumes := []costume.CostumeResponse{}
+	if err == nil {
+		costumes, err = controller.TrendingUsecase.FindTrending(request.Context(), trendingRequest)
+	}
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   costumes,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
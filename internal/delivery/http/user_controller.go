@@ -4,16 +4,11 @@ import (
 	"cosplayrent/internal/helper"
 	"cosplayrent/internal/model/web"
 	"cosplayrent/internal/model/web/user"
+	"cosplayrent/internal/storage"
 	"cosplayrent/internal/usecase"
 	"errors"
-	"fmt"
-	"io"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
-	"strings"
-	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/rs/zerolog"
@@ -21,12 +16,14 @@ import (
 
 type UserController struct {
 	UserUsecase *usecase.UserUsecase
+	Storage     storage.Storage
 	Log         *zerolog.Logger
 }
 
-func NewUserController(userUsecase *usecase.UserUsecase, zerolog *zerolog.Logger) *UserController {
+func NewUserController(userUsecase *usecase.UserUsecase, storage storage.Storage, zerolog *zerolog.Logger) *UserController {
 	return &UserController{
 		UserUsecase: userUsecase,
+		Storage:     storage,
 		Log:         zerolog,
 	}
 }
@@ -65,7 +62,7 @@ func (controller UserController) Login(writer http.ResponseWriter, request *http
 	userLoginRequest := user.UserLoginRequest{}
 	helper.ReadFromRequestBody(request, &userLoginRequest)
 
-	token, err := controller.UserUsecase.Login(request.Context(), userLoginRequest)
+	loginResponse, err := controller.UserUsecase.Login(request.Context(), userLoginRequest)
 	if err != nil {
 		writer.Header().Set("Content-Type", "application/json")
 		writer.WriteHeader(http.StatusBadRequest)
@@ -79,6 +76,33 @@ func (controller UserController) Login(writer http.ResponseWriter, request *http
 		return
 	}
 
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   loginResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller UserController) LoginTwoFactor(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	twoFactorLoginRequest := user.TwoFactorLoginRequest{}
+	helper.ReadFromRequestBody(request, &twoFactorLoginRequest)
+
+	token, err := controller.UserUsecase.LoginTwoFactor(request.Context(), twoFactorLoginRequest)
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusUnauthorized)
+		webResponse := web.WebResponse{
+			Code:   http.StatusUnauthorized,
+			Status: "Unauthorized",
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
 	tokenResponse := web.TokenResponse{
 		Token: token,
 	}
@@ -91,6 +115,89 @@ func (controller UserController) Login(writer http.ResponseWriter, request *http
 	helper.WriteToResponseBody(writer, webResponse)
 }
 
+func (controller UserController) EnrollTwoFactor(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	enrollResponse, err := controller.UserUsecase.EnrollTwoFactor(request.Context(), userUUID)
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+		webResponse := web.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   enrollResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller UserController) ConfirmTwoFactor(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	codeRequest := user.TwoFactorCodeRequest{}
+	helper.ReadFromRequestBody(request, &codeRequest)
+
+	recoveryCodesResponse, err := controller.UserUsecase.ConfirmTwoFactor(request.Context(), codeRequest, userUUID)
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+		webResponse := web.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   recoveryCodesResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller UserController) DisableTwoFactor(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	codeRequest := user.TwoFactorCodeRequest{}
+	helper.ReadFromRequestBody(request, &codeRequest)
+
+	err := controller.UserUsecase.DisableTwoFactor(request.Context(), codeRequest, userUUID)
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+		webResponse := web.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
 func (controller UserController) VerifyCode(writer http.ResponseWriter, request *http.Request, _ httprouter.Params) {
 	userVerificationCodeRequest := user.UserVerificationCode{}
 	helper.ReadFromRequestBody(request, &userVerificationCodeRequest)
@@ -177,37 +284,92 @@ func (controller UserController) Update(writer http.ResponseWriter, request *htt
 
 	request.Body = http.MaxBytesReader(writer, request.Body, 5*1024*1024) // 5 MB
 
-	file, fileHeader, err := request.FormFile("profile_picture")
-
-	var profilePicturePath *string
+	userRequest := user.UserPatchRequest{}
 
-	if err != nil {
-		if err.Error() == "http: no such file" {
+	if helper.IsJSONRequest(request) {
+		helper.ReadFromRequestBody(request, &userRequest)
 
-		} else if err.Error() == "http: request body too large" {
-			respErr := errors.New("request exceeded 5 mb")
+		if userRequest.Profile_picture.Set && !userRequest.Profile_picture.Null {
+			respErr := errors.New("profile_picture must be uploaded as a file")
 
 			writer.Header().Set("Content-Type", "application/json")
 			writer.WriteHeader(http.StatusBadRequest)
+
 			webResponse := web.WebResponse{
 				Code:   http.StatusBadRequest,
 				Status: "Bad Request",
 				Data:   respErr.Error(),
 			}
 
-			controller.Log.Warn().Err(err).Msg(respErr.Error())
+			controller.Log.Warn().Msg(respErr.Error())
 			helper.WriteToResponseBody(writer, webResponse)
 			return
-		} else {
-			respErr := errors.New("unexpected error handling file upload")
-			controller.Log.Panic().Err(err).Msg(respErr.Error())
 		}
-	} else if file != nil {
-		defer file.Close()
+	} else {
+		file, _, err := request.FormFile("profile_picture")
 
-		fileType := fileHeader.Header.Get("Content-Type")
-		if fileType != "image/jpeg" && fileType != "image/png" {
-			respErr := errors.New("file is not image")
+		if err != nil {
+			if err.Error() == "http: no such file" {
+
+			} else if err.Error() == "http: request body too large" {
+				respErr := errors.New("request exceeded 5 mb")
+
+				writer.Header().Set("Content-Type", "application/json")
+				writer.WriteHeader(http.StatusBadRequest)
+				webResponse := web.WebResponse{
+					Code:   http.StatusBadRequest,
+					Status: "Bad Request",
+					Data:   respErr.Error(),
+				}
+
+				controller.Log.Warn().Err(err).Msg(respErr.Error())
+				helper.WriteToResponseBody(writer, webResponse)
+				return
+			} else {
+				respErr := errors.New("unexpected error handling file upload")
+				controller.Log.Panic().Err(err).Msg(respErr.Error())
+			}
+		} else if file != nil {
+			defer file.Close()
+
+			userImageTrimPath, err := helper.SaveImage(request.Context(), controller.Storage, file, "profile")
+			if err != nil {
+				if !errors.Is(err, helper.ErrInvalidImage) {
+					respErr := errors.New("failed to save image")
+					controller.Log.Panic().Err(err).Msg(respErr.Error())
+				}
+
+				writer.Header().Set("Content-Type", "application/json")
+				writer.WriteHeader(http.StatusBadRequest)
+
+				webResponse := web.WebResponse{
+					Code:   http.StatusBadRequest,
+					Status: "Bad Request",
+					Data:   err.Error(),
+				}
+
+				controller.Log.Warn().Err(err).Msg("rejected uploaded image")
+				helper.WriteToResponseBody(writer, webResponse)
+				return
+			}
+			userRequest.Profile_picture = web.Some(userImageTrimPath)
+		}
+
+		if profilePicture := helper.FormString(request, "profile_picture"); !userRequest.Profile_picture.Set && profilePicture.Null {
+			userRequest.Profile_picture = profilePicture
+		}
+
+		originProvinceId, provinceErr := helper.FormInt(request, "origin_province_id")
+		originCityId, cityErr := helper.FormInt(request, "origin_city_id")
+		if provinceErr != nil || cityErr != nil {
+			respErr := provinceErr
+			if respErr == nil {
+				respErr = cityErr
+			}
+
+			if userRequest.Profile_picture.Set && !userRequest.Profile_picture.Null {
+				helper.RemoveImage(request.Context(), controller.Storage, userRequest.Profile_picture.Value)
+			}
 
 			writer.Header().Set("Content-Type", "application/json")
 			writer.WriteHeader(http.StatusBadRequest)
@@ -218,81 +380,25 @@ func (controller UserController) Update(writer http.ResponseWriter, request *htt
 				Data:   respErr.Error(),
 			}
 
-			controller.Log.Warn().Err(err).Msg(respErr.Error())
+			controller.Log.Warn().Msg(respErr.Error())
 			helper.WriteToResponseBody(writer, webResponse)
 			return
 		}
 
-		_, err = os.Stat("../static/profile/")
-		if os.IsNotExist(err) {
-			err = os.MkdirAll("../static/profile/", os.ModePerm)
-			if err != nil {
-				respErr := errors.New("failed to create directory")
-				controller.Log.Panic().Err(err).Msg(respErr.Error())
-			}
-		}
-
-		fileName := fmt.Sprintf("%d%s", time.Now().UnixNano(), filepath.Ext(fileHeader.Filename))
-		profileImagePath := filepath.Join("../static/profile/", fileName)
-
-		destFile, err := os.Create(profileImagePath)
-		if err != nil {
-			respErr := errors.New("failed to create file in the directory path")
-			controller.Log.Panic().Err(err).Msg(respErr.Error())
-		}
-		defer destFile.Close()
-
-		_, err = io.Copy(destFile, file)
-		if err != nil {
-			respErr := errors.New("failed to copy a created file from request's file")
-			controller.Log.Panic().Err(err).Msg(respErr.Error())
-		}
-
-		userImageTrimPath := strings.TrimPrefix(profileImagePath, "..")
-		profilePicturePath = &userImageTrimPath
+		userRequest.Name = helper.FormString(request, "name")
+		userRequest.Address = helper.FormString(request, "address")
+		userRequest.Origin_province_name = helper.FormString(request, "origin_province_name")
+		userRequest.Origin_province_id = originProvinceId
+		userRequest.Origin_city_name = helper.FormString(request, "origin_city_name")
+		userRequest.Origin_city_id = originCityId
 	}
 
-	userName := request.FormValue("name")
-	userEmail := request.FormValue("email")
-	userAddress := request.FormValue("address")
-	userOriginProvinceName := request.FormValue("origin_province_name")
-	userOriginProvinceId := request.FormValue("origin_province_id")
-	userOriginCityName := request.FormValue("origin_city_name")
-	userOriginCityId := request.FormValue("origin_city_id")
-
-	var originCityIdFinal int
-	if userOriginCityId != "" {
-		fmt.Println("id :", userOriginCityId)
-		originCityIdFinal, err = strconv.Atoi(userOriginCityId)
-		if err != nil {
-			respErr := errors.New("error converting string to int")
-			controller.Log.Panic().Err(err).Msg(respErr.Error())
-		}
-	}
-
-	var originProvinceIdFinal int
-	if userOriginProvinceId != "" {
-		originProvinceIdFinal, err = strconv.Atoi(userOriginProvinceId)
-		if err != nil {
-			respErr := errors.New("error converting string to int")
-			controller.Log.Panic().Err(err).Msg(respErr.Error())
+	userResponse, err := controller.UserUsecase.Update(request.Context(), userRequest, userUUID)
+	if err != nil {
+		if userRequest.Profile_picture.Set && !userRequest.Profile_picture.Null {
+			helper.RemoveImage(request.Context(), controller.Storage, userRequest.Profile_picture.Value)
 		}
-	}
 
-	userRequest := user.UserPatchRequest{
-		Name:                 &userName,
-		Email:                &userEmail,
-		Address:              &userAddress,
-		Profile_picture:      profilePicturePath,
-		Origin_province_name: &userOriginProvinceName,
-		Origin_province_id:   &originProvinceIdFinal,
-		Origin_city_name:     &userOriginCityName,
-		Origin_city_id:       &originCityIdFinal,
-	}
-
-	err = controller.UserUsecase.Update(request.Context(), userRequest, userUUID)
-
-	if err != nil {
 		writer.Header().Set("Content-Type", "application/json")
 		writer.WriteHeader(http.StatusBadRequest)
 
@@ -309,6 +415,7 @@ func (controller UserController) Update(writer http.ResponseWriter, request *htt
 	webResponse := web.WebResponse{
 		Code:   200,
 		Status: "OK",
+		Data:   userResponse,
 	}
 
 	helper.WriteToResponseBody(writer, webResponse)
@@ -319,7 +426,7 @@ func (controller UserController) AddOrUpdateIdentityCard(writer http.ResponseWri
 
 	request.Body = http.MaxBytesReader(writer, request.Body, 5*1024*1024) // 5 Mb
 
-	file, fileHeader, err := request.FormFile("identity_card")
+	file, _, err := request.FormFile("identity_card")
 
 	if err != nil {
 		if err.Error() == "http: no such file" {
@@ -356,9 +463,14 @@ func (controller UserController) AddOrUpdateIdentityCard(writer http.ResponseWri
 		return
 	}
 
-	fileType := fileHeader.Header.Get("Content-Type")
-	if fileType != "image/jpeg" && fileType != "image/png" {
-		respErr := errors.New("file is not image")
+	defer file.Close()
+
+	identityCardFile, err := helper.SanitizeImage(file)
+	if err != nil {
+		if !errors.Is(err, helper.ErrInvalidImage) {
+			respErr := errors.New("failed to read request's file")
+			controller.Log.Panic().Err(err).Msg(respErr.Error())
+		}
 
 		writer.Header().Set("Content-Type", "application/json")
 		writer.WriteHeader(http.StatusBadRequest)
@@ -366,54 +478,160 @@ func (controller UserController) AddOrUpdateIdentityCard(writer http.ResponseWri
 		webResponse := web.WebResponse{
 			Code:   http.StatusBadRequest,
 			Status: "Bad Request",
-			Data:   respErr.Error(),
+			Data:   err.Error(),
 		}
 
-		controller.Log.Warn().Err(err).Msg(respErr.Error())
+		controller.Log.Warn().Err(err).Msg("rejected uploaded image")
 
 		helper.WriteToResponseBody(writer, webResponse)
 		return
 	}
 
-	defer file.Close()
+	userRequest := user.IdentityCardRequest{
+		IdentityCard_file: identityCardFile,
+	}
 
-	var IdentityCardPicturePath *string
+	err = controller.UserUsecase.AddOrUpdateIdentityCard(request.Context(), userUUID, userRequest)
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
 
-	_, err = os.Stat("../static/identity_card/")
-	if os.IsNotExist(err) {
-		err = os.MkdirAll("../static/identity_card/", os.ModePerm)
-		if err != nil {
-			respErr := errors.New("failed to create directory")
-			controller.Log.Panic().Err(err).Msg(respErr.Error())
+		webResponse := web.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Data:   err.Error(),
 		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
 	}
 
-	fileName := fmt.Sprintf("%d%s", time.Now().UnixNano(), filepath.Ext(fileHeader.Filename))
-	IdentityCardImagePath := filepath.Join("../static/identity_card/", fileName)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller UserController) GetIdentityCard(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
 
-	destFile, err := os.Create(IdentityCardImagePath)
+	userResponse, err := controller.UserUsecase.GetIdentityCard(request.Context(), userUUID)
 	if err != nil {
-		respErr := errors.New("failed to create file in the directory path")
-		controller.Log.Panic().Err(err).Msg(respErr.Error())
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusNotFound)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusNotFound,
+			Status: "Not Found",
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
 	}
 
-	defer destFile.Close()
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   userResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller UserController) GetMeasurements(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
 
-	_, err = io.Copy(destFile, file)
+	measurementResponse, err := controller.UserUsecase.GetMeasurements(request.Context(), userUUID)
 	if err != nil {
-		respErr := errors.New("failed to copy a created file from request's file")
-		controller.Log.Panic().Err(err).Msg(respErr.Error())
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusNotFound)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusNotFound,
+			Status: "Not Found",
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
 	}
 
-	IdentityCardImageTrimPath := strings.TrimPrefix(IdentityCardImagePath, "..")
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   measurementResponse,
+	}
 
-	IdentityCardPicturePath = &IdentityCardImageTrimPath
+	helper.WriteToResponseBody(writer, webResponse)
+}
 
-	userRequest := user.IdentityCardRequest{
-		IdentityCard_picture: IdentityCardPicturePath,
+func (controller UserController) SaveMeasurements(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	measurementRequest := user.UserMeasurementRequest{}
+	helper.ReadFromRequestBody(request, &measurementRequest)
+
+	measurementResponse, err := controller.UserUsecase.SaveMeasurements(request.Context(), userUUID, measurementRequest)
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
 	}
 
-	err = controller.UserUsecase.AddOrUpdateIdentityCard(request.Context(), userUUID, userRequest)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   measurementResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller UserController) GetVacation(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	vacationResponse, err := controller.UserUsecase.GetVacation(request.Context(), userUUID)
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusNotFound)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusNotFound,
+			Status: "Not Found",
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   vacationResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller UserController) UpdateVacation(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	vacationRequest := user.UserVacationRequest{}
+	helper.ReadFromRequestBody(request, &vacationRequest)
+
+	vacationResponse, err := controller.UserUsecase.UpdateVacation(request.Context(), userUUID, vacationRequest)
 	if err != nil {
 		writer.Header().Set("Content-Type", "application/json")
 		writer.WriteHeader(http.StatusBadRequest)
@@ -431,15 +649,58 @@ func (controller UserController) AddOrUpdateIdentityCard(writer http.ResponseWri
 	webResponse := web.WebResponse{
 		Code:   200,
 		Status: "OK",
+		Data:   vacationResponse,
 	}
 
 	helper.WriteToResponseBody(writer, webResponse)
 }
 
-func (controller UserController) GetIdentityCard(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+func (controller UserController) EndVacation(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
 	userUUID, _ := request.Context().Value("user_uuid").(string)
 
-	userResponse, err := controller.UserUsecase.GetIdentityCard(request.Context(), userUUID)
+	controller.UserUsecase.EndVacation(request.Context(), userUUID)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller UserController) GetIdentityCardImage(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+	ownerID := params.ByName("userID")
+
+	identityCardFile, err := controller.UserUsecase.GetIdentityCardImage(request.Context(), userUUID, ownerID)
+	if err != nil {
+		statusCode := http.StatusNotFound
+		status := "Not Found"
+		if err.Error() == "identity card access denied" {
+			statusCode = http.StatusForbidden
+			status = "Forbidden"
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(statusCode)
+
+		webResponse := web.WebResponse{
+			Code:   statusCode,
+			Status: status,
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	writer.Header().Set("Content-Type", http.DetectContentType(identityCardFile))
+	writer.Header().Set("Cache-Control", "no-store")
+	writer.Write(identityCardFile)
+}
+
+func (controller UserController) FindPendingIdentityCards(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	pendingResponse, err := controller.UserUsecase.FindPendingIdentityCards(request.Context())
 	if err != nil {
 		writer.Header().Set("Content-Type", "application/json")
 		writer.WriteHeader(http.StatusNotFound)
@@ -457,7 +718,64 @@ func (controller UserController) GetIdentityCard(writer http.ResponseWriter, req
 	webResponse := web.WebResponse{
 		Code:   200,
 		Status: "OK",
-		Data:   userResponse,
+		Data:   pendingResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller UserController) ApproveIdentityCard(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	adminUUID, _ := request.Context().Value("user_uuid").(string)
+	userID := params.ByName("userID")
+
+	err := controller.UserUsecase.ApproveIdentityCard(request.Context(), adminUUID, userID)
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller UserController) RejectIdentityCard(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	adminUUID, _ := request.Context().Value("user_uuid").(string)
+	userID := params.ByName("userID")
+
+	rejectRequest := user.IdentityCardRejectRequest{}
+	helper.ReadFromRequestBody(request, &rejectRequest)
+
+	err := controller.UserUsecase.RejectIdentityCard(request.Context(), adminUUID, userID, rejectRequest)
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
 	}
 
 	helper.WriteToResponseBody(writer, webResponse)
@@ -599,6 +917,93 @@ func (controller UserController) FindSellerAddressDetailByCostumeId(writer http.
 	helper.WriteToResponseBody(writer, webResponse)
 }
 
+func (controller UserController) ChangePassword(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	passwordRequest := user.UserChangePasswordRequest{}
+	helper.ReadFromRequestBody(request, &passwordRequest)
+
+	err := controller.UserUsecase.ChangePassword(request.Context(), passwordRequest, userUUID)
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller UserController) RequestEmailChange(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	emailRequest := user.UserChangeEmailRequest{}
+	helper.ReadFromRequestBody(request, &emailRequest)
+
+	err := controller.UserUsecase.RequestEmailChange(request.Context(), emailRequest, userUUID)
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller UserController) VerifyEmailChange(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	userUUID, _ := request.Context().Value("user_uuid").(string)
+
+	codeRequest := user.UserVerificationCode{}
+	helper.ReadFromRequestBody(request, &codeRequest)
+
+	err := controller.UserUsecase.VerifyEmailChange(request.Context(), codeRequest, userUUID)
+	if err != nil {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+
+		webResponse := web.WebResponse{
+			Code:   http.StatusBadRequest,
+			Status: "Bad Request",
+			Data:   err.Error(),
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
 func (controller UserController) Delete(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
 	userUUID, _ := request.Context().Value("user_uuid").(string)
 
@@ -0,0 +1,73 @@
+package helper
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Cursor marks the last row of a keyset page, Value is the sort column rendered as text.
+type Cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	Id    int    `json:"i"`
+}
+
+// cursorValueKinds is how each sort renders its cursor value. The value ends up in a $n::<type> cast, so DecodeCursor
+// parses it first and a tampered cursor is a bad request instead of a failing query.
+var cursorValueKinds = map[string]string{
+	"newest":     "timestamp",
+	"feed":       "timestamp",
+	"price_asc":  "number",
+	"price_desc": "number",
+	"rating":     "number",
+	"trending":   "number",
+	"relevance":  "number",
+	"popularity": "integer",
+}
+
+// cursorTimestampLayout reads a postgres timestamp rendered as text, the fraction is left out when it is zero.
+const cursorTimestampLayout = "2006-01-02 15:04:05.999999"
+
+func EncodeCursor(cursor Cursor) string {
+	cursorJSON, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(cursorJSON)
+}
+
+func DecodeCursor(encoded string) (Cursor, error) {
+	cursor := Cursor{}
+
+	cursorJSON, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, errors.New("invalid cursor")
+	}
+
+	if err := json.Unmarshal(cursorJSON, &cursor); err != nil {
+		return cursor, errors.New("invalid cursor")
+	}
+
+	if !validCursorValue(cursorValueKinds[cursor.Sort], cursor.Value) {
+		return Cursor{}, errors.New("invalid cursor")
+	}
+
+	return cursor, nil
+}
+
+func validCursorValue(kind string, value string) bool {
+	switch kind {
+	case "timestamp":
+		_, err := time.Parse(cursorTimestampLayout, value)
+		return err == nil
+	case "number":
+		number, err := strconv.ParseFloat(value, 64)
+		return err == nil && !math.IsNaN(number) && !math.IsInf(number, 0)
+	case "integer":
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil
+	default:
+		return false
+	}
+}
@@ -0,0 +1,58 @@
+package helper
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	// values as postgres renders each sort column with ::text
+	for _, cursor := range []Cursor{
+		{Sort: "newest", Value: "2026-10-19 13:46:04.123456", Id: 42},
+		{Sort: "newest", Value: "2026-10-19 13:46:04", Id: 42},
+		{Sort: "feed", Value: "2026-10-19 13:46:04.5", Id: 7},
+		{Sort: "price_asc", Value: "150000.00", Id: 42},
+		{Sort: "price_desc", Value: "150000.00", Id: 42},
+		{Sort: "rating", Value: "4.5", Id: 42},
+		{Sort: "trending", Value: "1.2e-05", Id: 42},
+		{Sort: "relevance", Value: "0.0607927", Id: 42},
+		{Sort: "popularity", Value: "12", Id: 42},
+	} {
+		decoded, err := DecodeCursor(EncodeCursor(cursor))
+		if err != nil {
+			t.Errorf("DecodeCursor(%+v): %v", cursor, err)
+			continue
+		}
+		if decoded != cursor {
+			t.Errorf("decoded = %+v, want %+v", decoded, cursor)
+		}
+	}
+}
+
+func TestDecodeCursorRejectsTamperedValues(t *testing.T) {
+	for _, cursor := range []Cursor{
+		{Sort: "newest", Value: "yesterday", Id: 1},
+		{Sort: "newest", Value: "2026-10-19'); DROP TABLE costumes; --", Id: 1},
+		{Sort: "price_asc", Value: "cheap", Id: 1},
+		{Sort: "rating", Value: "NaN", Id: 1},
+		{Sort: "trending", Value: "1e400", Id: 1},
+		{Sort: "popularity", Value: "1.5", Id: 1},
+		{Sort: "unknown", Value: "1", Id: 1},
+	} {
+		if _, err := DecodeCursor(EncodeCursor(cursor)); err == nil {
+			t.Errorf("DecodeCursor accepted %+v", cursor)
+		}
+	}
+}
+
+func TestDecodeCursorRejectsGarbage(t *testing.T) {
+	for _, encoded := range []string{
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte("not json")),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"s":"newest","v":"x","i":"seven"}`)),
+	} {
+		if _, err := DecodeCursor(encoded); err == nil {
+			t.Errorf("DecodeCursor(%q) accepted a malformed cursor", encoded)
+		}
+	}
+}
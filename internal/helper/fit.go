@@ -0,0 +1,143 @@
+package helper
+
+import (
+	"cosplayrent/internal/model/domain"
+	"cosplayrent/internal/model/web/costume"
+	"math"
+	"strconv"
+)
+
+const (
+	// a costume fits when every compared dimension scores at least this much
+	fitDimensionThreshold = 0.75
+	goodFitScore          = 80
+	possibleFitScore      = 50
+)
+
+// ScoreFit compares a costume's garment measurements with a buyer's body measurements. Only dimensions known on
+// both sides are scored, each from 0 to 1, and the overall score is their average on a 0-100 scale.
+func ScoreFit(costumeMeasurement domain.CostumeMeasurement, buyerMeasurement domain.UserMeasurement) costume.CostumeFitResponse {
+	dimensions := []costume.CostumeFitDimensionResponse{}
+
+	circumferences := []struct {
+		name    string
+		garment *float64
+		body    *float64
+	}{
+		{"chest", costumeMeasurement.Chest, buyerMeasurement.Chest},
+		{"waist", costumeMeasurement.Waist, buyerMeasurement.Waist},
+		{"hip", costumeMeasurement.Hip, buyerMeasurement.Hip},
+	}
+	for _, circumference := range circumferences {
+		if circumference.garment == nil || circumference.body == nil {
+			continue
+		}
+		score, status := scoreCircumference(*circumference.garment - *circumference.body)
+		dimensions = append(dimensions, fitDimension(circumference.name, formatMeasurement(*circumference.garment), *circumference.body, status, score))
+	}
+
+	if buyerMeasurement.Height != nil && (costumeMeasurement.Height_min != nil || costumeMeasurement.Height_max != nil) {
+		score, status := scoreHeight(*buyerMeasurement.Height, costumeMeasurement.Height_min, costumeMeasurement.Height_max)
+		dimensions = append(dimensions, fitDimension("height", formatRange(costumeMeasurement.Height_min, costumeMeasurement.Height_max), *buyerMeasurement.Height, status, score))
+	}
+
+	if costumeMeasurement.Shoe_size != nil && buyerMeasurement.Shoe_size != nil {
+		score, status := scoreShoeSize(*costumeMeasurement.Shoe_size - *buyerMeasurement.Shoe_size)
+		dimensions = append(dimensions, fitDimension("shoe_size", formatMeasurement(*costumeMeasurement.Shoe_size), *buyerMeasurement.Shoe_size, status, score))
+	}
+
+	if len(dimensions) == 0 {
+		return costume.CostumeFitResponse{
+			Verdict:    "unknown",
+			Dimensions: dimensions,
+		}
+	}
+
+	total := 0
+	fits := true
+	for _, dimension := range dimensions {
+		total += dimension.Score
+		if float64(dimension.Score) < fitDimensionThreshold*100 {
+			fits = false
+		}
+	}
+	score := int(math.Round(float64(total) / float64(len(dimensions))))
+
+	verdict := "poor fit"
+	if fits && score >= goodFitScore {
+		verdict = "good fit"
+	} else if score >= possibleFitScore {
+		verdict = "may fit"
+	}
+
+	return costume.CostumeFitResponse{
+		Score:      &score,
+		Fits:       fits,
+		Verdict:    verdict,
+		Dimensions: dimensions,
+	}
+}
+
+// scoreCircumference rates garment minus body in cm: up to 2 cm smaller still stretches on and up to 8 cm of ease
+// is normal, beyond that the score falls to 0 at 8 cm too small or 20 cm too large.
+func scoreCircumference(ease float64) (float64, string) {
+	switch {
+	case ease < -2:
+		return clampScore(1 - (-2-ease)/6), "tight"
+	case ease > 8:
+		return clampScore(1 - (ease-8)/12), "loose"
+	default:
+		return 1, "fit"
+	}
+}
+
+func scoreHeight(height float64, heightMin *float64, heightMax *float64) (float64, string) {
+	switch {
+	case heightMin != nil && height < *heightMin:
+		return clampScore(1 - (*heightMin-height)/10), "short"
+	case heightMax != nil && height > *heightMax:
+		return clampScore(1 - (height-*heightMax)/10), "tall"
+	default:
+		return 1, "fit"
+	}
+}
+
+func scoreShoeSize(difference float64) (float64, string) {
+	switch {
+	case difference < -0.5:
+		return clampScore(1 - (-0.5-difference)/2), "small"
+	case difference > 0.5:
+		return clampScore(1 - (difference-0.5)/2), "large"
+	default:
+		return 1, "fit"
+	}
+}
+
+func clampScore(score float64) float64 {
+	return math.Max(0, math.Min(1, score))
+}
+
+func fitDimension(name string, costumeValue string, buyerValue float64, status string, score float64) costume.CostumeFitDimensionResponse {
+	return costume.CostumeFitDimensionResponse{
+		Dimension:     name,
+		Costume_value: costumeValue,
+		Buyer_value:   buyerValue,
+		Status:        status,
+		Score:         int(math.Round(score * 100)),
+	}
+}
+
+func formatMeasurement(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func formatRange(minValue *float64, maxValue *float64) string {
+	switch {
+	case minValue != nil && maxValue != nil:
+		return formatMeasurement(*minValue) + "-" + formatMeasurement(*maxValue)
+	case minValue != nil:
+		return formatMeasurement(*minValue) + "+"
+	default:
+		return "up to " + formatMeasurement(*maxValue)
+	}
+}
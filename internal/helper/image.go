@@ -0,0 +1,325 @@
+package helper
+
+import (
+	"bytes"
+	"context"
+	"cosplayrent/internal/model/web"
+	"cosplayrent/internal/storage"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// ErrInvalidImage wraps every rejection caused by the uploaded bytes, anything else returned by SaveImage is a server fault.
+var ErrInvalidImage = errors.New("invalid image")
+
+const (
+	maxImagePixels    = 40_000_000
+	originalImageSize = 2048
+)
+
+type imageVariant struct {
+	name    string
+	maxSize int
+}
+
+var imageVariants = []imageVariant{
+	{name: "thumbnail", maxSize: 200},
+	{name: "medium", maxSize: 600},
+	{name: "large", maxSize: 1200},
+}
+
+func invalidImage(message string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidImage, message)
+}
+
+// DecodeImage sniffs the magic bytes instead of trusting the client's Content-Type, decodes the image
+// and applies the EXIF orientation so the pixels are upright once the metadata is dropped.
+func DecodeImage(data []byte) (image.Image, error) {
+	switch http.DetectContentType(data) {
+	case "image/jpeg", "image/png", "image/webp":
+	default:
+		return nil, invalidImage("file is not image")
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, invalidImage("file is not a valid image")
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return nil, invalidImage("image dimensions are too large")
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, invalidImage("file is not a valid image")
+	}
+
+	return applyOrientation(img, jpegOrientation(data)), nil
+}
+
+// EncodeImage writes a fresh file without any metadata, as PNG when the image has transparency and JPEG otherwise.
+func EncodeImage(img image.Image) ([]byte, string, error) {
+	var buffer bytes.Buffer
+
+	if opaque, ok := img.(interface{ Opaque() bool }); ok && !opaque.Opaque() {
+		err := png.Encode(&buffer, img)
+		return buffer.Bytes(), ".png", err
+	}
+
+	err := jpeg.Encode(&buffer, img, &jpeg.Options{Quality: 85})
+	return buffer.Bytes(), ".jpg", err
+}
+
+// SanitizeImage re-encodes an upload that is stored without variants, such as identity cards.
+func SanitizeImage(file io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := DecodeImage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	sanitized, _, err := EncodeImage(resizeImage(img, originalImageSize))
+	return sanitized, err
+}
+
+// SaveImage stores the re-encoded upload as <prefix>/<uuid>_original plus one object per variant,
+// and returns the original's storage key, e.g. costume/0b0c..._original.jpg. A failed write removes the objects
+// already written for the upload.
+func SaveImage(ctx context.Context, store storage.Storage, file io.Reader, prefix string) (string, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", err
+	}
+
+	img, err := DecodeImage(data)
+	if err != nil {
+		return "", err
+	}
+
+	// random keys keep uploads apart across instances and within one request, a timestamp does neither
+	baseKey := prefix + "/" + uuid.New().String()
+
+	original, ext, err := EncodeImage(resizeImage(img, originalImageSize))
+	if err != nil {
+		return "", err
+	}
+
+	contentType := "image/jpeg"
+	if ext == ".png" {
+		contentType = "image/png"
+	}
+
+	written := []string{}
+	for _, variant := range imageVariants {
+		encoded, _, err := EncodeImage(resizeImage(img, variant.maxSize))
+		if err != nil {
+			removeKeys(ctx, store, written)
+			return "", err
+		}
+
+		variantKey := baseKey + "_" + variant.name + ext
+		err = store.Put(ctx, variantKey, encoded, contentType)
+		if err != nil {
+			removeKeys(ctx, store, written)
+			return "", err
+		}
+		written = append(written, variantKey)
+	}
+
+	// the original goes last so a key is never handed out before all of its variants exist
+	originalKey := baseKey + "_original" + ext
+	err = store.Put(ctx, originalKey, original, contentType)
+	if err != nil {
+		removeKeys(ctx, store, written)
+		return "", err
+	}
+
+	return originalKey, nil
+}
+
+// removeKeys is best effort, the caller already has the error that matters.
+func removeKeys(ctx context.Context, store storage.Storage, keys []string) {
+	for _, key := range keys {
+		store.Delete(ctx, key)
+	}
+}
+
+// ImageVariantPath maps a stored original to one of its variants, images uploaded before variants existed map to themselves.
+func ImageVariantPath(key string, variant string) string {
+	ext := path.Ext(key)
+	if !strings.HasSuffix(key, "_original"+ext) {
+		return key
+	}
+	return strings.TrimSuffix(key, "_original"+ext) + "_" + variant + ext
+}
+
+func ImageVariantURLs(store storage.Storage, key *string) *web.ImageVariantsResponse {
+	if key == nil {
+		return nil
+	}
+	return &web.ImageVariantsResponse{
+		Thumbnail: store.URL(ImageVariantPath(*key, "thumbnail")),
+		Medium:    store.URL(ImageVariantPath(*key, "medium")),
+		Large:     store.URL(ImageVariantPath(*key, "large")),
+	}
+}
+
+// RemoveImage deletes a stored original together with its variants, objects that are already gone are ignored.
+func RemoveImage(ctx context.Context, store storage.Storage, key string) error {
+	keys := []string{key}
+	for _, variant := range imageVariants {
+		if variantKey := ImageVariantPath(key, variant.name); variantKey != key {
+			keys = append(keys, variantKey)
+		}
+	}
+
+	for _, k := range keys {
+		err := store.Delete(ctx, k)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func resizeImage(img image.Image, maxSize int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxSize && height <= maxSize {
+		return img
+	}
+
+	if width >= height {
+		height = height * maxSize / width
+		width = maxSize
+	} else {
+		width = width * maxSize / height
+		height = maxSize
+	}
+
+	resized := image.NewNRGBA(image.Rect(0, 0, max(width, 1), max(height, 1)))
+	draw.CatmullRom.Scale(resized, resized.Bounds(), img, bounds, draw.Src, nil)
+	return resized
+}
+
+// jpegOrientation reads the EXIF orientation tag (0x0112) from a JPEG's APP1 segment, 1 means upright.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	offset := 2
+	for offset+4 <= len(data) {
+		if data[offset] != 0xFF {
+			return 1
+		}
+		marker := data[offset+1]
+		segmentLength := int(binary.BigEndian.Uint16(data[offset+2:]))
+		if marker == 0xDA || segmentLength < 2 || offset+2+segmentLength > len(data) {
+			return 1
+		}
+
+		segment := data[offset+4 : offset+2+segmentLength]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+
+		offset += 2 + segmentLength
+	}
+
+	return 1
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifdOffset := int(order.Uint32(tiff[4:]))
+	if ifdOffset+2 > len(tiff) {
+		return 1
+	}
+
+	entries := int(order.Uint16(tiff[ifdOffset:]))
+	for i := 0; i < entries; i++ {
+		entry := ifdOffset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+
+	return 1
+}
+
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation == 1 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	outWidth, outHeight := width, height
+	if orientation >= 5 {
+		outWidth, outHeight = height, width
+	}
+
+	oriented := image.NewNRGBA(image.Rect(0, 0, outWidth, outHeight))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = width-1-x, y
+			case 3:
+				dx, dy = width-1-x, height-1-y
+			case 4:
+				dx, dy = x, height-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = height-1-y, x
+			case 7:
+				dx, dy = height-1-y, width-1-x
+			case 8:
+				dx, dy = y, width-1-x
+			}
+			oriented.Set(dx, dy, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+
+	return oriented
+}
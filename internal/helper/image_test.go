@@ -0,0 +1,99 @@
+package helper
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memoryStore keeps objects in a map and fails the put at failAt, counting from 1.
+type memoryStore struct {
+	objects map[string][]byte
+	puts    int
+	failAt  int
+}
+
+func (store *memoryStore) Put(ctx context.Context, key string, body []byte, contentType string) error {
+	store.puts++
+	if store.puts == store.failAt {
+		return errors.New("put failed")
+	}
+	store.objects[key] = body
+	return nil
+}
+
+func (store *memoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	return store.objects[key], nil
+}
+
+func (store *memoryStore) Delete(ctx context.Context, key string) error {
+	delete(store.objects, key)
+	return nil
+}
+
+func (store *memoryStore) URL(key string) string {
+	return "/" + key
+}
+
+func (store *memoryStore) SignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return "/" + key, nil
+}
+
+func testJPEG(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 300, 200))
+	for x := 0; x < 300; x++ {
+		for y := 0; y < 200; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 90, A: 255})
+		}
+	}
+	var buffer bytes.Buffer
+	if err := jpeg.Encode(&buffer, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+func TestSaveImageKeysDoNotCollide(t *testing.T) {
+	store := &memoryStore{objects: map[string][]byte{}}
+	data := testJPEG(t)
+
+	first, err := SaveImage(context.Background(), store, bytes.NewReader(data), "costume")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := SaveImage(context.Background(), store, bytes.NewReader(data), "costume")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first == second {
+		t.Fatalf("two uploads were both stored as %s", first)
+	}
+	if !strings.HasPrefix(first, "costume/") || !strings.HasSuffix(first, "_original.jpg") {
+		t.Errorf("key = %s, want costume/<name>_original.jpg", first)
+	}
+	if len(store.objects) != 2*(len(imageVariants)+1) {
+		t.Errorf("stored %d objects, want %d", len(store.objects), 2*(len(imageVariants)+1))
+	}
+}
+
+func TestSaveImageRemovesWrittenVariantsOnFailure(t *testing.T) {
+	data := testJPEG(t)
+
+	for failAt := 1; failAt <= len(imageVariants)+1; failAt++ {
+		store := &memoryStore{objects: map[string][]byte{}, failAt: failAt}
+		if _, err := SaveImage(context.Background(), store, bytes.NewReader(data), "costume"); err == nil {
+			t.Fatalf("put %d failed but SaveImage succeeded", failAt)
+		}
+		if len(store.objects) != 0 {
+			t.Errorf("put %d failed and %d objects were left behind", failAt, len(store.objects))
+		}
+	}
+}
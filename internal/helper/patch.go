@@ -0,0 +1,85 @@
+package helper
+
+import (
+	"cosplayrent/internal/model/web"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator"
+)
+
+func IsJSONRequest(request *http.Request) bool {
+	return strings.HasPrefix(request.Header.Get("Content-Type"), "application/json")
+}
+
+// FormString reads a multipart field for a PATCH request, a field sent with an empty value is treated as null.
+func FormString(request *http.Request, key string) web.Optional[string] {
+	values, ok := request.PostForm[key]
+	if !ok || len(values) == 0 {
+		return web.Optional[string]{}
+	}
+	if values[0] == "" {
+		return web.Null[string]()
+	}
+	return web.Some(values[0])
+}
+
+func FormInt(request *http.Request, key string) (web.Optional[int], error) {
+	value := FormString(request, key)
+	if !value.Set || value.Null {
+		return web.Optional[int]{Set: value.Set, Null: value.Null}, nil
+	}
+
+	intValue, err := strconv.Atoi(value.Value)
+	if err != nil {
+		return web.Optional[int]{}, errors.New(key + " must be a number")
+	}
+	return web.Some(intValue), nil
+}
+
+func FormFloat(request *http.Request, key string) (web.Optional[float64], error) {
+	value := FormString(request, key)
+	if !value.Set || value.Null {
+		return web.Optional[float64]{Set: value.Set, Null: value.Null}, nil
+	}
+
+	floatValue, err := strconv.ParseFloat(value.Value, 64)
+	if err != nil {
+		return web.Optional[float64]{}, errors.New(key + " must be a number")
+	}
+	return web.Some(floatValue), nil
+}
+
+// FormJSON decodes a multipart field that carries a JSON document, such as a costume's attributes.
+func FormJSON[T any](request *http.Request, key string) (web.Optional[T], error) {
+	value := FormString(request, key)
+	if !value.Set || value.Null {
+		return web.Optional[T]{Set: value.Set, Null: value.Null}, nil
+	}
+
+	var decoded T
+	err := json.Unmarshal([]byte(value.Value), &decoded)
+	if err != nil {
+		return web.Optional[T]{}, errors.New(key + " must be valid JSON")
+	}
+	return web.Some(decoded), nil
+}
+
+func ValidatePatchField[T any](validate *validator.Validate, name string, field web.Optional[T], nullable bool, tag string) error {
+	if !field.Set {
+		return nil
+	}
+	if field.Null {
+		if nullable {
+			return nil
+		}
+		return errors.New(name + " cannot be null")
+	}
+	if tag != "" && validate.Var(field.Value, tag) != nil {
+		return errors.New("invalid " + name)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package helper
+
+import (
+	"cosplayrent/internal/model/domain"
+	"cosplayrent/internal/model/web/costume"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	RentalDateLayout = "2006-01-02"
+	// longest rental a quote is given for, keeps the per-day breakdown small
+	maxQuoteDays = 90
+	// how far ahead a rental may start
+	maxBookingLeadDays = 365
+)
+
+// LastRentalDay is the latest day a rental booked today can run until.
+func LastRentalDay(today time.Time) time.Time {
+	return RentalDay(today).AddDate(0, 0, maxBookingLeadDays+maxQuoteDays-1)
+}
+
+// RentalDay is the calendar date of t in t's zone, as midnight UTC like the dates ParseRentalDates returns.
+func RentalDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// ParseRentalDates reads an inclusive rental range, both dates formatted as 2006-01-02.
+func ParseRentalDates(startDate string, endDate string) (time.Time, time.Time, error) {
+	start, err := time.Parse(RentalDateLayout, startDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("start_date must be a date formatted as 2006-01-02")
+	}
+	end, err := time.Parse(RentalDateLayout, endDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("end_date must be a date formatted as 2006-01-02")
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, errors.New("end_date must not be before start_date")
+	}
+	return start, end, nil
+}
+
+// QuoteRental prices every day from start to end with the daily rate, a date rule covering the day takes
+// precedence over the weekend surcharge. The best discount the rental length qualifies for is then taken off
+// the subtotal. Amounts are rounded to whole rupiah.
+func QuoteRental(rate float64, pricing domain.CostumePricing, start time.Time, end time.Time, today time.Time) (costume.CostumePriceQuoteResponse, error) {
+	if start.Before(RentalDay(today)) {
+		return costume.CostumePriceQuoteResponse{}, errors.New("start_date must not be in the past")
+	}
+	if start.After(RentalDay(today).AddDate(0, 0, maxBookingLeadDays)) {
+		return costume.CostumePriceQuoteResponse{}, fmt.Errorf("a rental can start at most %d days ahead", maxBookingLeadDays)
+	}
+
+	days := int(end.Sub(start).Hours()/24) + 1
+	if days > maxQuoteDays {
+		return costume.CostumePriceQuoteResponse{}, fmt.Errorf("a rental can last at most %d days", maxQuoteDays)
+	}
+	if days < pricing.Min_days {
+		return costume.CostumePriceQuoteResponse{}, fmt.Errorf("this costume is rented for at least %d days", pricing.Min_days)
+	}
+	if pricing.Max_days != nil && days > *pricing.Max_days {
+		return costume.CostumePriceQuoteResponse{}, fmt.Errorf("this costume is rented for at most %d days", *pricing.Max_days)
+	}
+
+	breakdown := make([]costume.CostumePriceQuoteDayResponse, 0, days)
+	subtotal := 0.0
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		price, rule := dayPrice(rate, pricing, day)
+		breakdown = append(breakdown, costume.CostumePriceQuoteDayResponse{
+			Date:  day.Format(RentalDateLayout),
+			Rule:  rule,
+			Price: price,
+		})
+		subtotal += price
+	}
+
+	discountPercent := 0.0
+	for _, discount := range pricing.Discounts {
+		if days >= discount.Min_days && discount.Percent > discountPercent {
+			discountPercent = discount.Percent
+		}
+	}
+	discount := math.Round(subtotal * discountPercent / 100)
+
+	return costume.CostumePriceQuoteResponse{
+		Start_date:       start.Format(RentalDateLayout),
+		End_date:         end.Format(RentalDateLayout),
+		Days:             days,
+		Daily_rate:       rate,
+		Breakdown:        breakdown,
+		Subtotal:         subtotal,
+		Discount_percent: discountPercent,
+		Discount:         discount,
+		Total:            subtotal - discount,
+	}, nil
+}
+
+func dayPrice(rate float64, pricing domain.CostumePricing, day time.Time) (float64, string) {
+	date := day.Format(RentalDateLayout)
+	for _, rule := range pricing.Date_rules {
+		// dates share one layout so they compare as strings
+		if date < rule.Start_date || date > rule.End_date {
+			continue
+		}
+		if rule.Type == "fixed" {
+			return math.Round(rule.Value), rule.Label
+		}
+		return math.Round(rate * (1 + rule.Value/100)), rule.Label
+	}
+
+	if pricing.Weekend_surcharge > 0 && (day.Weekday() == time.Saturday || day.Weekday() == time.Sunday) {
+		return math.Round(rate * (1 + pricing.Weekend_surcharge/100)), "weekend"
+	}
+	return math.Round(rate), "daily rate"
+}
@@ -0,0 +1,167 @@
+package helper
+
+import (
+	"cosplayrent/internal/model/domain"
+	"strings"
+	"testing"
+	"time"
+)
+
+func rentalDate(t *testing.T, date string) time.Time {
+	t.Helper()
+	day, err := time.Parse(RentalDateLayout, date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return day
+}
+
+func TestQuoteRental(t *testing.T) {
+	// a Monday
+	today := time.Date(2026, 10, 19, 15, 30, 0, 0, time.UTC)
+	maxDays := 5
+
+	tests := []struct {
+		name          string
+		pricing       domain.CostumePricing
+		start, end    string
+		wantPrices    []float64
+		wantRules     []string
+		wantSubtotal  float64
+		wantDiscount  float64
+		wantTotal     float64
+		wantErrPrefix string
+	}{
+		{
+			name:         "weekdays at the daily rate",
+			start:        "2026-10-20",
+			end:          "2026-10-22",
+			wantPrices:   []float64{100000, 100000, 100000},
+			wantRules:    []string{"daily rate", "daily rate", "daily rate"},
+			wantSubtotal: 300000,
+			wantTotal:    300000,
+		},
+		{
+			name:         "starting today",
+			start:        "2026-10-19",
+			end:          "2026-10-19",
+			wantPrices:   []float64{100000},
+			wantRules:    []string{"daily rate"},
+			wantSubtotal: 100000,
+			wantTotal:    100000,
+		},
+		{
+			name:         "weekend surcharge",
+			pricing:      domain.CostumePricing{Weekend_surcharge: 25},
+			start:        "2026-10-23",
+			end:          "2026-10-25",
+			wantPrices:   []float64{100000, 125000, 125000},
+			wantRules:    []string{"daily rate", "weekend", "weekend"},
+			wantSubtotal: 350000,
+			wantTotal:    350000,
+		},
+		{
+			name: "date rules take precedence over the weekend surcharge",
+			pricing: domain.CostumePricing{
+				Weekend_surcharge: 25,
+				Date_rules: []domain.PricingDateRule{
+					{Label: "convention", Start_date: "2026-10-24", End_date: "2026-10-24", Type: "fixed", Value: 150000},
+					{Label: "halloween", Start_date: "2026-10-25", End_date: "2026-10-31", Type: "surcharge", Value: 10},
+				},
+			},
+			start:        "2026-10-23",
+			end:          "2026-10-26",
+			wantPrices:   []float64{100000, 150000, 110000, 110000},
+			wantRules:    []string{"daily rate", "convention", "halloween", "halloween"},
+			wantSubtotal: 470000,
+			wantTotal:    470000,
+		},
+		{
+			name: "best qualifying discount",
+			pricing: domain.CostumePricing{
+				Discounts: []domain.PricingDiscount{{Min_days: 3, Percent: 10}, {Min_days: 5, Percent: 15}, {Min_days: 7, Percent: 30}},
+			},
+			start:        "2026-10-20",
+			end:          "2026-10-24",
+			wantSubtotal: 500000,
+			wantDiscount: 75000,
+			wantTotal:    425000,
+		},
+		{
+			name:          "start in the past",
+			start:         "2026-10-18",
+			end:           "2026-10-20",
+			wantErrPrefix: "start_date must not be in the past",
+		},
+		{
+			name:          "start too far ahead",
+			start:         "2027-10-20",
+			end:           "2027-10-21",
+			wantErrPrefix: "a rental can start at most 365 days ahead",
+		},
+		{
+			name:          "longer than a quote covers",
+			start:         "2026-10-20",
+			end:           "2027-01-18",
+			wantErrPrefix: "a rental can last at most 90 days",
+		},
+		{
+			name:          "shorter than the minimum",
+			pricing:       domain.CostumePricing{Min_days: 3},
+			start:         "2026-10-20",
+			end:           "2026-10-21",
+			wantErrPrefix: "this costume is rented for at least 3 days",
+		},
+		{
+			name:          "longer than the maximum",
+			pricing:       domain.CostumePricing{Max_days: &maxDays},
+			start:         "2026-10-20",
+			end:           "2026-10-25",
+			wantErrPrefix: "this costume is rented for at most 5 days",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, err := QuoteRental(100000, tt.pricing, rentalDate(t, tt.start), rentalDate(t, tt.end), today)
+			if tt.wantErrPrefix != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErrPrefix) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErrPrefix)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if quote.Days != len(quote.Breakdown) {
+				t.Errorf("days = %d but the breakdown has %d entries", quote.Days, len(quote.Breakdown))
+			}
+			for i, day := range quote.Breakdown {
+				if tt.wantPrices != nil && day.Price != tt.wantPrices[i] {
+					t.Errorf("day %s price = %v, want %v", day.Date, day.Price, tt.wantPrices[i])
+				}
+				if tt.wantRules != nil && day.Rule != tt.wantRules[i] {
+					t.Errorf("day %s rule = %q, want %q", day.Date, day.Rule, tt.wantRules[i])
+				}
+			}
+			if quote.Subtotal != tt.wantSubtotal || quote.Discount != tt.wantDiscount || quote.Total != tt.wantTotal {
+				t.Errorf("subtotal, discount, total = %v, %v, %v, want %v, %v, %v", quote.Subtotal, quote.Discount, quote.Total, tt.wantSubtotal, tt.wantDiscount, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestParseRentalDates(t *testing.T) {
+	if _, _, err := ParseRentalDates("2026-10-21", "2026-10-20"); err == nil {
+		t.Error("an end before the start was accepted")
+	}
+	if _, _, err := ParseRentalDates("21-10-2026", "2026-10-22"); err == nil {
+		t.Error("a start in the wrong layout was accepted")
+	}
+
+	start, end, err := ParseRentalDates("2026-10-21", "2026-10-21")
+	if err != nil || !start.Equal(end) {
+		t.Errorf("one day rental = %v %v %v", start, end, err)
+	}
+}
@@ -0,0 +1,82 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// remoteImageClient only dials public addresses, so image urls from an import cannot reach services inside our network.
+var remoteImageClient = &http.Client{
+	Timeout: 15 * time.Second,
+	Transport: &http.Transport{
+		Proxy: nil,
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: dialPublicAddress,
+		}).DialContext,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: 10 * time.Second,
+	},
+	CheckRedirect: func(request *http.Request, via []*http.Request) error {
+		if len(via) >= 3 {
+			return errors.New("too many redirects")
+		}
+		if request.URL.Scheme != "http" && request.URL.Scheme != "https" {
+			return errors.New("redirect to an unsupported scheme")
+		}
+		return nil
+	},
+}
+
+func dialPublicAddress(network string, address string, conn syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+		return fmt.Errorf("address %s is not public", host)
+	}
+	return nil
+}
+
+// FetchImage downloads an image url for a bulk import, reading at most maxBytes.
+func FetchImage(ctx context.Context, rawURL string, maxBytes int64) ([]byte, error) {
+	imageURL, err := url.Parse(rawURL)
+	if err != nil || (imageURL.Scheme != "http" && imageURL.Scheme != "https") || imageURL.Host == "" {
+		return nil, errors.New("image url must be an http or https url")
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL.String(), nil)
+	if err != nil {
+		return nil, errors.New("image url must be an http or https url")
+	}
+
+	response, err := remoteImageClient.Do(request)
+	if err != nil {
+		return nil, errors.New("could not download image")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not download image, got status %d", response.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(response.Body, maxBytes+1))
+	if err != nil {
+		return nil, errors.New("could not download image")
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("image exceeds %d mb", maxBytes/1024/1024)
+	}
+
+	return data, nil
+}
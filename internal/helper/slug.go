@@ -0,0 +1,17 @@
+package helper
+
+import (
+	"regexp"
+	"strings"
+)
+
+var slugSeparator = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slugify lowercases the text and joins its ascii letters and digits with dashes, e.g. "Genshin Impact!" becomes genshin-impact.
+func Slugify(text string) string {
+	return strings.Trim(slugSeparator.ReplaceAllString(strings.ToLower(text), "-"), "-")
+}
+
+func IsSlug(text string) bool {
+	return text != "" && Slugify(text) == text
+}
@@ -0,0 +1,81 @@
+package helper
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the RFC 6238 SHA1 test key "12345678901234567890" in base32.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeMatchesRFC6238(t *testing.T) {
+	// the RFC lists 8 digit codes, the last 6 digits are the 6 digit code for the same step
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := totpCode(rfc6238Secret, tt.unix/totpPeriod)
+		if err != nil {
+			t.Fatalf("totpCode: %v", err)
+		}
+		if code != tt.code {
+			t.Errorf("code at %d = %s, want %s", tt.unix, code, tt.code)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	issued := time.Unix(1234567890, 0)
+	step := issued.Unix() / totpPeriod
+
+	for _, offset := range []time.Duration{-totpPeriod * time.Second, 0, totpPeriod * time.Second} {
+		matched, ok := ValidateTOTP(rfc6238Secret, "005924", issued.Add(offset))
+		if !ok || matched != step {
+			t.Errorf("code checked %v from its step = %d %v, want %d true", offset, matched, ok, step)
+		}
+	}
+
+	if _, ok := ValidateTOTP(rfc6238Secret, "005924", issued.Add(2*totpPeriod*time.Second)); ok {
+		t.Error("a code two steps old was accepted")
+	}
+	if _, ok := ValidateTOTP(rfc6238Secret, "005925", issued); ok {
+		t.Error("a wrong code was accepted")
+	}
+	if _, ok := ValidateTOTP(strings.ToLower(rfc6238Secret), "005924", issued); !ok {
+		t.Error("a lowercase secret was rejected")
+	}
+	if _, ok := ValidateTOTP("not base32!", "005924", issued); ok {
+		t.Error("an undecodable secret was accepted")
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret: %v", err)
+	}
+
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil || len(key) != 20 {
+		t.Fatalf("secret %q decodes to %d bytes, %v", secret, len(key), err)
+	}
+
+	other, _ := GenerateTOTPSecret()
+	if other == secret {
+		t.Error("two secrets are the same")
+	}
+
+	uri := TOTPProvisioningURI("CosplayRent", "buyer@example.com", secret)
+	if !strings.HasPrefix(uri, "otpauth://totp/CosplayRent:buyer@example.com?") || !strings.Contains(uri, "secret="+secret) {
+		t.Errorf("provisioning uri = %s", uri)
+	}
+}
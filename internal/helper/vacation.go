@@ -0,0 +1,21 @@
+package helper
+
+import (
+	"cosplayrent/internal/model/domain"
+	"time"
+)
+
+// VacationWindow returns the seller's vacation dates when one is running or coming up, a vacation whose end date has
+// passed is over without anyone clearing it.
+func VacationWindow(seller domain.User, today time.Time) (time.Time, time.Time, bool) {
+	if seller.Vacation_start == nil || seller.Vacation_end == nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	start, end := RentalDay(*seller.Vacation_start), RentalDay(*seller.Vacation_end)
+	if end.Before(RentalDay(today)) {
+		return time.Time{}, time.Time{}, false
+	}
+
+	return start, end, true
+}
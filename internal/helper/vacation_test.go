@@ -0,0 +1,48 @@
+package helper
+
+import (
+	"cosplayrent/internal/model/domain"
+	"testing"
+	"time"
+)
+
+func TestVacationWindow(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	today := time.Date(2026, 10, 19, 9, 0, 0, 0, jakarta)
+	at := func(month time.Month, day int) *time.Time {
+		date := time.Date(2026, month, day, 22, 0, 0, 0, jakarta)
+		return &date
+	}
+
+	tests := []struct {
+		name      string
+		seller    domain.User
+		wantOk    bool
+		wantStart string
+		wantEnd   string
+	}{
+		{name: "no vacation", seller: domain.User{}},
+		{name: "only a start", seller: domain.User{Vacation_start: at(10, 20)}},
+		{name: "ended yesterday", seller: domain.User{Vacation_start: at(10, 10), Vacation_end: at(10, 18)}},
+		{name: "ends today", seller: domain.User{Vacation_start: at(10, 10), Vacation_end: at(10, 19)}, wantOk: true, wantStart: "2026-10-10", wantEnd: "2026-10-19"},
+		{name: "coming up", seller: domain.User{Vacation_start: at(11, 1), Vacation_end: at(11, 7)}, wantOk: true, wantStart: "2026-11-01", wantEnd: "2026-11-07"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, ok := VacationWindow(tt.seller, today)
+			if ok != tt.wantOk {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOk)
+			}
+			if !ok {
+				return
+			}
+			if start.Format(RentalDateLayout) != tt.wantStart || end.Format(RentalDateLayout) != tt.wantEnd {
+				t.Errorf("window = %s to %s, want %s to %s", start.Format(RentalDateLayout), end.Format(RentalDateLayout), tt.wantStart, tt.wantEnd)
+			}
+			if start.Location() != time.UTC || start.Hour() != 0 {
+				t.Errorf("start %v is not a rental day", start)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package helper
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// VisitorId fingerprints an anonymous client from its address and user agent, the first X-Forwarded-For entry wins
+// since the api runs behind nginx. It only throttles repeated views, so a spoofed header costs nothing.
+func VisitorId(request *http.Request) string {
+	address := strings.TrimSpace(strings.Split(request.Header.Get("X-Forwarded-For"), ",")[0])
+	if address == "" {
+		address = request.RemoteAddr
+		if host, _, err := net.SplitHostPort(address); err == nil {
+			address = host
+		}
+	}
+
+	sum := sha1.Sum([]byte(address + "|" + request.UserAgent()))
+	return hex.EncodeToString(sum[:])
+}
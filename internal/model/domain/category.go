@@ -1 +1,12 @@
 package domain
+
+import "time"
+
+type Category struct {
+	Id         int
+	Parent_id  *int
+	Name       string
+	Slug       string
+	Created_at *time.Time
+	Updated_at *time.Time
+}
@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+type CategoryAttribute struct {
+	Id          int
+	Category_id int
+	Key         string
+	Label       string
+	Type        string
+	Options     []string
+	Required    bool
+	Created_at  *time.Time
+	Updated_at  *time.Time
+}
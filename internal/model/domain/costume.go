@@ -13,7 +13,7 @@ type Costume struct {
 	Kategori    int
 	Price       float64
 	Picture     string
-	Available   string
+	Status      string
 	Created_at  *time.Time
 	Updated_at  *time.Time
 }
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// CostumeActivity is a feed entry, Type is new_listing or restock.
+type CostumeActivity struct {
+	Id         int
+	Costume_id int
+	Seller_id  string
+	Type       string
+	Created_at *time.Time
+}
@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// CostumeBlackout is a date range, both days included, in which the seller does not rent the costume out.
+type CostumeBlackout struct {
+	Id         int
+	Costume_id int
+	Start_date time.Time
+	End_date   time.Time
+	Reason     *string
+	Created_at *time.Time
+}
@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+type CostumeDailyStat struct {
+	Costume_id       int
+	Day              *time.Time
+	Views            int
+	Wishlist_adds    int
+	Wishlist_removes int
+	Checkouts        int
+	Completed_orders int
+	Revenue          float64
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// CostumeEvent is one thing that happened to a listing, Type is view, wishlist, wishlist_remove, checkout or rental.
+// Amount is the order total of checkout and rental events, Visitor_id identifies the client behind an anonymous view.
+type CostumeEvent struct {
+	Id         int
+	Costume_id int
+	Type       string
+	User_id    *string
+	Visitor_id *string
+	Order_id   *string
+	Amount     *float64
+	Created_at *time.Time
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+type CostumeImage struct {
+	Id         int
+	Costume_id int
+	Path       string
+	Position   int
+	Is_cover   bool
+	Created_at *time.Time
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// CostumePricing holds the rental rules of a listing, the listing or variant price is the rate for one day.
+type CostumePricing struct {
+	Costume_id        int
+	Min_days          int
+	Max_days          *int
+	Weekend_surcharge float64
+	Discounts         []PricingDiscount
+	Date_rules        []PricingDateRule
+	Updated_at        *time.Time
+}
+
+// PricingDiscount takes Percent off the whole rental once it lasts at least Min_days, stored as jsonb.
+type PricingDiscount struct {
+	Min_days int     `json:"min_days"`
+	Percent  float64 `json:"percent"`
+}
+
+// PricingDateRule prices the days from Start_date to End_date, both inclusive and formatted 2006-01-02. A
+// surcharge rule adds Value percent to the daily rate, a fixed rule replaces the daily rate with Value.
+type PricingDateRule struct {
+	Label      string  `json:"label"`
+	Start_date string  `json:"start_date"`
+	End_date   string  `json:"end_date"`
+	Type       string  `json:"type"`
+	Value      float64 `json:"value"`
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// CostumeVariant is one size of a listing, Reserved counts the orders that are still running and Booked_today the
+// units taken today once rental dates and turnaround are accounted for.
+type CostumeVariant struct {
+	Id           int
+	Costume_id   int
+	Size         string
+	Quantity     int
+	Reserved     int
+	Booked_today int
+	Price        *float64
+	Created_at   *time.Time
+	Updated_at   *time.Time
+}
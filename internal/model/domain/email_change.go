@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+type EmailChange struct {
+	Id                int
+	User_id           string
+	New_email         string
+	Verification_code string
+	Attempts          int
+	Created_at        *time.Time
+	Expired_at        *time.Time
+}
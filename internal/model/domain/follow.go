@@ -0,0 +1,9 @@
+package domain
+
+import "time"
+
+type Follow struct {
+	Follower_id string
+	Seller_id   string
+	Created_at  *time.Time
+}
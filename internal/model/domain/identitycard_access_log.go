@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+type IdentityCardAccessLog struct {
+	Id          int
+	Owner_id    string
+	Accessor_id string
+	Access_role string
+	Granted     bool
+	Created_at  *time.Time
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// CostumeMeasurement holds the garment's own measurements in cm, shoe size is EU.
+type CostumeMeasurement struct {
+	Costume_id int
+	Chest      *float64
+	Waist      *float64
+	Hip        *float64
+	Height_min *float64
+	Height_max *float64
+	Shoe_size  *float64
+	Updated_at *time.Time
+}
+
+// UserMeasurement holds the buyer's body measurements in cm, shoe size is EU.
+type UserMeasurement struct {
+	User_id    string
+	Chest      *float64
+	Waist      *float64
+	Hip        *float64
+	Height     *float64
+	Shoe_size  *float64
+	Updated_at *time.Time
+}
@@ -14,4 +14,8 @@ type Notification struct {
 type EmailNotification struct {
 	Username string
 	Code     string
+	Reason   string
+	NewEmail string
+	Seller   string
+	Costume  string
 }
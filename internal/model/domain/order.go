@@ -9,6 +9,9 @@ type Order struct {
 	Seller_id            string
 	Costumer_id          string
 	Costume_id           int
+	Variant_id           int
+	Start_date           *time.Time
+	End_date             *time.Time
 	Total_amount         float64
 	Shipment_origin      string
 	Shipment_destination string
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// TwoFactorChallenge backs a login challenge token, Attempts counts the wrong codes tried with it.
+type TwoFactorChallenge struct {
+	Id         string
+	User_id    string
+	Attempts   int
+	Expires_at *time.Time
+	Created_at *time.Time
+}
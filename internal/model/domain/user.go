@@ -5,17 +5,30 @@ import (
 )
 
 type User struct {
-	Id                    string
-	Name                  string
-	Email                 string
-	Address               string
-	Password              string
-	Profile_picture       *string
-	Identity_card_picture string
-	Origin_province_name  string
-	Origin_province_id    int
-	Origin_city_name      string
-	Origin_city_id        int
-	Created_at            *time.Time
-	Updated_at            *time.Time
+	Id                     string
+	Name                   string
+	Email                  string
+	Address                string
+	Password               string
+	Profile_picture        *string
+	Identity_card_picture  string
+	Identity_card_status   string
+	Identity_card_reason   *string
+	Identity_card_reviewer string
+	Identity_card_reviewed *time.Time
+	Role                   string
+	Totp_secret            *string
+	Totp_enabled           bool
+	Totp_last_step         *int64
+	Totp_attempts          int
+	Totp_locked_until      *time.Time
+	Vacation_start         *time.Time
+	Vacation_end           *time.Time
+	Vacation_message       *string
+	Origin_province_name   string
+	Origin_province_id     int
+	Origin_city_name       string
+	Origin_city_id         int
+	Created_at             *time.Time
+	Updated_at             *time.Time
 }
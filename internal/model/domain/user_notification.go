@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+type UserNotification struct {
+	Id         int
+	User_id    string
+	Type       string
+	Title      string
+	Body       string
+	Costume_id *int
+	Read_at    *time.Time
+	Created_at *time.Time
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+type UserRecoveryCode struct {
+	Id         int
+	User_id    string
+	Code_hash  string
+	Used_at    *time.Time
+	Created_at *time.Time
+}
@@ -0,0 +1,7 @@
+package analytics
+
+type SellerAnalyticsRequest struct {
+	Seller_id  string
+	Start_date string
+	End_date   string
+}
@@ -0,0 +1,44 @@
+package analytics
+
+type SellerAnalyticsResponse struct {
+	Start_date   string                     `json:"start_date"`
+	End_date     string                     `json:"end_date"`
+	Funnel       AnalyticsFunnelResponse    `json:"funnel"`
+	Revenue      AnalyticsRevenueResponse   `json:"revenue"`
+	Top_listings []AnalyticsListingResponse `json:"top_listings"`
+}
+
+// AnalyticsFunnelResponse rates are percentages, nil when the step before had nothing to convert.
+type AnalyticsFunnelResponse struct {
+	Views            int      `json:"views"`
+	Wishlist_adds    int      `json:"wishlist_adds"`
+	Wishlist_removes int      `json:"wishlist_removes"`
+	Checkouts        int      `json:"checkouts"`
+	Completed_orders int      `json:"completed_orders"`
+	Checkout_rate    *float64 `json:"checkout_rate"`
+	Completion_rate  *float64 `json:"completion_rate"`
+	Conversion_rate  *float64 `json:"conversion_rate"`
+}
+
+type AnalyticsRevenueResponse struct {
+	Total float64                       `json:"total"`
+	Days  []AnalyticsRevenueDayResponse `json:"days"`
+}
+
+type AnalyticsRevenueDayResponse struct {
+	Date             string  `json:"date"`
+	Revenue          float64 `json:"revenue"`
+	Completed_orders int     `json:"completed_orders"`
+}
+
+type AnalyticsListingResponse struct {
+	Costume_id       int      `json:"costume_id"`
+	Name             string   `json:"name"`
+	Costume_picture  *string  `json:"costume_picture"`
+	Views            int      `json:"views"`
+	Wishlist_adds    int      `json:"wishlist_adds"`
+	Checkouts        int      `json:"checkouts"`
+	Completed_orders int      `json:"completed_orders"`
+	Revenue          float64  `json:"revenue"`
+	Conversion_rate  *float64 `json:"conversion_rate"`
+}
@@ -0,0 +1,21 @@
+package category
+
+import "cosplayrent/internal/model/web"
+
+type CategoryAttributeCreateRequest struct {
+	Category_id int      `json:"-"`
+	Key         string   `validate:"required,min=1,max=50" json:"key"`
+	Label       string   `validate:"required,min=1,max=100" json:"label"`
+	Type        string   `validate:"required,oneof=text number boolean enum multi_enum" json:"type"`
+	Options     []string `validate:"max=50,dive,required,max=50" json:"options"`
+	Required    bool     `json:"required"`
+}
+
+// CategoryAttributeUpdateRequest cannot change key or type, values already stored on costumes depend on both.
+type CategoryAttributeUpdateRequest struct {
+	Id          int                    `json:"-"`
+	Category_id int                    `json:"-"`
+	Label       web.Optional[string]   `json:"label"`
+	Options     web.Optional[[]string] `json:"options"`
+	Required    web.Optional[bool]     `json:"required"`
+}
@@ -0,0 +1,7 @@
+package category
+
+type CategoryCreateRequest struct {
+	Name      string `validate:"required,min=2,max=100" json:"name"`
+	Slug      string `validate:"omitempty,max=120" json:"slug"`
+	Parent_id *int   `validate:"omitempty,min=1" json:"parent_id"`
+}
@@ -1,6 +1,28 @@
 package category
 
 type CategoryResponse struct {
-	Id   int    `json:"id"`
-	Name string `json:"name"`
+	Id        int    `json:"id"`
+	Parent_id *int   `json:"parent_id"`
+	Name      string `json:"name"`
+	Slug      string `json:"slug"`
+}
+
+type CategoryTreeResponse struct {
+	Id                  int                    `json:"id"`
+	Parent_id           *int                   `json:"-"`
+	Name                string                 `json:"name"`
+	Slug                string                 `json:"slug"`
+	Costume_count       int                    `json:"costume_count"`
+	Total_costume_count int                    `json:"total_costume_count"`
+	Children            []CategoryTreeResponse `json:"children"`
+}
+
+type CategoryAttributeResponse struct {
+	Id          int      `json:"id"`
+	Category_id int      `json:"category_id"`
+	Key         string   `json:"key"`
+	Label       string   `json:"label"`
+	Type        string   `json:"type"`
+	Options     []string `json:"options"`
+	Required    bool     `json:"required"`
 }
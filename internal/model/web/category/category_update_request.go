@@ -0,0 +1,17 @@
+package category
+
+import "cosplayrent/internal/model/web"
+
+type CategoryUpdateRequest struct {
+	Id        int                  `json:"-"`
+	Name      web.Optional[string] `json:"name"`
+	Slug      web.Optional[string] `json:"slug"`
+	Parent_id web.Optional[int]    `json:"parent_id"`
+}
+
+// CategoryDeleteRequest moves the costumes and subcategories of the deleted category to Reassign_to,
+// without it a category that is still in use cannot be deleted.
+type CategoryDeleteRequest struct {
+	Id          int  `json:"-"`
+	Reassign_to *int `validate:"omitempty,min=1" json:"reassign_to"`
+}
@@ -0,0 +1,20 @@
+package costume
+
+type CostumeBlackoutRequest struct {
+	Costume_id int     `json:"-"`
+	Start_date string  `validate:"required" json:"start_date"`
+	End_date   string  `validate:"required" json:"end_date"`
+	Reason     *string `validate:"omitempty,max=100" json:"reason"`
+}
+
+type CostumeTurnaroundRequest struct {
+	Costume_id      int `json:"-"`
+	Turnaround_days int `validate:"min=0,max=30" json:"turnaround_days"`
+}
+
+// CostumeAvailabilityRequest selects the calendar window, it defaults to the next 30 days.
+type CostumeAvailabilityRequest struct {
+	Start_date string
+	End_date   string
+	Variant_id int
+}
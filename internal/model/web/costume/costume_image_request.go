@@ -0,0 +1,5 @@
+package costume
+
+type CostumeImageOrderRequest struct {
+	Image_ids []int `validate:"required,min=1,dive,min=1" json:"image_ids"`
+}
@@ -0,0 +1,11 @@
+package costume
+
+type CostumeMeasurementRequest struct {
+	Costume_id int      `json:"-"`
+	Chest      *float64 `validate:"omitempty,min=40,max=200" json:"chest"`
+	Waist      *float64 `validate:"omitempty,min=30,max=200" json:"waist"`
+	Hip        *float64 `validate:"omitempty,min=40,max=200" json:"hip"`
+	Height_min *float64 `validate:"omitempty,min=80,max=250" json:"height_min"`
+	Height_max *float64 `validate:"omitempty,min=80,max=250" json:"height_max"`
+	Shoe_size  *float64 `validate:"omitempty,min=20,max=55" json:"shoe_size"`
+}
@@ -0,0 +1,30 @@
+package costume
+
+type CostumePricingRequest struct {
+	Costume_id                int                             `json:"-"`
+	Min_days                  int                             `validate:"min=0,max=90" json:"min_days"`
+	Max_days                  *int                            `validate:"omitempty,min=1,max=90" json:"max_days"`
+	Weekend_surcharge_percent float64                         `validate:"min=0,max=300" json:"weekend_surcharge_percent"`
+	Discounts                 []CostumePricingDiscountRequest `validate:"max=5,dive" json:"discounts"`
+	Date_rules                []CostumePricingDateRuleRequest `validate:"max=50,dive" json:"date_rules"`
+}
+
+type CostumePricingDiscountRequest struct {
+	Min_days int     `validate:"required,min=2,max=90" json:"min_days"`
+	Percent  float64 `validate:"required,gt=0,max=90" json:"percent"`
+}
+
+type CostumePricingDateRuleRequest struct {
+	Label      string  `validate:"required,min=1,max=50" json:"label"`
+	Start_date string  `validate:"required" json:"start_date"`
+	End_date   string  `validate:"required" json:"end_date"`
+	Type       string  `validate:"required,oneof=surcharge fixed" json:"type"`
+	Value      float64 `validate:"required,gt=0" json:"value"`
+}
+
+// CostumeQuoteRequest asks GET /api/costume/:costumeID for a price breakdown, it is ignored without both dates.
+type CostumeQuoteRequest struct {
+	Start_date string
+	End_date   string
+	Variant_id int
+}
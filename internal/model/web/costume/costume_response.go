@@ -1,22 +1,43 @@
 package costume
 
+import (
+	"cosplayrent/internal/model/web"
+	"encoding/json"
+)
+
 type CostumeResponse struct {
-	Id              int     `json:"id"`
-	User_id         string  `json:"user_id"`
-	Username        string  `json:"username"`
-	Profile_picture *string `json:"profile_picture"`
-	Name            string  `json:"name"`
-	Description     string  `json:"description"`
-	Bahan           string  `json:"bahan"`
-	Ukuran          *string `json:"ukuran"`
-	Berat           int     `json:"berat"`
-	Kategori        string  `json:"kategori"`
-	Kategori_id     int     `json:"-"`
-	Price           float64 `json:"price"`
-	Picture         *string `json:"costume_picture"`
-	Available       string  `json:"available"`
-	Created_at      string  `json:"created_at"`
-	Updated_at      string  `json:"updated_at"`
+	Id               int                                  `json:"id"`
+	User_id          string                               `json:"user_id"`
+	Username         string                               `json:"username"`
+	Profile_picture  *string                              `json:"profile_picture"`
+	Name             string                               `json:"name"`
+	Description      string                               `json:"description"`
+	Bahan            string                               `json:"bahan"`
+	Ukuran           *string                              `json:"ukuran"`
+	Berat            int                                  `json:"berat"`
+	Kategori         string                               `json:"kategori"`
+	Kategori_id      int                                  `json:"-"`
+	Price            float64                              `json:"price"`
+	Picture          *string                              `json:"costume_picture"`
+	Picture_variants *web.ImageVariantsResponse           `json:"costume_picture_variants"`
+	Status           string                               `json:"status"`
+	Created_at       string                               `json:"created_at"`
+	Updated_at       string                               `json:"updated_at"`
+	Images           []CostumeImageResponse               `json:"images"`
+	Attributes       []CostumeAttributeResponse           `json:"attributes"`
+	Variants         []CostumeVariantResponse             `json:"variants"`
+	Measurements     *CostumeMeasurementResponse          `json:"measurements"`
+	Fit              *CostumeFitResponse                  `json:"fit,omitempty"`
+	Pricing          *CostumePricingResponse              `json:"pricing,omitempty"`
+	Price_quote      *CostumePriceQuoteResponse           `json:"price_quote,omitempty"`
+	Availability     *CostumeAvailabilitySettingsResponse `json:"availability,omitempty"`
+	Seller_vacation  *SellerVacationResponse              `json:"seller_vacation,omitempty"`
+	Sort_value       string                               `json:"-"`
+}
+
+type CostumeListResponse struct {
+	Costumes    []CostumeResponse `json:"costumes"`
+	Next_cursor *string           `json:"next_cursor"`
 }
 
 type SimpleCostumeResponse struct {
@@ -27,18 +48,195 @@ type SimpleCostumeResponse struct {
 }
 
 type SellerCostumeResponse struct {
-	Id          int     `json:"id"`
-	User_id     string  `json:"user_id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Bahan       string  `json:"bahan"`
-	Ukuran      *string `json:"ukuran"`
-	Berat       int     `json:"berat"`
-	Kategori    string  `json:"kategori"`
-	Price       float64 `json:"price"`
-	KotaAsal    string  `json:"kota_asal"`
-	Picture     *string `json:"costume_picture"`
-	Available   string  `json:"available"`
-	Created_at  string  `json:"created_at"`
-	Updated_at  string  `json:"updated_at"`
+	Id               int                         `json:"id"`
+	User_id          string                      `json:"user_id"`
+	Name             string                      `json:"name"`
+	Description      string                      `json:"description"`
+	Bahan            string                      `json:"bahan"`
+	Ukuran           *string                     `json:"ukuran"`
+	Berat            int                         `json:"berat"`
+	Kategori         string                      `json:"kategori"`
+	Price            float64                     `json:"price"`
+	KotaAsal         string                      `json:"kota_asal"`
+	Picture          *string                     `json:"costume_picture"`
+	Picture_variants *web.ImageVariantsResponse  `json:"costume_picture_variants"`
+	Status           string                      `json:"status"`
+	Created_at       string                      `json:"created_at"`
+	Updated_at       string                      `json:"updated_at"`
+	Images           []CostumeImageResponse      `json:"images"`
+	Attributes       []CostumeAttributeResponse  `json:"attributes"`
+	Variants         []CostumeVariantResponse    `json:"variants"`
+	Measurements     *CostumeMeasurementResponse `json:"measurements"`
+	Pricing          *CostumePricingResponse     `json:"pricing"`
+}
+
+type CostumeSearchResponse struct {
+	Id               int                        `json:"id"`
+	User_id          string                     `json:"user_id"`
+	Username         string                     `json:"username"`
+	Name             string                     `json:"name"`
+	Name_highlight   string                     `json:"name_highlight"`
+	Snippet          string                     `json:"snippet"`
+	Kategori         string                     `json:"kategori"`
+	Bahan            string                     `json:"bahan"`
+	Price            float64                    `json:"price"`
+	Picture          *string                    `json:"costume_picture"`
+	Picture_variants *web.ImageVariantsResponse `json:"costume_picture_variants"`
+	Images           []CostumeImageResponse     `json:"images"`
+	Attributes       []CostumeAttributeResponse `json:"attributes"`
+	Fit              *CostumeFitResponse        `json:"fit,omitempty"`
+	Rank             string                     `json:"-"`
+}
+
+type CostumeSearchListResponse struct {
+	Costumes    []CostumeSearchResponse `json:"costumes"`
+	Next_cursor *string                 `json:"next_cursor"`
+}
+
+type CostumeImageResponse struct {
+	Id       int                        `json:"id"`
+	Url      string                     `json:"url"`
+	Position int                        `json:"position"`
+	Is_cover bool                       `json:"is_cover"`
+	Variants *web.ImageVariantsResponse `json:"variants"`
+}
+
+type CostumeAttributeResponse struct {
+	Attribute_id int             `json:"-"`
+	Key          string          `json:"key"`
+	Label        string          `json:"label"`
+	Type         string          `json:"type"`
+	Value        json.RawMessage `json:"value"`
+}
+
+// CostumeVariantResponse carries the price to charge, Price_override is nil when the variant uses the listing price.
+type CostumeVariantResponse struct {
+	Id             int      `json:"id"`
+	Size           string   `json:"size"`
+	Quantity       int      `json:"quantity"`
+	Available      int      `json:"available"`
+	Price          float64  `json:"price"`
+	Price_override *float64 `json:"price_override"`
+}
+
+type CostumeMeasurementResponse struct {
+	Chest      *float64 `json:"chest"`
+	Waist      *float64 `json:"waist"`
+	Hip        *float64 `json:"hip"`
+	Height_min *float64 `json:"height_min"`
+	Height_max *float64 `json:"height_max"`
+	Shoe_size  *float64 `json:"shoe_size"`
+}
+
+// CostumeFitResponse compares a costume with the buyer's measurements, Score is nil when no dimension could be compared.
+// List endpoints leave Dimensions out.
+type CostumeFitResponse struct {
+	Score      *int                          `json:"score"`
+	Fits       bool                          `json:"fits"`
+	Verdict    string                        `json:"verdict"`
+	Dimensions []CostumeFitDimensionResponse `json:"dimensions,omitempty"`
+}
+
+type CostumeFitDimensionResponse struct {
+	Dimension     string  `json:"dimension"`
+	Costume_value string  `json:"costume_value"`
+	Buyer_value   float64 `json:"buyer_value"`
+	Status        string  `json:"status"`
+	Score         int     `json:"score"`
+}
+
+type CostumePricingResponse struct {
+	Daily_rate                float64                          `json:"daily_rate"`
+	Min_days                  int                              `json:"min_days"`
+	Max_days                  *int                             `json:"max_days"`
+	Weekend_surcharge_percent float64                          `json:"weekend_surcharge_percent"`
+	Discounts                 []CostumePricingDiscountResponse `json:"discounts"`
+	Date_rules                []CostumePricingDateRuleResponse `json:"date_rules"`
+}
+
+type CostumePricingDiscountResponse struct {
+	Min_days int     `json:"min_days"`
+	Percent  float64 `json:"percent"`
+}
+
+type CostumePricingDateRuleResponse struct {
+	Label      string  `json:"label"`
+	Start_date string  `json:"start_date"`
+	End_date   string  `json:"end_date"`
+	Type       string  `json:"type"`
+	Value      float64 `json:"value"`
+}
+
+// CostumePriceQuoteResponse prices a rental from Start_date to End_date, both days included.
+type CostumePriceQuoteResponse struct {
+	Variant_id       int                            `json:"variant_id"`
+	Start_date       string                         `json:"start_date"`
+	End_date         string                         `json:"end_date"`
+	Days             int                            `json:"days"`
+	Daily_rate       float64                        `json:"daily_rate"`
+	Breakdown        []CostumePriceQuoteDayResponse `json:"breakdown"`
+	Subtotal         float64                        `json:"subtotal"`
+	Discount_percent float64                        `json:"discount_percent"`
+	Discount         float64                        `json:"discount"`
+	Total            float64                        `json:"total"`
+}
+
+// CostumePriceQuoteDayResponse is one rented day, Rule is "daily rate", "weekend" or the label of a date rule.
+type CostumePriceQuoteDayResponse struct {
+	Date  string  `json:"date"`
+	Rule  string  `json:"rule"`
+	Price float64 `json:"price"`
+}
+
+// CostumeAvailabilitySettingsResponse is what the seller configured, buyers only see its effect in the calendar.
+type CostumeAvailabilitySettingsResponse struct {
+	Turnaround_days int                       `json:"turnaround_days"`
+	Blackouts       []CostumeBlackoutResponse `json:"blackouts"`
+}
+
+type CostumeBlackoutResponse struct {
+	Id         int     `json:"id"`
+	Start_date string  `json:"start_date"`
+	End_date   string  `json:"end_date"`
+	Reason     *string `json:"reason"`
+}
+
+type CostumeAvailabilityResponse struct {
+	Costume_id      int                              `json:"costume_id"`
+	Variant_id      int                              `json:"variant_id"`
+	Turnaround_days int                              `json:"turnaround_days"`
+	Days            []CostumeAvailabilityDayResponse `json:"days"`
+}
+
+// CostumeAvailabilityDayResponse has Status "available", "booked" when every unit is rented out or being cleaned,
+// "blackout" when the seller blocked the day or "vacation" while the seller is away.
+type CostumeAvailabilityDayResponse struct {
+	Date      string `json:"date"`
+	Status    string `json:"status"`
+	Available int    `json:"available"`
+}
+
+// SellerVacationResponse flags a listing whose seller is away, Active is false while the vacation is still ahead.
+type SellerVacationResponse struct {
+	Start_date string  `json:"start_date"`
+	End_date   string  `json:"end_date"`
+	Message    *string `json:"message"`
+	Active     bool    `json:"active"`
+}
+
+type CostumeImportResponse struct {
+	Total   int                        `json:"total"`
+	Created int                        `json:"created"`
+	Failed  int                        `json:"failed"`
+	Rows    []CostumeImportRowResponse `json:"rows"`
+}
+
+// CostumeImportRowResponse reports one csv row, Row is the line number in the uploaded file and Costume_id is set
+// only for a listing that was saved.
+type CostumeImportRowResponse struct {
+	Row        int      `json:"row"`
+	Name       string   `json:"name"`
+	Status     string   `json:"status"`
+	Costume_id *int     `json:"costume_id"`
+	Errors     []string `json:"errors"`
 }
@@ -0,0 +1,44 @@
+package costume
+
+type CostumeSearchRequest struct {
+	Q           string            `validate:"max=100"`
+	Kategori    int               `validate:"min=0"`
+	Ukuran      string            `validate:"max=4"`
+	Bahan       string            `validate:"max=30"`
+	Min_price   *float64          `validate:"omitempty,min=0"`
+	Max_price   *float64          `validate:"omitempty,min=0"`
+	City_id     int               `validate:"min=0"`
+	Province_id int               `validate:"min=0"`
+	Min_rating  float64           `validate:"min=0,max=5"`
+	Attributes  map[string]string `validate:"max=10,dive,keys,min=1,max=50,endkeys,min=1,max=100"`
+	Sort        string            `validate:"required,oneof=newest price_asc price_desc rating popularity trending"`
+	Cursor      string
+	Limit       int `validate:"min=1,max=50"`
+	After_value *string
+	After_id    int
+	Buyer_id    string
+	Seller_id   string
+	// Unpaged returns every matching costume, for clients from before cursor paging that send neither cursor nor limit
+	Unpaged bool
+}
+
+type CostumeTextSearchRequest struct {
+	Q          string `validate:"required,min=2,max=100"`
+	Cursor     string
+	Limit      int `validate:"min=1,max=50"`
+	After_rank *string
+	After_id   int
+	Buyer_id   string
+}
+
+type CostumeTrendingRequest struct {
+	Kategori int `validate:"min=0"`
+	City_id  int `validate:"min=0"`
+	Limit    int `validate:"min=1,max=50"`
+}
+
+type CostumeSimilarRequest struct {
+	Costume_id int
+	Limit      int `validate:"min=1,max=20"`
+	Buyer_id   string
+}
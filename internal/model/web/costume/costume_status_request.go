@@ -0,0 +1,6 @@
+package costume
+
+type CostumeStatusRequest struct {
+	Id     int    `json:"-"`
+	Status string `validate:"required,oneof=draft published paused archived" json:"status"`
+}
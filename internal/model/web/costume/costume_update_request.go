@@ -1,14 +1,20 @@
 package costume
 
+import (
+	"cosplayrent/internal/model/web"
+	"encoding/json"
+)
+
 type CostumeUpdateRequest struct {
-	Id          int
-	Name        string  `validate:"max=30" json:"name,omitempty"`
-	Description string  `validate:"max=1000" json:"description,omitempty"`
-	Bahan       string  `validate:"max=30" json:"bahan,omitempty"`
-	Ukuran      string  `validate:"max=30" json:"ukuran,omitempty"`
-	Berat       int     `json:"berat,omitempty"`
-	Kategori    int     `validate:"max=30" json:"kategori,omitempty"`
-	Available   string  `validate:"max=13" json:"available,omitempty"`
-	Price       float64 `json:"price,omitempty"`
-	Picture     *string `json:"costume_picture,omitempty"`
+	Id          int                   `json:"-"`
+	Name        web.Optional[string]  `json:"name"`
+	Description web.Optional[string]  `json:"description"`
+	Bahan       web.Optional[string]  `json:"bahan"`
+	Ukuran      web.Optional[string]  `json:"ukuran"`
+	Berat       web.Optional[int]     `json:"berat"`
+	Kategori    web.Optional[int]     `json:"kategori"`
+	Price       web.Optional[float64] `json:"price"`
+	Picture     web.Optional[string]  `json:"costume_picture"`
+	// Attributes is merged into the stored values, a key sent as null removes that value.
+	Attributes web.Optional[map[string]json.RawMessage] `json:"attributes"`
 }
@@ -0,0 +1,19 @@
+package costume
+
+import "cosplayrent/internal/model/web"
+
+type CostumeVariantRequest struct {
+	Costume_id int      `json:"-"`
+	Size       string   `validate:"required,min=1,max=30" json:"size"`
+	Quantity   int      `validate:"required,min=1,max=1000" json:"quantity"`
+	Price      *float64 `validate:"omitempty,gt=0" json:"price"`
+}
+
+// CostumeVariantUpdateRequest clears the price override when price is null.
+type CostumeVariantUpdateRequest struct {
+	Id         int                   `json:"-"`
+	Costume_id int                   `json:"-"`
+	Size       web.Optional[string]  `json:"size"`
+	Quantity   web.Optional[int]     `json:"quantity"`
+	Price      web.Optional[float64] `json:"price"`
+}
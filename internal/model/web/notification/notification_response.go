@@ -0,0 +1,16 @@
+package notification
+
+type UserNotificationResponse struct {
+	Id         int     `json:"id"`
+	Type       string  `json:"type"`
+	Title      string  `json:"title"`
+	Body       string  `json:"body"`
+	Costume_id *int    `json:"costume_id"`
+	Read_at    *string `json:"read_at"`
+	Created_at string  `json:"created_at"`
+}
+
+type UserNotificationListResponse struct {
+	Unread        int                        `json:"unread"`
+	Notifications []UserNotificationResponse `json:"notifications"`
+}
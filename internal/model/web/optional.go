@@ -0,0 +1,27 @@
+package web
+
+import "encoding/json"
+
+// Optional tells an absent PATCH field apart from an explicit null.
+type Optional[T any] struct {
+	Set   bool
+	Null  bool
+	Value T
+}
+
+func (optional *Optional[T]) UnmarshalJSON(data []byte) error {
+	optional.Set = true
+	if string(data) == "null" {
+		optional.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &optional.Value)
+}
+
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{Set: true, Value: value}
+}
+
+func Null[T any]() Optional[T] {
+	return Optional[T]{Set: true, Null: true}
+}
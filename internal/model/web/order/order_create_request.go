@@ -4,13 +4,17 @@ type OrderRequest struct {
 	Seller_id             string  `validate:"required" json:"seller_id"`
 	Seller_name           string  `validate:"required" json:"merchant_name"`
 	Costume_id            int     `validate:"required" json:"costume_id"`
+	Variant_id            int     `json:"variant_id"`
 	Costume_name          string  `validate:"required" json:"costume_name"`
 	Costume_category      string  `validate:"required" json:"costume_category"`
 	Costume_price         float64 `validate:"required" json:"costume_price"`
+	Start_date            string  `validate:"required" json:"start_date"`
+	End_date              string  `validate:"required" json:"end_date"`
 	Shippment_destination string  `validate:"required" json:"shipment_destination"`
 	Shipment_origin       string  `validate:"required" json:"shipment_origin"`
 	TotalAmount           float64 `validate:"required" json:"total"`
 	Payment_method        string  `validate:"required" json:"payment_method"`
+	Totp_code             string  `json:"-"`
 }
 
 type OrderEventRequest struct {
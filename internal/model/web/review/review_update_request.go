@@ -1,7 +1,9 @@
 package review
 
+import "cosplayrent/internal/model/web"
+
 type ReviewUpdateRequest struct {
-	Review_picture *string `json:"review_picture,omitempty"`
-	Description    string  `json:"description,omitempty"`
-	Rating         int     `json:"rating,omitempty" `
+	Review_picture web.Optional[string] `json:"review_picture"`
+	Description    web.Optional[string] `json:"description"`
+	Rating         web.Optional[int]    `json:"rating"`
 }
@@ -0,0 +1,7 @@
+package search
+
+type SearchSuggestResponse struct {
+	Costumes   []string `json:"costumes"`
+	Categories []string `json:"categories"`
+	Sellers    []string `json:"sellers"`
+}
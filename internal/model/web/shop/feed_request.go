@@ -0,0 +1,9 @@
+package shop
+
+type FeedRequest struct {
+	Follower_id string
+	Cursor      string
+	Limit       int `validate:"min=1,max=50"`
+	After_value *string
+	After_id    int
+}
@@ -0,0 +1,45 @@
+package shop
+
+import (
+	"cosplayrent/internal/model/web"
+	"cosplayrent/internal/model/web/costume"
+)
+
+type ShopResponse struct {
+	Id                       string                          `json:"id"`
+	Name                     string                          `json:"name"`
+	Profile_picture          *string                         `json:"profile_picture"`
+	Profile_picture_variants *web.ImageVariantsResponse      `json:"profile_picture_variants"`
+	City                     *string                         `json:"city"`
+	Joined_at                string                          `json:"joined_at"`
+	Verified                 bool                            `json:"verified"`
+	Following                bool                            `json:"following"`
+	Vacation                 *costume.SellerVacationResponse `json:"vacation"`
+	Stats                    ShopStatsResponse               `json:"stats"`
+	Costumes                 []costume.CostumeResponse       `json:"costumes"`
+	Next_cursor              *string                         `json:"next_cursor"`
+}
+
+// ShopStatsResponse is computed in one query per shop. Response_rate is the percentage of the last 90 days of orders
+// the seller acted on within 24 hours, nil when there were none to answer.
+type ShopStatsResponse struct {
+	Average_rating    float64  `json:"average_rating"`
+	Review_count      int      `json:"review_count"`
+	Completed_rentals int      `json:"completed_rentals"`
+	Follower_count    int      `json:"follower_count"`
+	Response_rate     *float64 `json:"response_rate"`
+}
+
+type FeedResponse struct {
+	Items       []FeedItemResponse `json:"items"`
+	Next_cursor *string            `json:"next_cursor"`
+}
+
+// FeedItemResponse is a listing that was published or restocked by a followed seller, Type is new_listing or restock.
+type FeedItemResponse struct {
+	Id         int                     `json:"id"`
+	Type       string                  `json:"type"`
+	Created_at string                  `json:"created_at"`
+	Costume    costume.CostumeResponse `json:"costume"`
+	Sort_value string                  `json:"-"`
+}
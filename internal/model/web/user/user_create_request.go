@@ -11,5 +11,9 @@ type UserVerificationCode struct {
 }
 
 type IdentityCardRequest struct {
-	IdentityCard_picture *string `validate:"required,min=5,max=255" json:"identitycard_picture"`
+	IdentityCard_file []byte `validate:"required" json:"-"`
+}
+
+type IdentityCardRejectRequest struct {
+	Reason string `validate:"required,min=5,max=500" json:"reason"`
 }
@@ -4,3 +4,12 @@ type UserLoginRequest struct {
 	Email    string `validate:"required,min=5,max=254" json:"email"`
 	Password string `validate:"required,min=5,max=20" json:"password"`
 }
+
+type TwoFactorCodeRequest struct {
+	Code string `validate:"required,min=6,max=11" json:"code"`
+}
+
+type TwoFactorLoginRequest struct {
+	Challenge_token string `validate:"required" json:"challenge_token"`
+	Code            string `validate:"required,min=6,max=11" json:"code"`
+}
@@ -0,0 +1,9 @@
+package user
+
+type UserMeasurementRequest struct {
+	Chest     *float64 `validate:"omitempty,min=40,max=200" json:"chest"`
+	Waist     *float64 `validate:"omitempty,min=30,max=200" json:"waist"`
+	Hip       *float64 `validate:"omitempty,min=40,max=200" json:"hip"`
+	Height    *float64 `validate:"omitempty,min=80,max=250" json:"height"`
+	Shoe_size *float64 `validate:"omitempty,min=20,max=55" json:"shoe_size"`
+}
@@ -1,21 +1,34 @@
 package user
 
+import "cosplayrent/internal/model/web"
+
 type UserResponse struct {
-	Id                   string  `json:"id"`
-	Name                 string  `json:"name"`
-	Email                string  `json:"email"`
-	Address              *string `json:"address"`
-	Profile_picture      *string `json:"profile_picture"`
-	Origin_province_name *string `json:"origin_province_name"`
-	Origin_province_id   *int    `json:"origin_province_id"`
-	Origin_city_name     *string `json:"origin_city_name"`
-	Origin_city_id       *int    `json:"origin_city_id"`
-	Created_at           string  `json:"created_at"`
-	Updated_at           string  `json:"updated_at"`
+	Id                       string                     `json:"id"`
+	Name                     string                     `json:"name"`
+	Email                    string                     `json:"email"`
+	Address                  *string                    `json:"address"`
+	Profile_picture          *string                    `json:"profile_picture"`
+	Profile_picture_variants *web.ImageVariantsResponse `json:"profile_picture_variants"`
+	Origin_province_name     *string                    `json:"origin_province_name"`
+	Origin_province_id       *int                       `json:"origin_province_id"`
+	Origin_city_name         *string                    `json:"origin_city_name"`
+	Origin_city_id           *int                       `json:"origin_city_id"`
+	Created_at               string                     `json:"created_at"`
+	Updated_at               string                     `json:"updated_at"`
 }
 
 type IdentityCardResponse struct {
-	IdentityCard_picture string `json:"identitycard_picture"`
+	IdentityCard_picture string  `json:"identitycard_picture"`
+	IdentityCard_status  string  `json:"identitycard_status"`
+	IdentityCard_reason  *string `json:"identitycard_rejection_reason"`
+}
+
+type PendingIdentityCardResponse struct {
+	User_id                string `json:"user_id"`
+	Name                   string `json:"name"`
+	Email                  string `json:"email"`
+	IdentityCard_picture   string `json:"identitycard_picture"`
+	IdentityCard_submitted string `json:"identitycard_submitted_at"`
 }
 
 type UserEmoneyResponse struct {
@@ -42,3 +55,35 @@ type SellerAddressResponse struct {
 	Seller_origin_city_name     *string `json:"seller_origin_city_name"`
 	Seller_origin_city_id       *int    `json:"seller_origin_city_id"`
 }
+
+type UserLoginResponse struct {
+	Token               string `json:"token,omitempty"`
+	Two_factor_required bool   `json:"two_factor_required"`
+	Challenge_token     string `json:"challenge_token,omitempty"`
+}
+
+type TwoFactorEnrollResponse struct {
+	Secret      string `json:"secret"`
+	Otpauth_uri string `json:"otpauth_uri"`
+}
+
+type TwoFactorRecoveryCodesResponse struct {
+	Recovery_codes []string `json:"recovery_codes"`
+}
+
+type UserMeasurementResponse struct {
+	Chest      *float64 `json:"chest"`
+	Waist      *float64 `json:"waist"`
+	Hip        *float64 `json:"hip"`
+	Height     *float64 `json:"height"`
+	Shoe_size  *float64 `json:"shoe_size"`
+	Updated_at *string  `json:"updated_at"`
+}
+
+// UserVacationResponse has nil dates when the seller has no vacation that is running or coming up.
+type UserVacationResponse struct {
+	Start_date *string `json:"start_date"`
+	End_date   *string `json:"end_date"`
+	Message    *string `json:"message"`
+	Active     bool    `json:"active"`
+}
@@ -1,16 +1,26 @@
 package user
 
+import "cosplayrent/internal/model/web"
+
 type UserPatchRequest struct {
-	Name                 *string `json:"name" validate:"max=20"`
-	Email                *string `json:"email" validate:"max=254"`
-	Address              *string `json:"address" validate:"max=100"`
-	Profile_picture      *string `json:"profile_picture"`
-	Origin_province_name *string `json:"origin_province_name" validate:"max=30"`
-	Origin_province_id   *int    `json:"origin_province_id"`
-	Origin_city_name     *string `json:"origin_city_name" validate:"max=30"`
-	Origin_city_id       *int    `json:"origin_city_id"`
+	Name                 web.Optional[string] `json:"name"`
+	Address              web.Optional[string] `json:"address"`
+	Profile_picture      web.Optional[string] `json:"profile_picture"`
+	Origin_province_name web.Optional[string] `json:"origin_province_name"`
+	Origin_province_id   web.Optional[int]    `json:"origin_province_id"`
+	Origin_city_name     web.Optional[string] `json:"origin_city_name"`
+	Origin_city_id       web.Optional[int]    `json:"origin_city_id"`
 }
 
 type TopUpEmoney struct {
 	Emoney_amount float64 `validate:"required" json:"emoney_amount"`
 }
+
+type UserChangePasswordRequest struct {
+	Current_password string `validate:"required" json:"current_password"`
+	New_password     string `validate:"required,min=5,max=20" json:"new_password"`
+}
+
+type UserChangeEmailRequest struct {
+	Email string `validate:"required,email,max=254" json:"email"`
+}
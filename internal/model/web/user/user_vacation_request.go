@@ -0,0 +1,7 @@
+package user
+
+type UserVacationRequest struct {
+	Start_date string  `validate:"required" json:"start_date"`
+	End_date   string  `validate:"required" json:"end_date"`
+	Message    *string `validate:"omitempty,max=500" json:"message"`
+}
@@ -21,3 +21,9 @@ type AppResponse struct {
 type WishlistStatusResponse struct {
 	Status_wishlist string `json:"status_wishlist"`
 }
+
+type ImageVariantsResponse struct {
+	Thumbnail string `json:"thumbnail"`
+	Medium    string `json:"medium"`
+	Large     string `json:"large"`
+}
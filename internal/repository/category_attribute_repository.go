@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"cosplayrent/internal/model/domain"
+	"cosplayrent/internal/model/web/category"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+type CategoryAttributeRepository struct {
+	Log *zerolog.Logger
+}
+
+func NewCategoryAttributeRepository(zerolog *zerolog.Logger) *CategoryAttributeRepository {
+	return &CategoryAttributeRepository{
+		Log: zerolog,
+	}
+}
+
+func (repository *CategoryAttributeRepository) scanAttributes(rows *sql.Rows) []domain.CategoryAttribute {
+	defer rows.Close()
+
+	attributes := []domain.CategoryAttribute{}
+	for rows.Next() {
+		attribute := domain.CategoryAttribute{}
+		var options []byte
+		err := rows.Scan(&attribute.Id, &attribute.Category_id, &attribute.Key, &attribute.Label, &attribute.Type, &options, &attribute.Required, &attribute.Created_at, &attribute.Updated_at)
+		if err != nil {
+			respErr := errors.New("failed to scan query result")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+
+		err = json.Unmarshal(options, &attribute.Options)
+		if err != nil {
+			respErr := errors.New("failed to decode attribute options")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+
+		attributes = append(attributes, attribute)
+	}
+
+	return attributes
+}
+
+// FindEffective returns the attributes a costume in categoryId can carry: the category's own definitions plus
+// those inherited from its ancestors, where a definition closer to the category overrides one with the same key.
+func (repository *CategoryAttributeRepository) FindEffective(ctx context.Context, tx *sql.Tx, categoryId int) []domain.CategoryAttribute {
+	query := `WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id, 0 AS depth FROM categories WHERE id = $1
+			UNION
+			SELECT c.id, c.parent_id, a.depth + 1 FROM categories c JOIN ancestors a ON c.id = a.parent_id
+		)
+		SELECT id, category_id, key, label, type, options, required, created_at, updated_at FROM (
+			SELECT DISTINCT ON (ca.key) ca.*
+			FROM category_attributes ca
+			JOIN ancestors a ON a.id = ca.category_id
+			ORDER BY ca.key, a.depth
+		) effective
+		ORDER BY key`
+
+	rows, err := tx.QueryContext(ctx, query, categoryId)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return repository.scanAttributes(rows)
+}
+
+// FindByCategory returns the attributes defined on the category itself, without the inherited ones.
+func (repository *CategoryAttributeRepository) FindByCategory(ctx context.Context, tx *sql.Tx, categoryId int) []domain.CategoryAttribute {
+	query := "SELECT id, category_id, key, label, type, options, required, created_at, updated_at FROM category_attributes WHERE category_id = $1 ORDER BY key"
+
+	rows, err := tx.QueryContext(ctx, query, categoryId)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return repository.scanAttributes(rows)
+}
+
+func (repository *CategoryAttributeRepository) FindById(ctx context.Context, tx *sql.Tx, categoryId int, id int) (domain.CategoryAttribute, error) {
+	query := "SELECT id, category_id, key, label, type, options, required, created_at, updated_at FROM category_attributes WHERE category_id = $1 AND id = $2"
+
+	rows, err := tx.QueryContext(ctx, query, categoryId, id)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	attributes := repository.scanAttributes(rows)
+	if len(attributes) == 0 {
+		return domain.CategoryAttribute{}, errors.New("attribute not found")
+	}
+
+	return attributes[0], nil
+}
+
+func (repository *CategoryAttributeRepository) KeyExists(ctx context.Context, tx *sql.Tx, categoryId int, key string) bool {
+	query := "SELECT EXISTS (SELECT 1 FROM category_attributes WHERE category_id = $1 AND key = $2)"
+
+	var exists bool
+	err := tx.QueryRowContext(ctx, query, categoryId, key).Scan(&exists)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return exists
+}
+
+func (repository *CategoryAttributeRepository) Create(ctx context.Context, tx *sql.Tx, attribute domain.CategoryAttribute) int {
+	query := "INSERT INTO category_attributes (category_id,key,label,type,options,required,created_at,updated_at) VALUES ($1,$2,$3,$4,$5::jsonb,$6,$7,$8) RETURNING id"
+
+	options, _ := json.Marshal(attribute.Options)
+
+	var id int
+	err := tx.QueryRowContext(ctx, query, attribute.Category_id, attribute.Key, attribute.Label, attribute.Type, string(options), attribute.Required, attribute.Created_at, attribute.Updated_at).Scan(&id)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return id
+}
+
+func (repository *CategoryAttributeRepository) Update(ctx context.Context, tx *sql.Tx, attributeRequest category.CategoryAttributeUpdateRequest, updatedAt *time.Time) {
+	query := "UPDATE category_attributes SET "
+	args := []interface{}{}
+
+	query, args = appendPatchField(query, args, "label", attributeRequest.Label)
+	query, args = appendPatchField(query, args, "required", attributeRequest.Required)
+
+	if attributeRequest.Options.Set {
+		options, _ := json.Marshal(attributeRequest.Options.Value)
+		args = append(args, string(options))
+		query += fmt.Sprintf("options = $%d::jsonb, ", len(args))
+	}
+
+	args = append(args, updatedAt)
+	query += fmt.Sprintf("updated_at = $%d ", len(args))
+
+	args = append(args, attributeRequest.Id)
+	query += fmt.Sprintf("WHERE id = $%d", len(args))
+
+	_, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *CategoryAttributeRepository) Delete(ctx context.Context, tx *sql.Tx, id int) {
+	_, err := tx.ExecContext(ctx, "DELETE FROM category_attributes WHERE id = $1", id)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+// Move files the attribute under another category. It becomes optional there, the category's other costumes have no
+// value for it yet.
+func (repository *CategoryAttributeRepository) Move(ctx context.Context, tx *sql.Tx, id int, categoryId int, updatedAt *time.Time) {
+	_, err := tx.ExecContext(ctx, "UPDATE category_attributes SET category_id = $1, required = false, updated_at = $2 WHERE id = $3", categoryId, updatedAt, id)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+// MergeValues repoints the costume values of one attribute to another, a costume that already has a value for the
+// other attribute keeps that one.
+func (repository *CategoryAttributeRepository) MergeValues(ctx context.Context, tx *sql.Tx, fromId int, intoId int) {
+	query := `UPDATE costume_attributes ca SET attribute_id = $2
+		WHERE ca.attribute_id = $1
+			AND NOT EXISTS (SELECT 1 FROM costume_attributes x WHERE x.costume_id = ca.costume_id AND x.attribute_id = $2)`
+
+	_, err := tx.ExecContext(ctx, query, fromId, intoId)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+// CountValuesOutsideOptions counts stored costume values that would no longer be valid with the given options.
+func (repository *CategoryAttributeRepository) CountValuesOutsideOptions(ctx context.Context, tx *sql.Tx, id int, options []string) int {
+	query := `SELECT COUNT(*) FROM costume_attributes ca
+		WHERE ca.attribute_id = $1 AND EXISTS (
+			SELECT 1 FROM jsonb_array_elements_text(CASE jsonb_typeof(ca.value) WHEN 'array' THEN ca.value ELSE jsonb_build_array(ca.value) END) v
+			WHERE v <> ALL($2)
+		)`
+
+	var count int
+	err := tx.QueryRowContext(ctx, query, id, options).Scan(&count)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return count
+}
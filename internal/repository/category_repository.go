@@ -2,10 +2,13 @@ package repository
 
 import (
 	"context"
+	"cosplayrent/internal/model/domain"
 	"cosplayrent/internal/model/web/category"
 	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/rs/zerolog"
+	"time"
 )
 
 type CategoryRepository struct {
@@ -19,7 +22,7 @@ func NewCategoryRepository(zerolog *zerolog.Logger) *CategoryRepository {
 }
 
 func (repository *CategoryRepository) FindAllCategory(ctx context.Context, tx *sql.Tx) ([]category.CategoryResponse, error) {
-	query := "SELECT id,name from categories"
+	query := "SELECT id,parent_id,name,slug from categories ORDER BY name"
 	rows, err := tx.QueryContext(ctx, query)
 	if err != nil {
 		respErr := errors.New("failed to query into database")
@@ -33,7 +36,7 @@ func (repository *CategoryRepository) FindAllCategory(ctx context.Context, tx *s
 	categories := []category.CategoryResponse{}
 	for rows.Next() {
 		category := category.CategoryResponse{}
-		err = rows.Scan(&category.Id, &category.Name)
+		err = rows.Scan(&category.Id, &category.Parent_id, &category.Name, &category.Slug)
 		if err != nil {
 			respErr := errors.New("failed to scan query result")
 			repository.Log.Panic().Err(err).Msg(respErr.Error())
@@ -74,3 +77,184 @@ func (repository *CategoryRepository) FindCategoryNameById(ctx context.Context,
 		return categoryName, errors.New("category not found")
 	}
 }
+
+func (repository *CategoryRepository) SuggestNames(ctx context.Context, tx *sql.Tx, prefix string, limit int) []string {
+	query := `SELECT name FROM categories
+		WHERE name ILIKE $1 OR $2 <% name
+		ORDER BY name ILIKE $1 DESC, similarity(name, $2) DESC, name LIMIT $3`
+
+	return findSuggestions(ctx, tx, repository.Log, query, prefix, limit)
+}
+
+func (repository *CategoryRepository) FindById(ctx context.Context, tx *sql.Tx, id int) (domain.Category, error) {
+	query := "SELECT id,parent_id,name,slug,created_at,updated_at FROM categories WHERE id = $1"
+
+	category := domain.Category{}
+	err := tx.QueryRowContext(ctx, query, id).Scan(&category.Id, &category.Parent_id, &category.Name, &category.Slug, &category.Created_at, &category.Updated_at)
+	if err == sql.ErrNoRows {
+		return category, errors.New("category not found")
+	}
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return category, nil
+}
+
+// LockTree serializes category writes so parent checks and slug checks cannot race each other, readers are not blocked.
+func (repository *CategoryRepository) LockTree(ctx context.Context, tx *sql.Tx) {
+	_, err := tx.ExecContext(ctx, "LOCK TABLE categories IN SHARE ROW EXCLUSIVE MODE")
+	if err != nil {
+		respErr := errors.New("failed to lock categories")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *CategoryRepository) SlugExists(ctx context.Context, tx *sql.Tx, slug string, excludeId int) bool {
+	query := "SELECT EXISTS (SELECT 1 FROM categories WHERE slug = $1 AND id <> $2)"
+
+	var exists bool
+	err := tx.QueryRowContext(ctx, query, slug, excludeId).Scan(&exists)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return exists
+}
+
+func (repository *CategoryRepository) SiblingNameExists(ctx context.Context, tx *sql.Tx, parentId *int, name string, excludeId int) bool {
+	query := "SELECT EXISTS (SELECT 1 FROM categories WHERE parent_id IS NOT DISTINCT FROM $1 AND lower(name) = lower($2) AND id <> $3)"
+
+	var exists bool
+	err := tx.QueryRowContext(ctx, query, parentId, name, excludeId).Scan(&exists)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return exists
+}
+
+// InSubtree reports whether candidateId is rootId itself or one of its descendants.
+func (repository *CategoryRepository) InSubtree(ctx context.Context, tx *sql.Tx, rootId int, candidateId int) bool {
+	query := `WITH RECURSIVE subtree AS (
+			SELECT id FROM categories WHERE id = $1
+			UNION
+			SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id
+		)
+		SELECT EXISTS (SELECT 1 FROM subtree WHERE id = $2)`
+
+	var exists bool
+	err := tx.QueryRowContext(ctx, query, rootId, candidateId).Scan(&exists)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return exists
+}
+
+func (repository *CategoryRepository) Create(ctx context.Context, tx *sql.Tx, category domain.Category) int {
+	query := "INSERT INTO categories (parent_id,name,slug,created_at,updated_at) VALUES ($1,$2,$3,$4,$5) RETURNING id"
+
+	var id int
+	err := tx.QueryRowContext(ctx, query, category.Parent_id, category.Name, category.Slug, category.Created_at, category.Updated_at).Scan(&id)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return id
+}
+
+func (repository *CategoryRepository) Update(ctx context.Context, tx *sql.Tx, categoryRequest category.CategoryUpdateRequest, updatedAt *time.Time) {
+	query := "UPDATE categories SET "
+	args := []interface{}{}
+
+	query, args = appendPatchField(query, args, "name", categoryRequest.Name)
+	query, args = appendPatchField(query, args, "slug", categoryRequest.Slug)
+	query, args = appendPatchField(query, args, "parent_id", categoryRequest.Parent_id)
+
+	args = append(args, updatedAt)
+	query += fmt.Sprintf("updated_at = $%d ", len(args))
+
+	args = append(args, categoryRequest.Id)
+	query += fmt.Sprintf("WHERE id = $%d", len(args))
+
+	_, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *CategoryRepository) CountUsage(ctx context.Context, tx *sql.Tx, id int) (int, int) {
+	query := `SELECT
+			(SELECT COUNT(*) FROM costumes WHERE category_id = $1),
+			(SELECT COUNT(*) FROM categories WHERE parent_id = $1)`
+
+	var costumeCount, childCount int
+	err := tx.QueryRowContext(ctx, query, id).Scan(&costumeCount, &childCount)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return costumeCount, childCount
+}
+
+// Reassign moves the costumes and direct subcategories of one category to another.
+func (repository *CategoryRepository) Reassign(ctx context.Context, tx *sql.Tx, fromId int, toId int, updatedAt *time.Time) {
+	_, err := tx.ExecContext(ctx, "UPDATE costumes SET category_id = $1, updated_at = $3 WHERE category_id = $2", toId, fromId, updatedAt)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE categories SET parent_id = $1, updated_at = $3 WHERE parent_id = $2", toId, fromId, updatedAt)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *CategoryRepository) Delete(ctx context.Context, tx *sql.Tx, id int) {
+	_, err := tx.ExecContext(ctx, "DELETE FROM categories WHERE id = $1", id)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+// FindAllWithCostumeCount returns every category as a flat list with the number of rentable costumes filed directly under it.
+func (repository *CategoryRepository) FindAllWithCostumeCount(ctx context.Context, tx *sql.Tx) []category.CategoryTreeResponse {
+	query := `SELECT cat.id, cat.parent_id, cat.name, cat.slug, COUNT(c.id)
+		FROM categories cat
+		LEFT JOIN costumes c ON c.category_id = cat.id AND c.status = 'published'
+		GROUP BY cat.id
+		ORDER BY cat.name`
+
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer rows.Close()
+
+	categories := []category.CategoryTreeResponse{}
+	for rows.Next() {
+		category := category.CategoryTreeResponse{}
+		err = rows.Scan(&category.Id, &category.Parent_id, &category.Name, &category.Slug, &category.Costume_count)
+		if err != nil {
+			respErr := errors.New("failed to scan query result")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+
+		categories = append(categories, category)
+	}
+
+	return categories
+}
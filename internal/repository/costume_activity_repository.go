@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"cosplayrent/internal/model/domain"
+	"cosplayrent/internal/model/web/shop"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+type CostumeActivityRepository struct {
+	Log *zerolog.Logger
+}
+
+func NewCostumeActivityRepository(zerolog *zerolog.Logger) *CostumeActivityRepository {
+	return &CostumeActivityRepository{
+		Log: zerolog,
+	}
+}
+
+func (repository *CostumeActivityRepository) Create(ctx context.Context, tx *sql.Tx, activity domain.CostumeActivity) {
+	query := "INSERT INTO costume_activities (costume_id,seller_id,type,created_at) VALUES ($1,$2,$3,$4)"
+	_, err := tx.ExecContext(ctx, query, activity.Costume_id, activity.Seller_id, activity.Type, activity.Created_at)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *CostumeActivityRepository) ExistsSince(ctx context.Context, tx *sql.Tx, costumeId int, activityType string, since time.Time) bool {
+	query := "SELECT 1 FROM costume_activities WHERE costume_id = $1 AND type = $2 AND created_at >= $3 LIMIT 1"
+
+	var exists int
+	err := tx.QueryRowContext(ctx, query, costumeId, activityType, since).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return false
+	}
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return true
+}
+
+// FindFeed returns one page of activity from the sellers the user follows, newest first, plus one extra row so the
+// caller can tell whether a next page exists. Listings that are not published right now are left out.
+func (repository *CostumeActivityRepository) FindFeed(ctx context.Context, tx *sql.Tx, followerId string, afterValue *string, afterId int, limit int) []shop.FeedItemResponse {
+	query := `SELECT a.id, a.type, a.created_at, a.created_at::text,
+		c.id, c.user_id, u.name, c.name, c.description, c.material, c.size, c.weight, c.category_id, c.price, c.costume_picture, c.status, c.created_at, c.updated_at
+	FROM costume_activities a
+	JOIN follows f ON f.seller_id = a.seller_id AND f.follower_id = $1
+	JOIN costumes c ON c.id = a.costume_id
+	JOIN users u ON u.id = c.user_id
+	WHERE c.status = 'published' AND NOT ` + sellerOnVacation
+	args := []interface{}{followerId}
+
+	if afterValue != nil {
+		args = append(args, *afterValue, afterId)
+		query += fmt.Sprintf(" AND (a.created_at, a.id) < ($%d::timestamp, $%d)", len(args)-1, len(args))
+	}
+
+	args = append(args, limit+1)
+	query += fmt.Sprintf(" ORDER BY a.created_at DESC, a.id DESC LIMIT $%d", len(args))
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer rows.Close()
+
+	items := []shop.FeedItemResponse{}
+	var activityAt time.Time
+	var createdAt time.Time
+	var updatedAt time.Time
+	for rows.Next() {
+		item := shop.FeedItemResponse{}
+		err = rows.Scan(&item.Id, &item.Type, &activityAt, &item.Sort_value,
+			&item.Costume.Id, &item.Costume.User_id, &item.Costume.Username, &item.Costume.Name, &item.Costume.Description, &item.Costume.Bahan, &item.Costume.Ukuran, &item.Costume.Berat, &item.Costume.Kategori, &item.Costume.Price, &item.Costume.Picture, &item.Costume.Status, &createdAt, &updatedAt)
+		if err != nil {
+			respErr := errors.New("failed to scan query result")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		item.Created_at = activityAt.Format("2006-01-02 15:04:05")
+		item.Costume.Created_at = createdAt.Format("2006-01-02 15:04:05")
+		item.Costume.Updated_at = updatedAt.Format("2006-01-02 15:04:05")
+		items = append(items, item)
+	}
+
+	return items
+}
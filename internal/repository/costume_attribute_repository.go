@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"cosplayrent/internal/model/web/costume"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/rs/zerolog"
+)
+
+type CostumeAttributeRepository struct {
+	Log *zerolog.Logger
+}
+
+func NewCostumeAttributeRepository(zerolog *zerolog.Logger) *CostumeAttributeRepository {
+	return &CostumeAttributeRepository{
+		Log: zerolog,
+	}
+}
+
+// FindByCostumeIds loads the attribute values of a whole result page in one query, keyed by costume id.
+func (repository *CostumeAttributeRepository) FindByCostumeIds(ctx context.Context, tx *sql.Tx, costumeIds []int) map[int][]costume.CostumeAttributeResponse {
+	attributes := map[int][]costume.CostumeAttributeResponse{}
+	if len(costumeIds) == 0 {
+		return attributes
+	}
+
+	query := `SELECT ca.costume_id, d.id, d.key, d.label, d.type, ca.value
+		FROM costume_attributes ca
+		JOIN category_attributes d ON d.id = ca.attribute_id
+		WHERE ca.costume_id = ANY($1)
+		ORDER BY ca.costume_id, d.key`
+
+	rows, err := tx.QueryContext(ctx, query, costumeIds)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		attribute := costume.CostumeAttributeResponse{}
+		var costumeId int
+		var value []byte
+		err = rows.Scan(&costumeId, &attribute.Attribute_id, &attribute.Key, &attribute.Label, &attribute.Type, &value)
+		if err != nil {
+			respErr := errors.New("failed to scan query result")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		attribute.Value = json.RawMessage(value)
+		attributes[costumeId] = append(attributes[costumeId], attribute)
+	}
+
+	return attributes
+}
+
+func (repository *CostumeAttributeRepository) Save(ctx context.Context, tx *sql.Tx, costumeId int, attributeId int, value json.RawMessage) {
+	query := `INSERT INTO costume_attributes (costume_id,attribute_id,value) VALUES ($1,$2,$3::jsonb)
+		ON CONFLICT (costume_id, attribute_id) DO UPDATE SET value = EXCLUDED.value`
+
+	_, err := tx.ExecContext(ctx, query, costumeId, attributeId, string(value))
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+// DeleteExcept drops every value of the costume whose attribute is not in keepIds, e.g. after a category change.
+func (repository *CostumeAttributeRepository) DeleteExcept(ctx context.Context, tx *sql.Tx, costumeId int, keepIds []int) {
+	query := "DELETE FROM costume_attributes WHERE costume_id = $1 AND NOT (attribute_id = ANY($2))"
+
+	_, err := tx.ExecContext(ctx, query, costumeId, keepIds)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
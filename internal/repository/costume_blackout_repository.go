@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"cosplayrent/internal/model/domain"
+	"database/sql"
+	"errors"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+type CostumeBlackoutRepository struct {
+	Log *zerolog.Logger
+}
+
+func NewCostumeBlackoutRepository(zerolog *zerolog.Logger) *CostumeBlackoutRepository {
+	return &CostumeBlackoutRepository{
+		Log: zerolog,
+	}
+}
+
+// FindByCostumeId returns the blackouts that end on or after since, earliest first.
+func (repository *CostumeBlackoutRepository) FindByCostumeId(ctx context.Context, tx *sql.Tx, costumeId int, since time.Time) []domain.CostumeBlackout {
+	query := "SELECT id,costume_id,start_date,end_date,reason,created_at FROM costume_blackouts WHERE costume_id = $1 AND end_date >= $2::date ORDER BY start_date, id"
+	rows, err := tx.QueryContext(ctx, query, costumeId, since)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer rows.Close()
+
+	blackouts := []domain.CostumeBlackout{}
+	for rows.Next() {
+		blackout := domain.CostumeBlackout{}
+		err = rows.Scan(&blackout.Id, &blackout.Costume_id, &blackout.Start_date, &blackout.End_date, &blackout.Reason, &blackout.Created_at)
+		if err != nil {
+			respErr := errors.New("failed to scan query result")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		blackouts = append(blackouts, blackout)
+	}
+
+	return blackouts
+}
+
+func (repository *CostumeBlackoutRepository) Overlaps(ctx context.Context, tx *sql.Tx, costumeId int, start time.Time, end time.Time) bool {
+	query := "SELECT 1 FROM costume_blackouts WHERE costume_id = $1 AND start_date <= $3::date AND end_date >= $2::date LIMIT 1"
+
+	var exists int
+	err := tx.QueryRowContext(ctx, query, costumeId, start, end).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return false
+	}
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return true
+}
+
+func (repository *CostumeBlackoutRepository) Create(ctx context.Context, tx *sql.Tx, blackout domain.CostumeBlackout) int {
+	query := "INSERT INTO costume_blackouts (costume_id,start_date,end_date,reason,created_at) VALUES ($1,$2,$3,$4,$5) RETURNING id"
+
+	var id int
+	err := tx.QueryRowContext(ctx, query, blackout.Costume_id, blackout.Start_date, blackout.End_date, blackout.Reason, blackout.Created_at).Scan(&id)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return id
+}
+
+func (repository *CostumeBlackoutRepository) Delete(ctx context.Context, tx *sql.Tx, costumeId int, id int) error {
+	query := "DELETE FROM costume_blackouts WHERE costume_id = $1 AND id = $2"
+
+	result, err := tx.ExecContext(ctx, query, costumeId, id)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+	if affected == 0 {
+		return errors.New("blackout not found")
+	}
+
+	return nil
+}
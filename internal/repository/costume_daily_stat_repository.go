@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"cosplayrent/internal/model/domain"
+	"cosplayrent/internal/model/web/analytics"
+	"database/sql"
+	"errors"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+type CostumeDailyStatRepository struct {
+	Log *zerolog.Logger
+}
+
+func NewCostumeDailyStatRepository(zerolog *zerolog.Logger) *CostumeDailyStatRepository {
+	return &CostumeDailyStatRepository{
+		Log: zerolog,
+	}
+}
+
+// Rollup recounts every day from the given date on from costume_events, fromDate is formatted as 2006-01-02.
+func (repository *CostumeDailyStatRepository) Rollup(ctx context.Context, tx *sql.Tx, fromDate string) {
+	query := `INSERT INTO costume_daily_stats (costume_id, day, views, wishlist_adds, wishlist_removes, checkouts, completed_orders, revenue)
+		SELECT costume_id, created_at::date,
+			COUNT(*) FILTER (WHERE type = 'view'),
+			COUNT(*) FILTER (WHERE type = 'wishlist'),
+			COUNT(*) FILTER (WHERE type = 'wishlist_remove'),
+			COUNT(*) FILTER (WHERE type = 'checkout'),
+			COUNT(*) FILTER (WHERE type = 'rental'),
+			COALESCE(SUM(amount) FILTER (WHERE type = 'rental'), 0)
+		FROM costume_events
+		WHERE created_at >= $1::date
+		GROUP BY costume_id, created_at::date
+		ON CONFLICT (costume_id, day) DO UPDATE SET
+			views = EXCLUDED.views,
+			wishlist_adds = EXCLUDED.wishlist_adds,
+			wishlist_removes = EXCLUDED.wishlist_removes,
+			checkouts = EXCLUDED.checkouts,
+			completed_orders = EXCLUDED.completed_orders,
+			revenue = EXCLUDED.revenue`
+	_, err := tx.ExecContext(ctx, query, fromDate)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+// FindTotalsBySeller sums the seller's listings from startDate to endDate, both included.
+func (repository *CostumeDailyStatRepository) FindTotalsBySeller(ctx context.Context, tx *sql.Tx, sellerId string, startDate string, endDate string) domain.CostumeDailyStat {
+	query := `SELECT COALESCE(SUM(s.views), 0), COALESCE(SUM(s.wishlist_adds), 0), COALESCE(SUM(s.wishlist_removes), 0), COALESCE(SUM(s.checkouts), 0), COALESCE(SUM(s.completed_orders), 0), COALESCE(SUM(s.revenue), 0)::float8
+		FROM costume_daily_stats s
+		JOIN costumes c ON c.id = s.costume_id
+		WHERE c.user_id = $1 AND s.day BETWEEN $2::date AND $3::date`
+
+	stat := domain.CostumeDailyStat{}
+	err := tx.QueryRowContext(ctx, query, sellerId, startDate, endDate).Scan(&stat.Views, &stat.Wishlist_adds, &stat.Wishlist_removes, &stat.Checkouts, &stat.Completed_orders, &stat.Revenue)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return stat
+}
+
+// FindDailyBySeller returns only the days with activity, oldest first.
+func (repository *CostumeDailyStatRepository) FindDailyBySeller(ctx context.Context, tx *sql.Tx, sellerId string, startDate string, endDate string) []domain.CostumeDailyStat {
+	query := `SELECT s.day, SUM(s.completed_orders), SUM(s.revenue)::float8
+		FROM costume_daily_stats s
+		JOIN costumes c ON c.id = s.costume_id
+		WHERE c.user_id = $1 AND s.day BETWEEN $2::date AND $3::date
+		GROUP BY s.day
+		ORDER BY s.day`
+	rows, err := tx.QueryContext(ctx, query, sellerId, startDate, endDate)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer rows.Close()
+
+	stats := []domain.CostumeDailyStat{}
+	for rows.Next() {
+		var day time.Time
+		stat := domain.CostumeDailyStat{}
+		err = rows.Scan(&day, &stat.Completed_orders, &stat.Revenue)
+		if err != nil {
+			respErr := errors.New("failed to scan query result")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		stat.Day = &day
+		stats = append(stats, stat)
+	}
+
+	return stats
+}
+
+// FindTopBySeller ranks the seller's listings by revenue, then completed orders and views. Costume_picture is the
+// stored path.
+func (repository *CostumeDailyStatRepository) FindTopBySeller(ctx context.Context, tx *sql.Tx, sellerId string, startDate string, endDate string, limit int) []analytics.AnalyticsListingResponse {
+	query := `SELECT c.id, c.name, c.costume_picture, SUM(s.views), SUM(s.wishlist_adds), SUM(s.checkouts), SUM(s.completed_orders), SUM(s.revenue)::float8 AS revenue
+		FROM costume_daily_stats s
+		JOIN costumes c ON c.id = s.costume_id
+		WHERE c.user_id = $1 AND s.day BETWEEN $2::date AND $3::date
+		GROUP BY c.id, c.name, c.costume_picture
+		ORDER BY revenue DESC, SUM(s.completed_orders) DESC, SUM(s.views) DESC, c.id DESC
+		LIMIT $4`
+	rows, err := tx.QueryContext(ctx, query, sellerId, startDate, endDate, limit)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer rows.Close()
+
+	listings := []analytics.AnalyticsListingResponse{}
+	for rows.Next() {
+		listing := analytics.AnalyticsListingResponse{}
+		err = rows.Scan(&listing.Costume_id, &listing.Name, &listing.Costume_picture, &listing.Views, &listing.Wishlist_adds, &listing.Checkouts, &listing.Completed_orders, &listing.Revenue)
+		if err != nil {
+			respErr := errors.New("failed to scan query result")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		listings = append(listings, listing)
+	}
+
+	return listings
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"cosplayrent/internal/model/domain"
+	"database/sql"
+	"errors"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+type CostumeEventRepository struct {
+	Log *zerolog.Logger
+}
+
+func NewCostumeEventRepository(zerolog *zerolog.Logger) *CostumeEventRepository {
+	return &CostumeEventRepository{
+		Log: zerolog,
+	}
+}
+
+func (repository *CostumeEventRepository) Create(ctx context.Context, tx *sql.Tx, event domain.CostumeEvent) {
+	query := "INSERT INTO costume_events (costume_id,type,user_id,visitor_id,order_id,amount,created_at) VALUES ($1,$2,$3,$4,$5,$6,$7)"
+	_, err := tx.ExecContext(ctx, query, event.Costume_id, event.Type, event.User_id, event.Visitor_id, event.Order_id, event.Amount, event.Created_at)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+// CreateView records a view unless the same user or visitor already viewed the costume since the given time.
+func (repository *CostumeEventRepository) CreateView(ctx context.Context, tx *sql.Tx, event domain.CostumeEvent, since time.Time) {
+	query := `INSERT INTO costume_events (costume_id,type,user_id,visitor_id,created_at)
+		SELECT $1, 'view', $2::char(36), $3::char(40), $4
+		WHERE NOT EXISTS (
+			SELECT 1 FROM costume_events
+			WHERE costume_id = $1 AND type = 'view' AND created_at >= $5
+				AND (user_id = $2::char(36) OR ($2::char(36) IS NULL AND visitor_id = $3::char(40)))
+		)`
+	_, err := tx.ExecContext(ctx, query, event.Costume_id, event.User_id, event.Visitor_id, event.Created_at, since)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+// CreateForOrder records an event for the order's costume with the order total, an order completed twice is only
+// counted once.
+func (repository *CostumeEventRepository) CreateForOrder(ctx context.Context, tx *sql.Tx, orderid string, eventType string, createdAt time.Time) {
+	query := `INSERT INTO costume_events (costume_id,type,user_id,order_id,amount,created_at)
+		SELECT costume_id, $2, customer_id, id, total, $3 FROM orders WHERE id = $1
+		ON CONFLICT (order_id, type) DO NOTHING`
+	_, err := tx.ExecContext(ctx, query, orderid, eventType, createdAt)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *CostumeEventRepository) DeleteBefore(ctx context.Context, tx *sql.Tx, before time.Time) {
+	query := "DELETE FROM costume_events WHERE created_at < $1"
+	_, err := tx.ExecContext(ctx, query, before)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
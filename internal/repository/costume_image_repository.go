@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"cosplayrent/internal/model/domain"
+	"cosplayrent/internal/model/web/costume"
+	"database/sql"
+	"errors"
+	"github.com/rs/zerolog"
+)
+
+type CostumeImageRepository struct {
+	Log *zerolog.Logger
+}
+
+func NewCostumeImageRepository(zerolog *zerolog.Logger) *CostumeImageRepository {
+	return &CostumeImageRepository{
+		Log: zerolog,
+	}
+}
+
+func (repository *CostumeImageRepository) Create(ctx context.Context, tx *sql.Tx, image domain.CostumeImage) int {
+	query := "INSERT INTO costume_images (costume_id,path,position,is_cover,created_at) VALUES ($1,$2,$3,$4,$5) RETURNING id"
+	var id int
+	err := tx.QueryRowContext(ctx, query, image.Costume_id, image.Path, image.Position, image.Is_cover, image.Created_at).Scan(&id)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return id
+}
+
+func (repository *CostumeImageRepository) FindByCostumeId(ctx context.Context, tx *sql.Tx, costumeId int) []domain.CostumeImage {
+	query := "SELECT id,costume_id,path,position,is_cover,created_at FROM costume_images WHERE costume_id = $1 ORDER BY position, id"
+	rows, err := tx.QueryContext(ctx, query, costumeId)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer rows.Close()
+
+	images := []domain.CostumeImage{}
+	for rows.Next() {
+		image := domain.CostumeImage{}
+		err = rows.Scan(&image.Id, &image.Costume_id, &image.Path, &image.Position, &image.Is_cover, &image.Created_at)
+		if err != nil {
+			respErr := errors.New("failed to scan query result")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		images = append(images, image)
+	}
+
+	return images
+}
+
+// FindByCostumeIds loads the images of a whole result page in one query, keyed by costume id.
+func (repository *CostumeImageRepository) FindByCostumeIds(ctx context.Context, tx *sql.Tx, costumeIds []int) map[int][]costume.CostumeImageResponse {
+	images := map[int][]costume.CostumeImageResponse{}
+	if len(costumeIds) == 0 {
+		return images
+	}
+
+	query := "SELECT id,costume_id,path,position,is_cover FROM costume_images WHERE costume_id = ANY($1) ORDER BY costume_id, position, id"
+	rows, err := tx.QueryContext(ctx, query, costumeIds)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		image := costume.CostumeImageResponse{}
+		var costumeId int
+		err = rows.Scan(&image.Id, &costumeId, &image.Url, &image.Position, &image.Is_cover)
+		if err != nil {
+			respErr := errors.New("failed to scan query result")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		images[costumeId] = append(images[costumeId], image)
+	}
+
+	return images
+}
+
+func (repository *CostumeImageRepository) UpdatePosition(ctx context.Context, tx *sql.Tx, image domain.CostumeImage) {
+	query := "UPDATE costume_images SET position = $1 WHERE id = $2 AND costume_id = $3"
+	_, err := tx.ExecContext(ctx, query, image.Position, image.Id, image.Costume_id)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+// SetCover moves the cover flag to imageId and mirrors its path into costumes.costume_picture for older clients.
+func (repository *CostumeImageRepository) SetCover(ctx context.Context, tx *sql.Tx, costumeId int, imageId int) {
+	query := "UPDATE costume_images SET is_cover = false WHERE costume_id = $1 AND is_cover"
+	_, err := tx.ExecContext(ctx, query, costumeId)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	query = "UPDATE costume_images SET is_cover = true WHERE costume_id = $1 AND id = $2"
+	_, err = tx.ExecContext(ctx, query, costumeId, imageId)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	query = "UPDATE costumes SET costume_picture = (SELECT path FROM costume_images WHERE id = $2) WHERE id = $1"
+	_, err = tx.ExecContext(ctx, query, costumeId, imageId)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *CostumeImageRepository) UpdateCoverPath(ctx context.Context, tx *sql.Tx, costumeId int, path string) {
+	query := "UPDATE costume_images SET path = $1 WHERE costume_id = $2 AND is_cover"
+	_, err := tx.ExecContext(ctx, query, path, costumeId)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *CostumeImageRepository) Delete(ctx context.Context, tx *sql.Tx, image domain.CostumeImage) {
+	query := "DELETE FROM costume_images WHERE id = $1 AND costume_id = $2"
+	_, err := tx.ExecContext(ctx, query, image.Id, image.Costume_id)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
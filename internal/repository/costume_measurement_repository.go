@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"cosplayrent/internal/model/domain"
+	"database/sql"
+	"errors"
+	"github.com/rs/zerolog"
+)
+
+type CostumeMeasurementRepository struct {
+	Log *zerolog.Logger
+}
+
+func NewCostumeMeasurementRepository(zerolog *zerolog.Logger) *CostumeMeasurementRepository {
+	return &CostumeMeasurementRepository{
+		Log: zerolog,
+	}
+}
+
+func (repository *CostumeMeasurementRepository) Save(ctx context.Context, tx *sql.Tx, measurement domain.CostumeMeasurement) {
+	query := `INSERT INTO costume_measurements (costume_id,chest,waist,hip,height_min,height_max,shoe_size,updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+		ON CONFLICT (costume_id) DO UPDATE SET chest = EXCLUDED.chest, waist = EXCLUDED.waist, hip = EXCLUDED.hip,
+			height_min = EXCLUDED.height_min, height_max = EXCLUDED.height_max, shoe_size = EXCLUDED.shoe_size, updated_at = EXCLUDED.updated_at`
+
+	_, err := tx.ExecContext(ctx, query, measurement.Costume_id, measurement.Chest, measurement.Waist, measurement.Hip, measurement.Height_min, measurement.Height_max, measurement.Shoe_size, measurement.Updated_at)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+// FindByCostumeIds loads the measurements of a whole result page in one query, costumes without any are left out.
+func (repository *CostumeMeasurementRepository) FindByCostumeIds(ctx context.Context, tx *sql.Tx, costumeIds []int) map[int]domain.CostumeMeasurement {
+	measurements := map[int]domain.CostumeMeasurement{}
+	if len(costumeIds) == 0 {
+		return measurements
+	}
+
+	query := "SELECT costume_id,chest,waist,hip,height_min,height_max,shoe_size,updated_at FROM costume_measurements WHERE costume_id = ANY($1)"
+	rows, err := tx.QueryContext(ctx, query, costumeIds)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		measurement := domain.CostumeMeasurement{}
+		err = rows.Scan(&measurement.Costume_id, &measurement.Chest, &measurement.Waist, &measurement.Hip, &measurement.Height_min, &measurement.Height_max, &measurement.Shoe_size, &measurement.Updated_at)
+		if err != nil {
+			respErr := errors.New("failed to scan query result")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		measurements[measurement.Costume_id] = measurement
+	}
+
+	return measurements
+}
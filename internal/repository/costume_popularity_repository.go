@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+type CostumePopularityRepository struct {
+	Log *zerolog.Logger
+}
+
+func NewCostumePopularityRepository(zerolog *zerolog.Logger) *CostumePopularityRepository {
+	return &CostumePopularityRepository{
+		Log: zerolog,
+	}
+}
+
+// Refresh rescores every costume from its events since the given time, each event counts for its weight halved every
+// halfLife and a user or anonymous visitor only counts once per costume and event type. Costumes left without events
+// drop out of the ranking.
+func (repository *CostumePopularityRepository) Refresh(ctx context.Context, tx *sql.Tx, now time.Time, since time.Time, halfLife time.Duration, weights map[string]float64) {
+	query := `WITH weights AS (
+		SELECT * FROM unnest($4::text[], $5::float8[]) AS w(type, weight)
+	), scores AS (
+		SELECT e.costume_id, SUM(w.weight * power(0.5, EXTRACT(EPOCH FROM ($1::timestamp - e.created_at))::float8 / $3))::float8 AS score
+		FROM (
+			SELECT DISTINCT ON (costume_id, type, COALESCE(user_id, visitor_id, id::text)) costume_id, type, created_at
+			FROM costume_events
+			WHERE created_at >= $2
+			ORDER BY costume_id, type, COALESCE(user_id, visitor_id, id::text), created_at DESC
+		) e
+		JOIN weights w ON w.type = e.type
+		GROUP BY e.costume_id
+	), upserted AS (
+		INSERT INTO costume_popularities (costume_id, score, updated_at)
+		SELECT costume_id, score, $1 FROM scores
+		ON CONFLICT (costume_id) DO UPDATE SET score = EXCLUDED.score, updated_at = EXCLUDED.updated_at
+	)
+	DELETE FROM costume_popularities WHERE costume_id NOT IN (SELECT costume_id FROM scores)`
+
+	types := make([]string, 0, len(weights))
+	values := make([]float64, 0, len(weights))
+	for eventType, weight := range weights {
+		types = append(types, eventType)
+		values = append(values, weight)
+	}
+
+	_, err := tx.ExecContext(ctx, query, now, since, halfLife.Seconds(), types, values)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"cosplayrent/internal/model/domain"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/rs/zerolog"
+)
+
+type CostumePricingRepository struct {
+	Log *zerolog.Logger
+}
+
+func NewCostumePricingRepository(zerolog *zerolog.Logger) *CostumePricingRepository {
+	return &CostumePricingRepository{
+		Log: zerolog,
+	}
+}
+
+func (repository *CostumePricingRepository) Save(ctx context.Context, tx *sql.Tx, pricing domain.CostumePricing) {
+	query := `INSERT INTO costume_pricing (costume_id,min_days,max_days,weekend_surcharge_percent,discounts,date_rules,updated_at) VALUES ($1,$2,$3,$4,$5::jsonb,$6::jsonb,$7)
+		ON CONFLICT (costume_id) DO UPDATE SET min_days = EXCLUDED.min_days, max_days = EXCLUDED.max_days, weekend_surcharge_percent = EXCLUDED.weekend_surcharge_percent,
+			discounts = EXCLUDED.discounts, date_rules = EXCLUDED.date_rules, updated_at = EXCLUDED.updated_at`
+
+	discounts, _ := json.Marshal(pricing.Discounts)
+	dateRules, _ := json.Marshal(pricing.Date_rules)
+	_, err := tx.ExecContext(ctx, query, pricing.Costume_id, pricing.Min_days, pricing.Max_days, pricing.Weekend_surcharge, string(discounts), string(dateRules), pricing.Updated_at)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *CostumePricingRepository) FindByCostumeId(ctx context.Context, tx *sql.Tx, costumeId int) (domain.CostumePricing, error) {
+	query := "SELECT costume_id,min_days,max_days,weekend_surcharge_percent,discounts,date_rules,updated_at FROM costume_pricing WHERE costume_id = $1"
+
+	pricing := domain.CostumePricing{}
+	var discounts, dateRules []byte
+	err := tx.QueryRowContext(ctx, query, costumeId).Scan(&pricing.Costume_id, &pricing.Min_days, &pricing.Max_days, &pricing.Weekend_surcharge, &discounts, &dateRules, &pricing.Updated_at)
+	if err == sql.ErrNoRows {
+		return pricing, errors.New("pricing not found")
+	}
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	err = json.Unmarshal(discounts, &pricing.Discounts)
+	if err == nil {
+		err = json.Unmarshal(dateRules, &pricing.Date_rules)
+	}
+	if err != nil {
+		respErr := errors.New("failed to decode pricing rules")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return pricing, nil
+}
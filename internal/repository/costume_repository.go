@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/rs/zerolog"
+	"slices"
 	"time"
 )
 
@@ -21,76 +22,36 @@ func NewCostumeRepository(zerolog *zerolog.Logger) *CostumeRepository {
 	}
 }
 
-func (repository *CostumeRepository) Create(ctx context.Context, tx *sql.Tx, costume domain.Costume) {
-	query := "INSERT INTO costumes (user_id,name,description,material,size,weight,category_id,price,costume_picture,created_at,updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)"
-	_, err := tx.ExecContext(ctx, query, costume.User_id, costume.Name, costume.Description, costume.Bahan, costume.Ukuran, costume.Berat, costume.Kategori, costume.Price, costume.Picture, costume.Created_at, costume.Updated_at)
+func (repository *CostumeRepository) Create(ctx context.Context, tx *sql.Tx, costume domain.Costume) int {
+	query := "INSERT INTO costumes (user_id,name,description,material,size,weight,category_id,price,costume_picture,status,created_at,updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12) RETURNING id"
+	var id int
+	err := tx.QueryRowContext(ctx, query, costume.User_id, costume.Name, costume.Description, costume.Bahan, costume.Ukuran, costume.Berat, costume.Kategori, costume.Price, costume.Picture, costume.Status, costume.Created_at, costume.Updated_at).Scan(&id)
 	if err != nil {
 		respErr := errors.New("failed to query into database")
 		repository.Log.Panic().Err(err).Msg(respErr.Error())
 	}
+
+	return id
 }
 
-func (repository *CostumeRepository) Update(ctx context.Context, tx *sql.Tx, costume domain.Costume) {
+func (repository *CostumeRepository) Update(ctx context.Context, tx *sql.Tx, costumeRequest costume.CostumeUpdateRequest, updatedAt *time.Time) {
 	query := "UPDATE costumes SET "
 	args := []interface{}{}
-	argCounter := 1
-
-	if costume.Name != "" {
-		query += fmt.Sprintf("name = $%d, ", argCounter)
-		args = append(args, costume.Name)
-		argCounter++
-	}
-	if costume.Description != "" {
-		query += fmt.Sprintf("description = $%d, ", argCounter)
-		args = append(args, costume.Description)
-		argCounter++
-	}
-	if costume.Bahan != "" {
-		query += fmt.Sprintf("material = $%d, ", argCounter)
-		args = append(args, costume.Bahan)
-		argCounter++
-	}
-	if costume.Ukuran != "" {
-		query += fmt.Sprintf("size = $%d, ", argCounter)
-		args = append(args, costume.Ukuran)
-		argCounter++
-	}
-	if costume.Berat != 0 {
-		query += fmt.Sprintf("weight = $%d, ", argCounter)
-		args = append(args, costume.Berat)
-		argCounter++
-	}
-
-	fmt.Println("kategori id:", costume.Kategori)
-	if costume.Kategori != 0 {
-		query += fmt.Sprintf("category_id = $%d, ", argCounter)
-		args = append(args, costume.Kategori)
-		argCounter++
-	}
-	if costume.Price != 0 {
-		query += fmt.Sprintf("price = $%d, ", argCounter)
-		args = append(args, costume.Price)
-		argCounter++
-	}
-	if costume.Picture != "" {
-		query += fmt.Sprintf("costume_picture = $%d, ", argCounter)
-		args = append(args, costume.Picture)
-		argCounter++
-	}
-	if costume.Available != "" {
-		query += fmt.Sprintf("available = $%d, ", argCounter)
-		args = append(args, costume.Available)
-		argCounter++
-	}
 
-	fmt.Println("avail:", costume.Available)
+	query, args = appendPatchField(query, args, "name", costumeRequest.Name)
+	query, args = appendPatchField(query, args, "description", costumeRequest.Description)
+	query, args = appendPatchField(query, args, "material", costumeRequest.Bahan)
+	query, args = appendPatchField(query, args, "size", costumeRequest.Ukuran)
+	query, args = appendPatchField(query, args, "weight", costumeRequest.Berat)
+	query, args = appendPatchField(query, args, "category_id", costumeRequest.Kategori)
+	query, args = appendPatchField(query, args, "price", costumeRequest.Price)
+	query, args = appendPatchField(query, args, "costume_picture", costumeRequest.Picture)
 
-	query += fmt.Sprintf("updated_at = $%d ", argCounter)
-	args = append(args, costume.Updated_at)
-	argCounter++
+	args = append(args, updatedAt)
+	query += fmt.Sprintf("updated_at = $%d ", len(args))
 
-	query += fmt.Sprintf("WHERE id = $%d", argCounter)
-	args = append(args, costume.Id)
+	args = append(args, costumeRequest.Id)
+	query += fmt.Sprintf("WHERE id = $%d", len(args))
 
 	_, err := tx.ExecContext(ctx, query, args...)
 	if err != nil {
@@ -118,7 +79,7 @@ func (repository *CostumeRepository) CheckOwnership(ctx context.Context, tx *sql
 }
 
 func (repository *CostumeRepository) CheckCostume(ctx context.Context, tx *sql.Tx, userUUID string, costumeid int) error {
-	query := "SELECT id FROM costumes WHERE id=$1 AND user_id=$2"
+	query := "SELECT id FROM costumes WHERE id=$1 AND user_id=$2 AND status <> 'archived'"
 	row, err := tx.QueryContext(ctx, query, costumeid, userUUID)
 
 	if err != nil {
@@ -196,14 +157,133 @@ func (repository *CostumeRepository) FindSellerIdFindByCostumeID(ctx context.Con
 	}
 }
 
-func (repository *CostumeRepository) FindAll(ctx context.Context, tx *sql.Tx) ([]costume.CostumeResponse, error) {
-	query := "SELECT id,user_id,name,description,material,size,weight,category_id,price,costume_picture,available,created_at, updated_at FROM costumes where available='Ready'"
-	rows, err := tx.QueryContext(ctx, query)
+type catalogSort struct {
+	column    string
+	cast      string
+	direction string
+}
+
+var catalogSorts = map[string]catalogSort{
+	"newest":     {column: "created_at", cast: "timestamp", direction: "DESC"},
+	"price_asc":  {column: "price", cast: "numeric", direction: "ASC"},
+	"price_desc": {column: "price", cast: "numeric", direction: "DESC"},
+	"rating":     {column: "rating", cast: "float8", direction: "DESC"},
+	"popularity": {column: "popularity", cast: "bigint", direction: "DESC"},
+	"trending":   {column: "trending", cast: "float8", direction: "DESC"},
+}
+
+// costumeTsQuery ORs the same user input parsed by every text search config used in costumes.search_vector.
+const costumeTsQuery = "(websearch_to_tsquery('simple', %[1]s) || websearch_to_tsquery('english', %[1]s) || websearch_to_tsquery('indonesian', %[1]s))"
+
+// variantInStock holds for a variant v with a unit free today.
+var variantInStock = "v.quantity > " + fmt.Sprintf(variantBookedOn, "CURRENT_DATE")
+
+// sellerOnVacation holds while seller u's vacation window covers today, so the listings come back on their own after it.
+const sellerOnVacation = "COALESCE(u.vacation_start <= CURRENT_DATE AND u.vacation_end >= CURRENT_DATE, false)"
+
+// FindAll returns one page of published costumes plus one extra row, so the caller can tell whether a next page exists.
+func (repository *CostumeRepository) FindAll(ctx context.Context, tx *sql.Tx, searchRequest costume.CostumeSearchRequest) []costume.CostumeResponse {
+	sort := catalogSorts[searchRequest.Sort]
+
+	query := `WITH catalog AS (
+		SELECT c.id, c.user_id, u.name AS username, c.name, c.description, c.material, c.size, c.weight, c.category_id, c.price, c.costume_picture, c.status, c.created_at, c.updated_at,
+			u.origincity_id, u.originprovince_id, c.search_vector,
+			COALESCE((SELECT AVG(r.rating) FROM reviews r WHERE r.costume_id = c.id), 0)::float8 AS rating,
+			(SELECT COUNT(*) FROM orders o WHERE o.costume_id = c.id) AS popularity,
+			COALESCE(p.score, 0)::float8 AS trending
+		FROM costumes c
+		JOIN users u ON u.id = c.user_id
+		LEFT JOIN costume_popularities p ON p.costume_id = c.id
+		WHERE c.status = 'published' AND NOT ` + sellerOnVacation + `
+			AND EXISTS (SELECT 1 FROM costume_variants v WHERE v.costume_id = c.id AND ` + variantInStock + `)
+	)
+	SELECT id, user_id, username, name, description, material, size, weight, category_id, price, costume_picture, status, created_at, updated_at, ` + sort.column + `::text
+	FROM catalog WHERE 1=1`
+	args := []interface{}{}
+
+	if searchRequest.Q != "" {
+		args = append(args, searchRequest.Q, likeEscaper.Replace(searchRequest.Q))
+		placeholder := fmt.Sprintf("$%d", len(args)-1)
+		query += fmt.Sprintf(` AND (search_vector @@ %s OR name ILIKE '%%' || $%d || '%%' ESCAPE '\')`, fmt.Sprintf(costumeTsQuery, placeholder), len(args))
+	}
+	if searchRequest.Kategori != 0 {
+		args = append(args, searchRequest.Kategori)
+		// filtering by a parent category also matches costumes filed under its subcategories
+		query += fmt.Sprintf(` AND category_id IN (
+			WITH RECURSIVE subtree AS (
+				SELECT id FROM categories WHERE id = $%d
+				UNION
+				SELECT cat.id FROM categories cat JOIN subtree s ON cat.parent_id = s.id
+			)
+			SELECT id FROM subtree)`, len(args))
+	}
+	attributeKeys := make([]string, 0, len(searchRequest.Attributes))
+	for key := range searchRequest.Attributes {
+		attributeKeys = append(attributeKeys, key)
+	}
+	slices.Sort(attributeKeys)
+	for _, key := range attributeKeys {
+		args = append(args, key, searchRequest.Attributes[key])
+		// the value matches a scalar attribute case-insensitively, or any element of a multi_enum attribute
+		query += fmt.Sprintf(` AND EXISTS (
+			SELECT 1 FROM costume_attributes ca
+			JOIN category_attributes d ON d.id = ca.attribute_id
+			WHERE ca.costume_id = catalog.id AND d.key = $%[1]d AND (
+				CASE jsonb_typeof(ca.value)
+					WHEN 'array' THEN EXISTS (SELECT 1 FROM jsonb_array_elements_text(ca.value) v WHERE lower(v) = lower($%[2]d))
+					ELSE lower(ca.value #>> '{}') = lower($%[2]d)
+				END))`, len(args)-1, len(args))
+	}
+	if searchRequest.Ukuran != "" {
+		args = append(args, searchRequest.Ukuran)
+		// a listing matches when any variant still in stock has the size
+		query += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM costume_variants v WHERE v.costume_id = catalog.id AND v.size ILIKE $%d AND %s)", len(args), variantInStock)
+	}
+	if searchRequest.Bahan != "" {
+		args = append(args, searchRequest.Bahan)
+		query += fmt.Sprintf(" AND material ILIKE $%d", len(args))
+	}
+	if searchRequest.Min_price != nil {
+		args = append(args, *searchRequest.Min_price)
+		query += fmt.Sprintf(" AND price >= $%d", len(args))
+	}
+	if searchRequest.Max_price != nil {
+		args = append(args, *searchRequest.Max_price)
+		query += fmt.Sprintf(" AND price <= $%d", len(args))
+	}
+	if searchRequest.City_id != 0 {
+		args = append(args, searchRequest.City_id)
+		query += fmt.Sprintf(" AND origincity_id = $%d", len(args))
+	}
+	if searchRequest.Province_id != 0 {
+		args = append(args, searchRequest.Province_id)
+		query += fmt.Sprintf(" AND originprovince_id = $%d", len(args))
+	}
+	if searchRequest.Min_rating > 0 {
+		args = append(args, searchRequest.Min_rating)
+		query += fmt.Sprintf(" AND rating >= $%d", len(args))
+	}
+	if searchRequest.Seller_id != "" {
+		args = append(args, searchRequest.Seller_id)
+		query += fmt.Sprintf(" AND user_id = $%d", len(args))
+	}
+	if searchRequest.After_value != nil {
+		operator := "<"
+		if sort.direction == "ASC" {
+			operator = ">"
+		}
+		args = append(args, *searchRequest.After_value, searchRequest.After_id)
+		query += fmt.Sprintf(" AND (%s, id) %s ($%d::%s, $%d)", sort.column, operator, len(args)-1, sort.cast, len(args))
+	}
+
+	args = append(args, searchRequest.Limit+1)
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT $%d", sort.column, sort.direction, sort.direction, len(args))
+
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
 		respErr := errors.New("failed to query into database")
 		repository.Log.Panic().Err(err).Msg(respErr.Error())
 	}
-	hasData := false
 
 	defer rows.Close()
 
@@ -212,7 +292,7 @@ func (repository *CostumeRepository) FindAll(ctx context.Context, tx *sql.Tx) ([
 	var updatedAt time.Time
 	for rows.Next() {
 		costume := costume.CostumeResponse{}
-		err = rows.Scan(&costume.Id, &costume.User_id, &costume.Name, &costume.Description, &costume.Bahan, &costume.Ukuran, &costume.Berat, &costume.Kategori, &costume.Price, &costume.Picture, &costume.Available, &createdAt, &updatedAt)
+		err = rows.Scan(&costume.Id, &costume.User_id, &costume.Username, &costume.Name, &costume.Description, &costume.Bahan, &costume.Ukuran, &costume.Berat, &costume.Kategori, &costume.Price, &costume.Picture, &costume.Status, &createdAt, &updatedAt, &costume.Sort_value)
 		if err != nil {
 			respErr := errors.New("failed to scan query result")
 			repository.Log.Panic().Err(err).Msg(respErr.Error())
@@ -220,17 +300,171 @@ func (repository *CostumeRepository) FindAll(ctx context.Context, tx *sql.Tx) ([
 		costume.Created_at = createdAt.Format("2006-01-02 15:04:05")
 		costume.Updated_at = updatedAt.Format("2006-01-02 15:04:05")
 		costumes = append(costumes, costume)
-		hasData = true
 	}
-	if hasData == false {
-		return costumes, errors.New("costume not found")
+
+	return costumes
+}
+
+// costumeAttributeValues lists the lowercased values a costume carries under the attribute keys in $2, each element of a
+// multi_enum attribute on its own row.
+const costumeAttributeValues = `SELECT d.key, lower(x.value) AS value
+	FROM costume_attributes ca
+	JOIN category_attributes d ON d.id = ca.attribute_id
+	CROSS JOIN LATERAL jsonb_array_elements_text(CASE jsonb_typeof(ca.value) WHEN 'array' THEN ca.value ELSE jsonb_build_array(ca.value #>> '{}') END) AS x(value)
+	WHERE ca.costume_id = %s AND d.key = ANY($2)`
+
+// FindSimilar ranks published costumes against the given one. The same category or a neighbour in its tree, shared
+// attribute values and being wishlisted or rented by the same users make a candidate relevant, a shared size, a price
+// within 30% and the seller's city only add to the score of relevant ones.
+func (repository *CostumeRepository) FindSimilar(ctx context.Context, tx *sql.Tx, costumeId int, attributeKeys []string, excludeUnavailable bool, excludeSameSeller bool, limit int) []costume.CostumeResponse {
+	query := `WITH source AS (
+		SELECT c.id, c.user_id, c.category_id, cat.parent_id, c.price, u.origincity_id
+		FROM costumes c
+		JOIN users u ON u.id = c.user_id
+		JOIN categories cat ON cat.id = c.category_id
+		WHERE c.id = $1
+	), source_sizes AS (
+		SELECT DISTINCT lower(size) AS size FROM costume_variants WHERE costume_id = $1
+	), source_attributes AS (
+		` + fmt.Sprintf(costumeAttributeValues, "$1") + `
+	), co_wishlists AS (
+		SELECT w2.costume_id, COUNT(DISTINCT w2.user_id) AS users
+		FROM wishlists w1
+		JOIN wishlists w2 ON w2.user_id = w1.user_id AND w2.costume_id <> w1.costume_id
+		WHERE w1.costume_id = $1
+		GROUP BY w2.costume_id
+	), co_rentals AS (
+		SELECT o2.costume_id, COUNT(DISTINCT o2.customer_id) AS users
+		FROM orders o1
+		JOIN orders o2 ON o2.customer_id = o1.customer_id AND o2.costume_id <> o1.costume_id
+		WHERE o1.costume_id = $1
+		GROUP BY o2.costume_id
+	), candidates AS (
+		SELECT c.id, c.user_id, u.name AS username, c.name, c.description, c.material, c.size, c.weight, c.category_id, c.price, c.costume_picture, c.status, c.created_at, c.updated_at,
+			CASE
+				WHEN c.category_id = s.category_id THEN 3
+				WHEN c.category_id = s.parent_id OR cat.parent_id = s.category_id OR cat.parent_id = s.parent_id THEN 1.5
+				ELSE 0
+			END AS category_score,
+			2 * (SELECT COUNT(DISTINCT a.key) FROM (` + fmt.Sprintf(costumeAttributeValues, "c.id") + `) a
+				JOIN source_attributes sa ON sa.key = a.key AND sa.value = a.value) AS attribute_score,
+			0.5 * LEAST(COALESCE(cw.users, 0), 6) + LEAST(COALESCE(cr.users, 0), 6) AS signal_score,
+			CASE WHEN EXISTS (SELECT 1 FROM costume_variants v WHERE v.costume_id = c.id AND lower(v.size) IN (SELECT size FROM source_sizes)) THEN 1 ELSE 0 END
+				+ CASE WHEN c.price BETWEEN s.price * 0.7 AND s.price * 1.3 THEN 1 ELSE 0 END
+				+ CASE WHEN u.origincity_id = s.origincity_id THEN 0.5 ELSE 0 END AS bonus_score
+		FROM costumes c
+		CROSS JOIN source s
+		JOIN users u ON u.id = c.user_id
+		JOIN categories cat ON cat.id = c.category_id
+		LEFT JOIN co_wishlists cw ON cw.costume_id = c.id
+		LEFT JOIN co_rentals cr ON cr.costume_id = c.id
+		WHERE c.id <> s.id AND c.status = 'published'`
+	args := []interface{}{costumeId, attributeKeys}
+
+	if excludeUnavailable {
+		query += ` AND NOT ` + sellerOnVacation + `
+			AND EXISTS (SELECT 1 FROM costume_variants v WHERE v.costume_id = c.id AND ` + variantInStock + `)`
+	}
+	if excludeSameSeller {
+		query += " AND c.user_id <> s.user_id"
+	}
+
+	args = append(args, limit)
+	query += fmt.Sprintf(`
+	)
+	SELECT id, user_id, username, name, description, material, size, weight, category_id, price, costume_picture, status, created_at, updated_at
+	FROM candidates
+	WHERE category_score > 0 OR attribute_score > 0 OR signal_score > 0
+	ORDER BY category_score + attribute_score + signal_score + bonus_score DESC, id DESC
+	LIMIT $%d`, len(args))
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
 	}
 
-	return costumes, nil
+	defer rows.Close()
+
+	costumes := []costume.CostumeResponse{}
+	var createdAt time.Time
+	var updatedAt time.Time
+	for rows.Next() {
+		costume := costume.CostumeResponse{}
+		err = rows.Scan(&costume.Id, &costume.User_id, &costume.Username, &costume.Name, &costume.Description, &costume.Bahan, &costume.Ukuran, &costume.Berat, &costume.Kategori, &costume.Price, &costume.Picture, &costume.Status, &createdAt, &updatedAt)
+		if err != nil {
+			respErr := errors.New("failed to scan query result")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		costume.Created_at = createdAt.Format("2006-01-02 15:04:05")
+		costume.Updated_at = updatedAt.Format("2006-01-02 15:04:05")
+		costumes = append(costumes, costume)
+	}
+
+	return costumes
+}
+
+// htmlEscaped escapes the text in %s for HTML. The highlights are rendered as markup, so seller text is escaped before
+// ts_headline adds the <mark> tags.
+const htmlEscaped = `replace(replace(replace(replace(replace(%s, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`
+
+// Search ranks full-text matches on search_vector together with trigram similarity on the name, so misspelled character names still match.
+func (repository *CostumeRepository) Search(ctx context.Context, tx *sql.Tx, searchRequest costume.CostumeTextSearchRequest) []costume.CostumeSearchResponse {
+	_, err := tx.ExecContext(ctx, "SET LOCAL pg_trgm.word_similarity_threshold = 0.4")
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	query := `WITH q AS (
+		SELECT ` + fmt.Sprintf(costumeTsQuery, "$1") + ` AS query
+	), matches AS (
+		SELECT c.id, c.user_id, u.name AS username, c.name, c.description, COALESCE(cat.name, '') AS category_name, c.material, c.price, c.costume_picture,
+			(ts_rank(c.search_vector, q.query) + word_similarity($1, c.name))::float8 AS rank
+		FROM costumes c
+		CROSS JOIN q
+		JOIN users u ON u.id = c.user_id
+		LEFT JOIN categories cat ON cat.id = c.category_id
+		WHERE c.status = 'published' AND (c.search_vector @@ q.query OR $1 <% c.name)
+			AND NOT ` + sellerOnVacation + `
+			AND EXISTS (SELECT 1 FROM costume_variants v WHERE v.costume_id = c.id AND ` + variantInStock + `)
+	), page AS (
+		SELECT * FROM matches
+		WHERE $2::float8 IS NULL OR (rank, id) < ($2::float8, $3)
+		ORDER BY rank DESC, id DESC
+		LIMIT $4
+	)
+	SELECT page.id, page.user_id, page.username, page.name,
+		ts_headline('simple', ` + fmt.Sprintf(htmlEscaped, "page.name") + `, q.query, 'HighlightAll=true, StartSel=<mark>, StopSel=</mark>'),
+		ts_headline('english', ` + fmt.Sprintf(htmlEscaped, "page.description") + `, q.query, 'StartSel=<mark>, StopSel=</mark>, MaxWords=30, MinWords=10, MaxFragments=2'),
+		page.category_name, page.material, page.price, page.costume_picture, page.rank::text
+	FROM page CROSS JOIN q
+	ORDER BY page.rank DESC, page.id DESC`
+
+	rows, err := tx.QueryContext(ctx, query, searchRequest.Q, searchRequest.After_rank, searchRequest.After_id, searchRequest.Limit+1)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer rows.Close()
+
+	costumes := []costume.CostumeSearchResponse{}
+	for rows.Next() {
+		costume := costume.CostumeSearchResponse{}
+		err = rows.Scan(&costume.Id, &costume.User_id, &costume.Username, &costume.Name, &costume.Name_highlight, &costume.Snippet, &costume.Kategori, &costume.Bahan, &costume.Price, &costume.Picture, &costume.Rank)
+		if err != nil {
+			respErr := errors.New("failed to scan query result")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		costumes = append(costumes, costume)
+	}
+
+	return costumes
 }
 
 func (repository *CostumeRepository) FindSellerCostume(ctx context.Context, tx *sql.Tx, uuid string) ([]costume.SellerCostumeResponse, error) {
-	query := "SELECT id,user_id,name,description,material,size,weight,category_id,price,costume_picture,available,created_at, updated_at FROM costumes where user_id=$1"
+	query := "SELECT id,user_id,name,description,material,size,weight,category_id,price,costume_picture,status,created_at, updated_at FROM costumes where user_id=$1 AND status <> 'archived'"
 	rows, err := tx.QueryContext(ctx, query, uuid)
 	if err != nil {
 		respErr := errors.New("failed to query into database")
@@ -245,7 +479,7 @@ func (repository *CostumeRepository) FindSellerCostume(ctx context.Context, tx *
 	var updatedAt time.Time
 	for rows.Next() {
 		costume := costume.SellerCostumeResponse{}
-		err = rows.Scan(&costume.Id, &costume.User_id, &costume.Name, &costume.Description, &costume.Bahan, &costume.Ukuran, &costume.Berat, &costume.Kategori, &costume.Price, &costume.Picture, &costume.Available, &createdAt, &updatedAt)
+		err = rows.Scan(&costume.Id, &costume.User_id, &costume.Name, &costume.Description, &costume.Bahan, &costume.Ukuran, &costume.Berat, &costume.Kategori, &costume.Price, &costume.Picture, &costume.Status, &createdAt, &updatedAt)
 		if err != nil {
 			respErr := errors.New("failed to scan query result")
 			repository.Log.Panic().Err(err).Msg(respErr.Error())
@@ -263,7 +497,7 @@ func (repository *CostumeRepository) FindSellerCostume(ctx context.Context, tx *
 }
 
 func (repository *CostumeRepository) FindById(ctx context.Context, tx *sql.Tx, id int) (costume.CostumeResponse, error) {
-	query := "SELECT id,user_id,name,description,material,size,weight,category_id,price,costume_picture,available,created_at, updated_at FROM costumes where id=$1"
+	query := "SELECT id,user_id,name,description,material,size,weight,category_id,price,costume_picture,status,created_at, updated_at FROM costumes where id=$1"
 	rows, err := tx.QueryContext(ctx, query, id)
 	if err != nil {
 		respErr := errors.New("failed to query into database")
@@ -278,7 +512,7 @@ func (repository *CostumeRepository) FindById(ctx context.Context, tx *sql.Tx, i
 	if rows.Next() {
 		err := rows.Scan(&costumes.Id, &costumes.User_id, &costumes.Name, &costumes.Description,
 			&costumes.Bahan, &costumes.Ukuran, &costumes.Berat, &costumes.Kategori_id, &costumes.Price,
-			&costumes.Picture, &costumes.Available, &createdAt, &updatedAt)
+			&costumes.Picture, &costumes.Status, &createdAt, &updatedAt)
 		if err != nil {
 			respErr := errors.New("failed to scan query result")
 			repository.Log.Panic().Err(err).Msg(respErr.Error())
@@ -292,7 +526,7 @@ func (repository *CostumeRepository) FindById(ctx context.Context, tx *sql.Tx, i
 }
 
 func (repository *CostumeRepository) FindSellerCostumeByCostumeID(ctx context.Context, tx *sql.Tx, userUUID string, costumeID int) (costume.CostumeResponse, error) {
-	query := "SELECT id,user_id,name,description,material,size,weight,category_id,price,costume_picture,available,created_at,updated_at FROM costumes WHERE user_id = $1 AND id = $2"
+	query := "SELECT id,user_id,name,description,material,size,weight,category_id,price,costume_picture,status,created_at,updated_at FROM costumes WHERE user_id = $1 AND id = $2"
 	rows, err := tx.QueryContext(ctx, query, userUUID, costumeID)
 	if err != nil {
 		respErr := errors.New("failed to query into database")
@@ -305,7 +539,7 @@ func (repository *CostumeRepository) FindSellerCostumeByCostumeID(ctx context.Co
 	var createdAt time.Time
 	var updatedAt time.Time
 	if rows.Next() {
-		err = rows.Scan(&costume.Id, &costume.User_id, &costume.Name, &costume.Description, &costume.Bahan, &costume.Ukuran, &costume.Berat, &costume.Kategori, &costume.Price, &costume.Picture, &costume.Available, &createdAt, &updatedAt)
+		err = rows.Scan(&costume.Id, &costume.User_id, &costume.Name, &costume.Description, &costume.Bahan, &costume.Ukuran, &costume.Berat, &costume.Kategori, &costume.Price, &costume.Picture, &costume.Status, &createdAt, &updatedAt)
 		if err != nil {
 			respErr := errors.New("failed to scan query result")
 			repository.Log.Panic().Err(err).Msg(respErr.Error())
@@ -364,9 +598,53 @@ func (repository *CostumeRepository) FindCostumeInfoById(ctx context.Context, tx
 	}
 }
 
-func (repository *CostumeRepository) Delete(ctx context.Context, tx *sql.Tx, id int, uuid string) {
-	query := "DELETE FROM costumes WHERE id=$1"
-	_, err := tx.ExecContext(ctx, query, id)
+// Archive stands in for deleting a listing, the row stays so orders and reviews keep pointing at it.
+func (repository *CostumeRepository) Archive(ctx context.Context, tx *sql.Tx, id int, archivedAt *time.Time) {
+	query := "UPDATE costumes SET status='archived', archived_at=$1, updated_at=$1 WHERE id=$2 AND status <> 'archived'"
+	_, err := tx.ExecContext(ctx, query, archivedAt, id)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *CostumeRepository) UpdateStatus(ctx context.Context, tx *sql.Tx, id int, status string, updatedAt *time.Time) {
+	query := "UPDATE costumes SET status=$1, updated_at=$2 WHERE id=$3"
+	_, err := tx.ExecContext(ctx, query, status, updatedAt, id)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *CostumeRepository) SuggestNames(ctx context.Context, tx *sql.Tx, prefix string, limit int) []string {
+	query := `SELECT name FROM (
+		SELECT DISTINCT ON (lower(name)) name, name ILIKE $1 AS is_prefix, similarity(name, $2) AS score
+		FROM costumes
+		WHERE status = 'published' AND (name ILIKE $1 OR $2 <% name)
+		ORDER BY lower(name), is_prefix DESC, score DESC
+	) suggestions ORDER BY is_prefix DESC, score DESC, name LIMIT $3`
+
+	return findSuggestions(ctx, tx, repository.Log, query, prefix, limit)
+}
+
+func (repository *CostumeRepository) FindTurnaroundDays(ctx context.Context, tx *sql.Tx, id int) int {
+	query := "SELECT turnaround_days FROM costumes WHERE id = $1"
+
+	var turnaroundDays int
+	err := tx.QueryRowContext(ctx, query, id).Scan(&turnaroundDays)
+	if err != nil && err != sql.ErrNoRows {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return turnaroundDays
+}
+
+func (repository *CostumeRepository) UpdateTurnaround(ctx context.Context, tx *sql.Tx, id int, turnaroundDays int, updatedAt *time.Time) {
+	query := "UPDATE costumes SET turnaround_days = $1, updated_at = $2 WHERE id = $3"
+
+	_, err := tx.ExecContext(ctx, query, turnaroundDays, updatedAt, id)
 	if err != nil {
 		respErr := errors.New("failed to query into database")
 		repository.Log.Panic().Err(err).Msg(respErr.Error())
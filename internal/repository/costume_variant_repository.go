@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"cosplayrent/internal/model/domain"
+	"cosplayrent/internal/model/web/costume"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+type CostumeVariantRepository struct {
+	Log *zerolog.Logger
+}
+
+func NewCostumeVariantRepository(zerolog *zerolog.Logger) *CostumeVariantRepository {
+	return &CostumeVariantRepository{
+		Log: zerolog,
+	}
+}
+
+// variantBookedOn counts the orders holding a unit of variant v on the day given as the format argument. A dated order
+// holds it from its start date through the costume's turnaround days after its end date unless it was cancelled, an
+// order placed before rentals had dates holds it until its reservation is released.
+const variantBookedOn = `(SELECT COUNT(*) FROM orders o WHERE o.variant_id = v.id AND (
+	(o.start_date IS NULL AND NOT o.reservation_released)
+	OR (o.start_date <= %[1]s AND o.end_date + (SELECT tc.turnaround_days FROM costumes tc WHERE tc.id = v.costume_id) >= %[1]s
+		AND NOT EXISTS (SELECT 1 FROM order_events e WHERE e.order_id = o.id AND e.status = 'Cancelled'))))`
+
+// FindByCostumeIds loads the variants of a whole result page in one query, keyed by costume id.
+func (repository *CostumeVariantRepository) FindByCostumeIds(ctx context.Context, tx *sql.Tx, costumeIds []int) map[int][]domain.CostumeVariant {
+	variants := map[int][]domain.CostumeVariant{}
+	if len(costumeIds) == 0 {
+		return variants
+	}
+
+	query := "SELECT v.id,v.costume_id,v.size,v.quantity,v.reserved," + fmt.Sprintf(variantBookedOn, "CURRENT_DATE") + ",v.price,v.created_at,v.updated_at FROM costume_variants v WHERE v.costume_id = ANY($1) ORDER BY v.costume_id, v.id"
+	rows, err := tx.QueryContext(ctx, query, costumeIds)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		variant := domain.CostumeVariant{}
+		err = rows.Scan(&variant.Id, &variant.Costume_id, &variant.Size, &variant.Quantity, &variant.Reserved, &variant.Booked_today, &variant.Price, &variant.Created_at, &variant.Updated_at)
+		if err != nil {
+			respErr := errors.New("failed to scan query result")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		variants[variant.Costume_id] = append(variants[variant.Costume_id], variant)
+	}
+
+	return variants
+}
+
+// FindByIdForUpdate locks the variant row, so quantity changes cannot race with reservations.
+func (repository *CostumeVariantRepository) FindByIdForUpdate(ctx context.Context, tx *sql.Tx, costumeId int, id int) (domain.CostumeVariant, error) {
+	query := "SELECT id,costume_id,size,quantity,reserved,price,created_at,updated_at FROM costume_variants WHERE costume_id = $1 AND id = $2 FOR UPDATE"
+
+	variant := domain.CostumeVariant{}
+	err := tx.QueryRowContext(ctx, query, costumeId, id).Scan(&variant.Id, &variant.Costume_id, &variant.Size, &variant.Quantity, &variant.Reserved, &variant.Price, &variant.Created_at, &variant.Updated_at)
+	if err == sql.ErrNoRows {
+		return variant, errors.New("variant not found")
+	}
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return variant, nil
+}
+
+func (repository *CostumeVariantRepository) SizeExists(ctx context.Context, tx *sql.Tx, costumeId int, size string, exceptId int) bool {
+	query := "SELECT EXISTS (SELECT 1 FROM costume_variants WHERE costume_id = $1 AND lower(size) = lower($2) AND id <> $3)"
+
+	var exists bool
+	err := tx.QueryRowContext(ctx, query, costumeId, size, exceptId).Scan(&exists)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return exists
+}
+
+func (repository *CostumeVariantRepository) Create(ctx context.Context, tx *sql.Tx, variant domain.CostumeVariant) int {
+	query := "INSERT INTO costume_variants (costume_id,size,quantity,price,created_at,updated_at) VALUES ($1,$2,$3,$4,$5,$6) RETURNING id"
+
+	var id int
+	err := tx.QueryRowContext(ctx, query, variant.Costume_id, variant.Size, variant.Quantity, variant.Price, variant.Created_at, variant.Updated_at).Scan(&id)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return id
+}
+
+func (repository *CostumeVariantRepository) Update(ctx context.Context, tx *sql.Tx, variantRequest costume.CostumeVariantUpdateRequest, updatedAt *time.Time) {
+	query := "UPDATE costume_variants SET "
+	args := []interface{}{}
+
+	query, args = appendPatchField(query, args, "size", variantRequest.Size)
+	query, args = appendPatchField(query, args, "quantity", variantRequest.Quantity)
+	query, args = appendPatchField(query, args, "price", variantRequest.Price)
+
+	args = append(args, updatedAt)
+	query += fmt.Sprintf("updated_at = $%d ", len(args))
+
+	args = append(args, variantRequest.Id)
+	query += fmt.Sprintf("WHERE id = $%d", len(args))
+
+	_, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *CostumeVariantRepository) Delete(ctx context.Context, tx *sql.Tx, id int) {
+	_, err := tx.ExecContext(ctx, "DELETE FROM costume_variants WHERE id = $1", id)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+// Reserve counts a new order against the variant. Whether its dates are free is checked by the caller while holding
+// the row lock from FindByIdForUpdate.
+func (repository *CostumeVariantRepository) Reserve(ctx context.Context, tx *sql.Tx, costumeId int, id int) error {
+	query := "UPDATE costume_variants SET reserved = reserved + 1 WHERE costume_id = $1 AND id = $2"
+
+	result, err := tx.ExecContext(ctx, query, costumeId, id)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+	if affected == 0 {
+		return errors.New("variant not found")
+	}
+
+	return nil
+}
+
+// BookedUnits returns, per variant of the costume and per day from start to end, how many units orders hold.
+func (repository *CostumeVariantRepository) BookedUnits(ctx context.Context, tx *sql.Tx, costumeId int, start time.Time, end time.Time) map[int]map[string]int {
+	query := `SELECT v.id, d::date, ` + fmt.Sprintf(variantBookedOn, "d::date") + `
+		FROM costume_variants v
+		CROSS JOIN generate_series($2::date, $3::date, interval '1 day') d
+		WHERE v.costume_id = $1`
+	rows, err := tx.QueryContext(ctx, query, costumeId, start, end)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer rows.Close()
+
+	booked := map[int]map[string]int{}
+	for rows.Next() {
+		var variantId, count int
+		var day time.Time
+		err = rows.Scan(&variantId, &day, &count)
+		if err != nil {
+			respErr := errors.New("failed to scan query result")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		if booked[variantId] == nil {
+			booked[variantId] = map[string]int{}
+		}
+		booked[variantId][day.Format("2006-01-02")] = count
+	}
+
+	return booked
+}
+
+func (repository *CostumeVariantRepository) Release(ctx context.Context, tx *sql.Tx, id int) {
+	query := "UPDATE costume_variants SET reserved = reserved - 1 WHERE id = $1 AND reserved > 0"
+
+	_, err := tx.ExecContext(ctx, query, id)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
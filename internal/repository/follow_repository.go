@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"cosplayrent/internal/model/domain"
+	"database/sql"
+	"errors"
+	"github.com/rs/zerolog"
+)
+
+type FollowRepository struct {
+	Log *zerolog.Logger
+}
+
+func NewFollowRepository(zerolog *zerolog.Logger) *FollowRepository {
+	return &FollowRepository{
+		Log: zerolog,
+	}
+}
+
+// Create follows the seller, following a seller twice keeps the first follow.
+func (repository *FollowRepository) Create(ctx context.Context, tx *sql.Tx, follow domain.Follow) {
+	query := "INSERT INTO follows (follower_id,seller_id,created_at) VALUES ($1,$2,$3) ON CONFLICT DO NOTHING"
+	_, err := tx.ExecContext(ctx, query, follow.Follower_id, follow.Seller_id, follow.Created_at)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *FollowRepository) Delete(ctx context.Context, tx *sql.Tx, followerId string, sellerId string) {
+	query := "DELETE FROM follows WHERE follower_id = $1 AND seller_id = $2"
+	_, err := tx.ExecContext(ctx, query, followerId, sellerId)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *FollowRepository) Exists(ctx context.Context, tx *sql.Tx, followerId string, sellerId string) bool {
+	query := "SELECT 1 FROM follows WHERE follower_id = $1 AND seller_id = $2"
+
+	var exists int
+	err := tx.QueryRowContext(ctx, query, followerId, sellerId).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return false
+	}
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return true
+}
+
+// FindFollowers returns the id, name and email of every verified follower of the seller.
+func (repository *FollowRepository) FindFollowers(ctx context.Context, tx *sql.Tx, sellerId string) []domain.User {
+	query := "SELECT u.id,u.name,u.email FROM follows f JOIN users u ON u.id = f.follower_id WHERE f.seller_id = $1 AND u.is_verified = 'Yes' ORDER BY f.created_at"
+	rows, err := tx.QueryContext(ctx, query, sellerId)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer rows.Close()
+
+	followers := []domain.User{}
+	for rows.Next() {
+		follower := domain.User{}
+		err = rows.Scan(&follower.Id, &follower.Name, &follower.Email)
+		if err != nil {
+			respErr := errors.New("failed to scan query result")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		followers = append(followers, follower)
+	}
+
+	return followers
+}
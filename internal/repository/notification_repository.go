@@ -41,3 +41,27 @@ func (repository *NotificationRepository) FindNotificationTemplateById(ctx conte
 		return notification, errors.New("notification template not found")
 	}
 }
+
+func (repository *NotificationRepository) FindNotificationTemplateByName(ctx context.Context, tx *sql.Tx, templatename string) (domain.Notification, error) {
+	query := "SELECT template_subject,template_body FROM notifications WHERE template_name=$1"
+	row, err := tx.QueryContext(ctx, query, templatename)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer row.Close()
+
+	notification := domain.Notification{}
+
+	if row.Next() {
+		err = row.Scan(&notification.Template_subject, &notification.Template_body)
+		if err != nil {
+			respErr := errors.New("failed to scan query result")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		return notification, nil
+	} else {
+		return notification, errors.New("notification template not found")
+	}
+}
@@ -22,14 +22,32 @@ func NewOrderRepository(zerolog *zerolog.Logger) *OrderRepository {
 }
 
 func (repository *OrderRepository) Create(ctx context.Context, tx *sql.Tx, userRequest domain.Order) {
-	query := "INSERT INTO orders (id,customer_id,seller_id,costume_id,total,shipment_origin,shipment_destination,created_at,updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)"
-	_, err := tx.ExecContext(ctx, query, userRequest.Id, userRequest.Costumer_id, userRequest.Seller_id, userRequest.Costume_id, userRequest.Total_amount, userRequest.Shipment_origin, userRequest.Shipment_destination, userRequest.Created_at, userRequest.Updated_at)
+	query := "INSERT INTO orders (id,customer_id,seller_id,costume_id,variant_id,start_date,end_date,total,shipment_origin,shipment_destination,created_at,updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)"
+	_, err := tx.ExecContext(ctx, query, userRequest.Id, userRequest.Costumer_id, userRequest.Seller_id, userRequest.Costume_id, userRequest.Variant_id, userRequest.Start_date, userRequest.End_date, userRequest.Total_amount, userRequest.Shipment_origin, userRequest.Shipment_destination, userRequest.Created_at, userRequest.Updated_at)
 	if err != nil {
 		respErr := errors.New("failed to query into database")
 		repository.Log.Panic().Err(err).Msg(respErr.Error())
 	}
 }
 
+// ReleaseReservation marks the order's variant unit as given back and returns the variant to release, at most once
+// per order however many closing events arrive.
+func (repository *OrderRepository) ReleaseReservation(ctx context.Context, tx *sql.Tx, orderid string) (int, bool) {
+	query := "UPDATE orders SET reservation_released = true WHERE id = $1 AND variant_id IS NOT NULL AND NOT reservation_released RETURNING variant_id"
+
+	var variantId int
+	err := tx.QueryRowContext(ctx, query, orderid).Scan(&variantId)
+	if err == sql.ErrNoRows {
+		return 0, false
+	}
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return variantId, true
+}
+
 func (repository *OrderRepository) FindBuyerIdByOrderId(ctx context.Context, tx *sql.Tx, orderid string) (string, error) {
 	query := "SELECT customer_id from orders WHERE id=$1"
 	row, err := tx.QueryContext(ctx, query, orderid)
@@ -263,6 +281,34 @@ func (repository *OrderRepository) FindUserAndCostumeById(ctx context.Context, t
 	}
 }
 
+// FindEventStateById locks the order and returns its seller and customer together with its latest event status, empty
+// while the order has no events yet. The status is read after the lock is held, so a concurrent event on the same order
+// is always seen.
+func (repository *OrderRepository) FindEventStateById(ctx context.Context, tx *sql.Tx, orderid string) (domain.Order, string, error) {
+	query := "SELECT id, seller_id, customer_id FROM orders WHERE id = $1 FOR UPDATE"
+
+	order := domain.Order{}
+	err := tx.QueryRowContext(ctx, query, orderid).Scan(&order.Id, &order.Seller_id, &order.Costumer_id)
+	if err == sql.ErrNoRows {
+		return order, "", errors.New("order not found")
+	}
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	query = "SELECT COALESCE((SELECT status FROM order_events WHERE order_id = $1 ORDER BY created_at DESC LIMIT 1), '')"
+
+	var latestStatus string
+	err = tx.QueryRowContext(ctx, query, orderid).Scan(&latestStatus)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return order, latestStatus, nil
+}
+
 func (repository *OrderRepository) FindCostumeIdByOrderId(ctx context.Context, tx *sql.Tx, orderid string) (int, error) {
 	query := "SELECT costume_id FROM orders WHERE id=$1"
 	row, err := tx.QueryContext(ctx, query, orderid)
@@ -618,3 +664,34 @@ func (repository *OrderRepository) UpdateOrder(ctx context.Context, tx *sql.Tx,
 		repository.Log.Panic().Err(err).Msg(respErr.Error())
 	}
 }
+
+func (repository *OrderRepository) CheckActiveOrderBetween(ctx context.Context, tx *sql.Tx, sellerid string, customerid string) error {
+	query := `
+	SELECT 1
+	FROM orders o
+	JOIN LATERAL (
+		SELECT e.status
+		FROM order_events e
+		WHERE e.order_id = o.id
+		ORDER BY e.created_at DESC
+		LIMIT 1
+	) latest ON true
+	WHERE o.seller_id = $1
+		AND o.customer_id = $2
+		AND latest.status NOT IN ('Completed', 'Cancelled')
+	LIMIT 1
+	`
+	row, err := tx.QueryContext(ctx, query, sellerid, customerid)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer row.Close()
+
+	if row.Next() {
+		return nil
+	} else {
+		return errors.New("active order not found")
+	}
+}
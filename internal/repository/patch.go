@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"cosplayrent/internal/model/web"
+	"fmt"
+)
+
+func appendPatchField[T any](query string, args []interface{}, column string, field web.Optional[T]) (string, []interface{}) {
+	if !field.Set {
+		return query, args
+	}
+	if field.Null {
+		return query + column + " = NULL, ", args
+	}
+	args = append(args, field.Value)
+	return query + fmt.Sprintf("%s = $%d, ", column, len(args)), args
+}
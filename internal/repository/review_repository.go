@@ -47,33 +47,36 @@ func (repository *ReviewRepository) CheckReviewId(ctx context.Context, tx *sql.T
 	}
 }
 
-func (repository *ReviewRepository) Update(ctx context.Context, tx *sql.Tx, review domain.Review) {
+func (repository *ReviewRepository) CheckReviewOwner(ctx context.Context, tx *sql.Tx, reviewid int, userid string) error {
+	query := "SELECT id FROM reviews WHERE id=$1 AND customer_id=$2"
+	rows, err := tx.QueryContext(ctx, query, reviewid, userid)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer rows.Close()
+
+	if rows.Next() {
+		return nil
+	} else {
+		return errors.New("review not found")
+	}
+}
+
+func (repository *ReviewRepository) Update(ctx context.Context, tx *sql.Tx, reviewid int, reviewRequest review.ReviewUpdateRequest, updatedAt *time.Time) {
 	query := "UPDATE reviews SET "
 	args := []interface{}{}
-	argCounter := 1
 
-	if review.Review_picture != "" {
-		query += fmt.Sprintf("review_picture = $%d, ", argCounter)
-		args = append(args, review.Review_picture)
-		argCounter++
-	}
-	if review.Description != "" {
-		query += fmt.Sprintf("description = $%d, ", argCounter)
-		args = append(args, review.Description)
-		argCounter++
-	}
-	if review.Rating != 0 {
-		query += fmt.Sprintf("rating = $%d, ", argCounter)
-		args = append(args, review.Rating)
-		argCounter++
-	}
+	query, args = appendPatchField(query, args, "review_picture", reviewRequest.Review_picture)
+	query, args = appendPatchField(query, args, "description", reviewRequest.Description)
+	query, args = appendPatchField(query, args, "rating", reviewRequest.Rating)
 
-	query += fmt.Sprintf("updated_at = $%d ", argCounter)
-	args = append(args, review.Updated_at)
-	argCounter++
+	args = append(args, updatedAt)
+	query += fmt.Sprintf("updated_at = $%d ", len(args))
 
-	query += fmt.Sprintf("WHERE id = $%d", argCounter)
-	args = append(args, review.Id)
+	args = append(args, reviewid)
+	query += fmt.Sprintf("WHERE id = $%d", len(args))
 
 	_, err := tx.ExecContext(ctx, query, args...)
 	if err != nil {
@@ -82,6 +85,33 @@ func (repository *ReviewRepository) Update(ctx context.Context, tx *sql.Tx, revi
 	}
 }
 
+func (repository *ReviewRepository) FindById(ctx context.Context, tx *sql.Tx, reviewid int) (review.ReviewResponse, error) {
+	query := "SELECT customer_id,costume_id,review_picture,description,rating,created_at,updated_at FROM reviews WHERE id=$1"
+	rows, err := tx.QueryContext(ctx, query, reviewid)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer rows.Close()
+
+	review := review.ReviewResponse{}
+	var createdAt time.Time
+	var updatedAt time.Time
+	if rows.Next() {
+		err = rows.Scan(&review.User_id, &review.Costume_id, &review.Review_picture, &review.Description, &review.Rating, &createdAt, &updatedAt)
+		if err != nil {
+			respErr := errors.New("failed to scan query result")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		review.Created_at = createdAt.Format("2006-01-02 15:04:05")
+		review.Updated_at = updatedAt.Format("2006-01-02 15:04:05")
+		return review, nil
+	} else {
+		return review, errors.New("review not found")
+	}
+}
+
 func (repository *ReviewRepository) FindByCostumeId(ctx context.Context, tx *sql.Tx, id int) ([]review.ReviewResponse, error) {
 	query := "SELECT customer_id,costume_id,review_picture,description,rating,created_at,updated_at FROM reviews where costume_id=$1"
 	rows, err := tx.QueryContext(ctx, query, id)
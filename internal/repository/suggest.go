@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// findSuggestions runs a suggestion query whose $1 is the escaped LIKE prefix, $2 the raw prefix and $3 the limit.
+func findSuggestions(ctx context.Context, tx *sql.Tx, log *zerolog.Logger, query string, prefix string, limit int) []string {
+	rows, err := tx.QueryContext(ctx, query, likeEscaper.Replace(prefix)+"%", prefix, limit)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer rows.Close()
+
+	suggestions := []string{}
+	for rows.Next() {
+		var suggestion string
+		err = rows.Scan(&suggestion)
+		if err != nil {
+			respErr := errors.New("failed to scan query result")
+			log.Panic().Err(err).Msg(respErr.Error())
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+
+	return suggestions
+}
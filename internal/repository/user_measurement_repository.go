@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"cosplayrent/internal/model/domain"
+	"database/sql"
+	"errors"
+	"github.com/rs/zerolog"
+)
+
+type UserMeasurementRepository struct {
+	Log *zerolog.Logger
+}
+
+func NewUserMeasurementRepository(zerolog *zerolog.Logger) *UserMeasurementRepository {
+	return &UserMeasurementRepository{
+		Log: zerolog,
+	}
+}
+
+func (repository *UserMeasurementRepository) Save(ctx context.Context, tx *sql.Tx, measurement domain.UserMeasurement) {
+	query := `INSERT INTO user_measurements (user_id,chest,waist,hip,height,shoe_size,updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7)
+		ON CONFLICT (user_id) DO UPDATE SET chest = EXCLUDED.chest, waist = EXCLUDED.waist, hip = EXCLUDED.hip,
+			height = EXCLUDED.height, shoe_size = EXCLUDED.shoe_size, updated_at = EXCLUDED.updated_at`
+
+	_, err := tx.ExecContext(ctx, query, measurement.User_id, measurement.Chest, measurement.Waist, measurement.Hip, measurement.Height, measurement.Shoe_size, measurement.Updated_at)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *UserMeasurementRepository) FindByUserId(ctx context.Context, tx *sql.Tx, userId string) (domain.UserMeasurement, error) {
+	query := "SELECT user_id,chest,waist,hip,height,shoe_size,updated_at FROM user_measurements WHERE user_id = $1"
+
+	measurement := domain.UserMeasurement{}
+	err := tx.QueryRowContext(ctx, query, userId).Scan(&measurement.User_id, &measurement.Chest, &measurement.Waist, &measurement.Hip, &measurement.Height, &measurement.Shoe_size, &measurement.Updated_at)
+	if err == sql.ErrNoRows {
+		return measurement, errors.New("measurements not found")
+	}
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return measurement, nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"cosplayrent/internal/model/domain"
+	"cosplayrent/internal/model/web/notification"
+	"database/sql"
+	"errors"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+type UserNotificationRepository struct {
+	Log *zerolog.Logger
+}
+
+func NewUserNotificationRepository(zerolog *zerolog.Logger) *UserNotificationRepository {
+	return &UserNotificationRepository{
+		Log: zerolog,
+	}
+}
+
+// CreateForUsers writes the same notification into the inbox of every user in userIds with one statement.
+func (repository *UserNotificationRepository) CreateForUsers(ctx context.Context, tx *sql.Tx, userIds []string, userNotification domain.UserNotification) {
+	if len(userIds) == 0 {
+		return
+	}
+
+	query := "INSERT INTO user_notifications (user_id,type,title,body,costume_id,created_at) SELECT u, $2, $3, $4, $5, $6 FROM unnest($1::text[]) AS u"
+	_, err := tx.ExecContext(ctx, query, userIds, userNotification.Type, userNotification.Title, userNotification.Body, userNotification.Costume_id, userNotification.Created_at)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *UserNotificationRepository) FindByUserId(ctx context.Context, tx *sql.Tx, userId string, limit int) []notification.UserNotificationResponse {
+	query := "SELECT id,type,title,body,costume_id,read_at,created_at FROM user_notifications WHERE user_id = $1 ORDER BY created_at DESC, id DESC LIMIT $2"
+	rows, err := tx.QueryContext(ctx, query, userId, limit)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer rows.Close()
+
+	notifications := []notification.UserNotificationResponse{}
+	var readAt *time.Time
+	var createdAt time.Time
+	for rows.Next() {
+		userNotification := notification.UserNotificationResponse{}
+		err = rows.Scan(&userNotification.Id, &userNotification.Type, &userNotification.Title, &userNotification.Body, &userNotification.Costume_id, &readAt, &createdAt)
+		if err != nil {
+			respErr := errors.New("failed to scan query result")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		if readAt != nil {
+			value := readAt.Format("2006-01-02 15:04:05")
+			userNotification.Read_at = &value
+		}
+		userNotification.Created_at = createdAt.Format("2006-01-02 15:04:05")
+		notifications = append(notifications, userNotification)
+	}
+
+	return notifications
+}
+
+func (repository *UserNotificationRepository) CountUnread(ctx context.Context, tx *sql.Tx, userId string) int {
+	query := "SELECT COUNT(*) FROM user_notifications WHERE user_id = $1 AND read_at IS NULL"
+
+	var unread int
+	err := tx.QueryRowContext(ctx, query, userId).Scan(&unread)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return unread
+}
+
+func (repository *UserNotificationRepository) MarkAllRead(ctx context.Context, tx *sql.Tx, userId string, readAt *time.Time) {
+	query := "UPDATE user_notifications SET read_at = $1 WHERE user_id = $2 AND read_at IS NULL"
+	_, err := tx.ExecContext(ctx, query, readAt, userId)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"cosplayrent/internal/model/domain"
+	"cosplayrent/internal/model/web/shop"
 	"cosplayrent/internal/model/web/user"
 	"database/sql"
 	"errors"
@@ -205,74 +206,175 @@ func (repository *UserRepository) FindAll(ctx context.Context, tx *sql.Tx, uuid
 	return users, nil
 }
 
-func (repository *UserRepository) Update(ctx context.Context, tx *sql.Tx, user domain.User) {
+func (repository *UserRepository) Update(ctx context.Context, tx *sql.Tx, uuid string, userRequest user.UserPatchRequest, updatedAt *time.Time) {
 	query := "UPDATE users SET "
 	args := []interface{}{}
-	argCounter := 1
 
-	if user.Name != "" {
-		query += fmt.Sprintf("name = $%d, ", argCounter)
-		args = append(args, user.Name)
-		argCounter++
+	query, args = appendPatchField(query, args, "name", userRequest.Name)
+	query, args = appendPatchField(query, args, "address", userRequest.Address)
+	query, args = appendPatchField(query, args, "profile_picture", userRequest.Profile_picture)
+	query, args = appendPatchField(query, args, "originprovince_name", userRequest.Origin_province_name)
+	query, args = appendPatchField(query, args, "originprovince_id", userRequest.Origin_province_id)
+	query, args = appendPatchField(query, args, "origincity_name", userRequest.Origin_city_name)
+	query, args = appendPatchField(query, args, "origincity_id", userRequest.Origin_city_id)
+
+	args = append(args, updatedAt)
+	query += fmt.Sprintf("updated_at = $%d ", len(args))
+
+	args = append(args, uuid)
+	query += fmt.Sprintf("WHERE id = $%d", len(args))
+
+	_, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
 	}
-	if user.Email != "" {
-		query += fmt.Sprintf("email = $%d, ", argCounter)
-		args = append(args, user.Email)
-		argCounter++
+}
+
+func (repository *UserRepository) AddOrUpdateIdentityCard(ctx context.Context, tx *sql.Tx, user domain.User) {
+	if user.Identity_card_picture != "" {
+		query := "UPDATE users SET identitycard_picture = $1, identitycard_status = 'Submitted', identitycard_rejection_reason = NULL, identitycard_submitted_at = $2, identitycard_reviewed_by = NULL, identitycard_reviewed_at = NULL WHERE id = $3"
+		_, err := tx.ExecContext(ctx, query, user.Identity_card_picture, user.Updated_at, user.Id)
+		if err != nil {
+			respErr := errors.New("failed to query into database")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
 	}
-	if user.Profile_picture != nil {
-		query += fmt.Sprintf("profile_picture = $%d, ", argCounter)
-		args = append(args, user.Profile_picture)
-		argCounter++
+}
+
+// ReplaceIdentityCard repoints the identity card only while it still holds from, so a card uploaded in the meantime
+// is left alone.
+func (repository *UserRepository) ReplaceIdentityCard(ctx context.Context, tx *sql.Tx, uuid string, from string, to string) bool {
+	query := "UPDATE users SET identitycard_picture = $1 WHERE id = $2 AND identitycard_picture = $3"
+	result, err := tx.ExecContext(ctx, query, to, uuid, from)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
 	}
-	if user.Address != "" {
-		query += fmt.Sprintf("address = $%d, ", argCounter)
-		args = append(args, user.Address)
-		argCounter++
+
+	replaced, err := result.RowsAffected()
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
 	}
-	if user.Origin_province_name != "" {
-		query += fmt.Sprintf("originprovince_name = $%d, ", argCounter)
-		args = append(args, user.Origin_province_name)
-		argCounter++
+
+	return replaced > 0
+}
+
+// FindLegacyIdentityCards returns the users whose identity card is still the plaintext upload in the public store.
+func (repository *UserRepository) FindLegacyIdentityCards(ctx context.Context, tx *sql.Tx) []domain.User {
+	query := "SELECT id,identitycard_picture FROM users WHERE identitycard_picture LIKE '/static/%' ORDER BY id"
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
 	}
-	if user.Origin_province_id != 0 {
-		query += fmt.Sprintf("originprovince_id = $%d, ", argCounter)
-		args = append(args, user.Origin_province_id)
-		argCounter++
+
+	defer rows.Close()
+
+	users := []domain.User{}
+	for rows.Next() {
+		user := domain.User{}
+		err = rows.Scan(&user.Id, &user.Identity_card_picture)
+		if err != nil {
+			respErr := errors.New("failed to scan query result")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		users = append(users, user)
 	}
-	if user.Origin_city_name != "" {
-		query += fmt.Sprintf("origincity_name = $%d, ", argCounter)
-		args = append(args, user.Origin_city_name)
-		argCounter++
+
+	return users
+}
+
+func (repository *UserRepository) FindIdentityCardStatus(ctx context.Context, tx *sql.Tx, uuid string) (domain.User, error) {
+	query := "SELECT id,name,email,identitycard_status,identitycard_rejection_reason FROM users WHERE id=$1"
+	row, err := tx.QueryContext(ctx, query, uuid)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
 	}
-	if user.Origin_city_id != 0 {
-		query += fmt.Sprintf("origincity_id = $%d, ", argCounter)
-		args = append(args, user.Origin_city_id)
-		argCounter++
+
+	defer row.Close()
+
+	user := domain.User{}
+	var identityCardStatus *string
+	if row.Next() {
+		err := row.Scan(&user.Id, &user.Name, &user.Email, &identityCardStatus, &user.Identity_card_reason)
+		if err != nil {
+			respErr := errors.New("failed to scan query result")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		if identityCardStatus == nil {
+			return user, errors.New("identity card is empty")
+		}
+		user.Identity_card_status = *identityCardStatus
+		return user, nil
+	} else {
+		return user, errors.New("user not found")
 	}
+}
 
-	query += fmt.Sprintf("updated_at = $%d ", argCounter)
-	args = append(args, user.Updated_at)
-	argCounter++
+func (repository *UserRepository) FindPendingIdentityCards(ctx context.Context, tx *sql.Tx) ([]user.PendingIdentityCardResponse, error) {
+	query := "SELECT id,name,email,identitycard_picture,identitycard_submitted_at FROM users WHERE identitycard_status='Submitted' ORDER BY identitycard_submitted_at ASC"
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
 
-	query += fmt.Sprintf("WHERE id = $%d", argCounter)
-	args = append(args, user.Id)
+	hasData := false
 
-	_, err := tx.ExecContext(ctx, query, args...)
+	defer rows.Close()
+
+	users := []user.PendingIdentityCardResponse{}
+	var submittedAt time.Time
+	for rows.Next() {
+		user := user.PendingIdentityCardResponse{}
+		err = rows.Scan(&user.User_id, &user.Name, &user.Email, &user.IdentityCard_picture, &submittedAt)
+		if err != nil {
+			respErr := errors.New("failed to scan query result")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		user.IdentityCard_submitted = submittedAt.Format("2006-01-02 15:04:05")
+		users = append(users, user)
+		hasData = true
+	}
+	if hasData == false {
+		return users, errors.New("no identity card waiting for review")
+	}
+
+	return users, nil
+}
+
+func (repository *UserRepository) UpdateIdentityCardStatus(ctx context.Context, tx *sql.Tx, user domain.User) {
+	query := "UPDATE users SET identitycard_status = $1, identitycard_rejection_reason = $2, identitycard_reviewed_by = $3, identitycard_reviewed_at = $4 WHERE id = $5"
+	_, err := tx.ExecContext(ctx, query, user.Identity_card_status, user.Identity_card_reason, user.Identity_card_reviewer, user.Identity_card_reviewed, user.Id)
 	if err != nil {
 		respErr := errors.New("failed to query into database")
 		repository.Log.Panic().Err(err).Msg(respErr.Error())
 	}
 }
 
-func (repository *UserRepository) AddOrUpdateIdentityCard(ctx context.Context, tx *sql.Tx, user domain.User) {
-	if user.Identity_card_picture != "" {
-		query := "UPDATE users SET identitycard_picture = $1 WHERE id = $2"
-		_, err := tx.ExecContext(ctx, query, user.Identity_card_picture, user.Id)
+func (repository *UserRepository) FindRoleById(ctx context.Context, tx *sql.Tx, uuid string) (string, error) {
+	query := "SELECT role FROM users WHERE id=$1"
+	row, err := tx.QueryContext(ctx, query, uuid)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer row.Close()
+
+	var role string
+	if row.Next() {
+		err := row.Scan(&role)
 		if err != nil {
-			respErr := errors.New("failed to query into database")
+			respErr := errors.New("failed to scan query result")
 			repository.Log.Panic().Err(err).Msg(respErr.Error())
 		}
+		return role, nil
+	} else {
+		return role, errors.New("user not found")
 	}
 }
 
@@ -465,7 +567,7 @@ func (repository *UserRepository) AfterBuyForSeller(ctx context.Context, tx *sql
 }
 
 func (repository *UserRepository) CheckUserStatus(ctx context.Context, tx *sql.Tx, userid string) (user.CheckUserStatusResponse, error) {
-	query := "SELECT id,name,identitycard_picture,address,origincity_name FROM users WHERE id=$1"
+	query := "SELECT id,name,identitycard_picture,identitycard_status,address,origincity_name FROM users WHERE id=$1"
 	row, err := tx.QueryContext(ctx, query, userid)
 	if err != nil {
 		respErr := errors.New("failed to query into database")
@@ -476,19 +578,22 @@ func (repository *UserRepository) CheckUserStatus(ctx context.Context, tx *sql.T
 
 	checkuserStatus := user.CheckUserStatusResponse{}
 	var IdentityCardImage *string
+	var IdentityCardStatus *string
 	var originCityName *string
 	var address *string
 	if row.Next() {
-		err := row.Scan(&checkuserStatus.User_id, &checkuserStatus.Name, &IdentityCardImage, &address, &originCityName)
+		err := row.Scan(&checkuserStatus.User_id, &checkuserStatus.Name, &IdentityCardImage, &IdentityCardStatus, &address, &originCityName)
 		if err != nil {
 			respErr := errors.New("failed to scan query result")
 			repository.Log.Panic().Err(err).Msg(respErr.Error())
 		}
-		if IdentityCardImage != nil && originCityName != nil && address != nil {
-			return checkuserStatus, nil
-		} else {
+		if IdentityCardImage == nil || originCityName == nil || address == nil {
 			return checkuserStatus, errors.New("empty identity card or address detail")
 		}
+		if IdentityCardStatus == nil || *IdentityCardStatus != "Approved" {
+			return checkuserStatus, errors.New("identity card has not been approved")
+		}
+		return checkuserStatus, nil
 	} else {
 		return checkuserStatus, errors.New("user not found")
 	}
@@ -579,7 +684,7 @@ func (repository *UserRepository) FindBasicInfo(ctx context.Context, tx *sql.Tx,
 	user := domain.User{}
 
 	if row.Next() {
-		err := row.Scan(&user.Id, &user.Name, &user.Profile_picture)
+		err := row.Scan(&user.Id, &user.Name, &user.Email)
 		if err != nil {
 			respErr := errors.New("failed to scan query result")
 			repository.Log.Panic().Err(err).Msg(respErr.Error())
@@ -598,3 +703,413 @@ func (repository *UserRepository) Delete(ctx context.Context, tx *sql.Tx, uuid s
 		repository.Log.Panic().Err(err).Msg(respErr.Error())
 	}
 }
+
+func (repository *UserRepository) CreateIdentityCardAccessLog(ctx context.Context, tx *sql.Tx, accessLog domain.IdentityCardAccessLog) {
+	query := "INSERT INTO identitycard_access_logs (owner_id,accessor_id,access_role,granted,created_at) VALUES ($1,$2,$3,$4,$5)"
+	_, err := tx.ExecContext(ctx, query, accessLog.Owner_id, accessLog.Accessor_id, accessLog.Access_role, accessLog.Granted, accessLog.Created_at)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *UserRepository) FindPasswordById(ctx context.Context, tx *sql.Tx, uuid string) (string, error) {
+	query := "SELECT password FROM users WHERE id=$1"
+	row, err := tx.QueryContext(ctx, query, uuid)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer row.Close()
+
+	var password string
+	if row.Next() {
+		err := row.Scan(&password)
+		if err != nil {
+			respErr := errors.New("failed to scan query result")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		return password, nil
+	} else {
+		return password, errors.New("user not found")
+	}
+}
+
+func (repository *UserRepository) UpdatePassword(ctx context.Context, tx *sql.Tx, user domain.User) {
+	query := "UPDATE users SET password = $1, updated_at = $2 WHERE id = $3"
+	_, err := tx.ExecContext(ctx, query, user.Password, user.Updated_at, user.Id)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *UserRepository) CheckEmailUnique(ctx context.Context, tx *sql.Tx, email string) error {
+	query := "SELECT id FROM users WHERE email=$1 LIMIT 1"
+	row, err := tx.QueryContext(ctx, query, email)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer row.Close()
+
+	if row.Next() {
+		return errors.New("email already exists")
+	} else {
+		return nil
+	}
+}
+
+func (repository *UserRepository) CreateOrUpdateEmailChange(ctx context.Context, tx *sql.Tx, emailChange domain.EmailChange) {
+	query := `
+	INSERT INTO email_changes (user_id,new_email,verification_code,created_at,expired_at) VALUES ($1,$2,$3,$4,$5)
+	ON CONFLICT (user_id) DO UPDATE SET new_email = EXCLUDED.new_email, verification_code = EXCLUDED.verification_code, attempts = 0, created_at = EXCLUDED.created_at, expired_at = EXCLUDED.expired_at
+	`
+	_, err := tx.ExecContext(ctx, query, emailChange.User_id, emailChange.New_email, emailChange.Verification_code, emailChange.Created_at, emailChange.Expired_at)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+// FindEmailChange locks the pending change, so concurrent guesses are counted one after another.
+func (repository *UserRepository) FindEmailChange(ctx context.Context, tx *sql.Tx, uuid string) (domain.EmailChange, error) {
+	query := "SELECT user_id,new_email,verification_code,attempts,expired_at FROM email_changes WHERE user_id=$1 FOR UPDATE"
+	row, err := tx.QueryContext(ctx, query, uuid)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer row.Close()
+
+	emailChange := domain.EmailChange{}
+	if row.Next() {
+		err := row.Scan(&emailChange.User_id, &emailChange.New_email, &emailChange.Verification_code, &emailChange.Attempts, &emailChange.Expired_at)
+		if err != nil {
+			respErr := errors.New("failed to scan query result")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		return emailChange, nil
+	} else {
+		return emailChange, errors.New("no pending email change")
+	}
+}
+
+func (repository *UserRepository) IncrementEmailChangeAttempts(ctx context.Context, tx *sql.Tx, uuid string) {
+	query := "UPDATE email_changes SET attempts = attempts + 1 WHERE user_id = $1"
+	_, err := tx.ExecContext(ctx, query, uuid)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *UserRepository) DeleteEmailChange(ctx context.Context, tx *sql.Tx, uuid string) {
+	query := "DELETE FROM email_changes WHERE user_id=$1"
+	_, err := tx.ExecContext(ctx, query, uuid)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *UserRepository) UpdateEmail(ctx context.Context, tx *sql.Tx, user domain.User) {
+	query := "UPDATE users SET email = $1, updated_at = $2 WHERE id = $3"
+	_, err := tx.ExecContext(ctx, query, user.Email, user.Updated_at, user.Id)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *UserRepository) CheckNameUnique(ctx context.Context, tx *sql.Tx, name string, uuid string) error {
+	query := "SELECT id FROM users WHERE name=$1 AND id<>$2 LIMIT 1"
+	row, err := tx.QueryContext(ctx, query, name, uuid)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer row.Close()
+
+	if row.Next() {
+		return errors.New("name already exists")
+	} else {
+		return nil
+	}
+}
+
+// FindTwoFactorById locks the user, so concurrent wrong codes are counted one after another.
+func (repository *UserRepository) FindTwoFactorById(ctx context.Context, tx *sql.Tx, uuid string) (domain.User, error) {
+	query := "SELECT id,email,totp_secret,totp_enabled,totp_last_step,totp_attempts,totp_locked_until FROM users WHERE id=$1 FOR UPDATE"
+	row, err := tx.QueryContext(ctx, query, uuid)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer row.Close()
+
+	user := domain.User{}
+	if row.Next() {
+		err := row.Scan(&user.Id, &user.Email, &user.Totp_secret, &user.Totp_enabled, &user.Totp_last_step, &user.Totp_attempts, &user.Totp_locked_until)
+		if err != nil {
+			respErr := errors.New("failed to scan query result")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		return user, nil
+	} else {
+		return user, errors.New("user not found")
+	}
+}
+
+func (repository *UserRepository) FindVacationById(ctx context.Context, tx *sql.Tx, uuid string) (domain.User, error) {
+	query := "SELECT id,vacation_start,vacation_end,vacation_message FROM users WHERE id=$1"
+	row, err := tx.QueryContext(ctx, query, uuid)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer row.Close()
+
+	user := domain.User{}
+	if row.Next() {
+		err := row.Scan(&user.Id, &user.Vacation_start, &user.Vacation_end, &user.Vacation_message)
+		if err != nil {
+			respErr := errors.New("failed to scan query result")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		return user, nil
+	} else {
+		return user, errors.New("user not found")
+	}
+}
+
+// FindShopById loads a seller's public profile together with the shop stats, aggregated in the same query.
+func (repository *UserRepository) FindShopById(ctx context.Context, tx *sql.Tx, uuid string) (domain.User, shop.ShopStatsResponse, error) {
+	query := `SELECT u.id, u.name, u.profile_picture, u.origincity_name, COALESCE(u.identitycard_status, ''), u.vacation_start, u.vacation_end, u.vacation_message, u.created_at,
+		r.average_rating, r.review_count, o.completed_rentals, o.responded, o.answerable,
+		(SELECT COUNT(*) FROM follows f WHERE f.seller_id = u.id) AS follower_count
+	FROM users u
+	CROSS JOIN LATERAL (
+		SELECT COALESCE(AVG(rv.rating), 0)::float8 AS average_rating, COUNT(*) AS review_count
+		FROM reviews rv JOIN costumes c ON c.id = rv.costume_id
+		WHERE c.user_id = u.id
+	) r
+	CROSS JOIN LATERAL (
+		SELECT COUNT(*) FILTER (WHERE EXISTS (SELECT 1 FROM order_events e WHERE e.order_id = od.id AND e.status = 'Completed' AND e.user_id = u.id)) AS completed_rentals,
+			COUNT(*) FILTER (WHERE od.created_at >= now() - interval '90 days' AND od.responded) AS responded,
+			COUNT(*) FILTER (WHERE od.created_at >= now() - interval '90 days' AND (od.responded OR od.created_at < now() - interval '24 hours')) AS answerable
+		FROM (
+			SELECT o.id, o.created_at, EXISTS (
+				SELECT 1 FROM order_events e WHERE e.order_id = o.id AND e.user_id = o.seller_id AND e.created_at <= o.created_at + interval '24 hours'
+			) AS responded
+			FROM orders o WHERE o.seller_id = u.id
+		) od
+	) o
+	WHERE u.id = $1`
+	row, err := tx.QueryContext(ctx, query, uuid)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer row.Close()
+
+	user := domain.User{}
+	stats := shop.ShopStatsResponse{}
+	var city *string
+	var responded int
+	var answerable int
+	if row.Next() {
+		err := row.Scan(&user.Id, &user.Name, &user.Profile_picture, &city, &user.Identity_card_status, &user.Vacation_start, &user.Vacation_end, &user.Vacation_message, &user.Created_at,
+			&stats.Average_rating, &stats.Review_count, &stats.Completed_rentals, &responded, &answerable, &stats.Follower_count)
+		if err != nil {
+			respErr := errors.New("failed to scan query result")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		if city != nil {
+			user.Origin_city_name = *city
+		}
+		if answerable > 0 {
+			rate := float64(responded) * 100 / float64(answerable)
+			stats.Response_rate = &rate
+		}
+		return user, stats, nil
+	} else {
+		return user, stats, errors.New("shop not found")
+	}
+}
+
+// UpdateVacation sets the vacation window and message, nil values end the vacation.
+func (repository *UserRepository) UpdateVacation(ctx context.Context, tx *sql.Tx, user domain.User) {
+	query := "UPDATE users SET vacation_start = $1, vacation_end = $2, vacation_message = $3, updated_at = $4 WHERE id = $5"
+	_, err := tx.ExecContext(ctx, query, user.Vacation_start, user.Vacation_end, user.Vacation_message, user.Updated_at, user.Id)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *UserRepository) UpdateTwoFactorSecret(ctx context.Context, tx *sql.Tx, user domain.User) {
+	query := "UPDATE users SET totp_secret = $1, totp_enabled = false, totp_last_step = NULL, totp_attempts = 0 WHERE id = $2"
+	_, err := tx.ExecContext(ctx, query, user.Totp_secret, user.Id)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *UserRepository) EnableTwoFactor(ctx context.Context, tx *sql.Tx, user domain.User) {
+	query := "UPDATE users SET totp_enabled = true, totp_last_step = $1, totp_attempts = 0 WHERE id = $2"
+	_, err := tx.ExecContext(ctx, query, user.Totp_last_step, user.Id)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *UserRepository) DisableTwoFactor(ctx context.Context, tx *sql.Tx, uuid string) {
+	query := "UPDATE users SET totp_secret = NULL, totp_enabled = false, totp_last_step = NULL, totp_attempts = 0, totp_locked_until = NULL WHERE id = $1"
+	_, err := tx.ExecContext(ctx, query, uuid)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+// UpdateTwoFactorAttempts stores the count of wrong codes in a row and the lockout they started, nil when none.
+func (repository *UserRepository) UpdateTwoFactorAttempts(ctx context.Context, tx *sql.Tx, user domain.User) {
+	query := "UPDATE users SET totp_attempts = $1, totp_locked_until = $2 WHERE id = $3"
+	_, err := tx.ExecContext(ctx, query, user.Totp_attempts, user.Totp_locked_until, user.Id)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *UserRepository) UpdateTwoFactorLastStep(ctx context.Context, tx *sql.Tx, user domain.User) {
+	query := "UPDATE users SET totp_last_step = $1 WHERE id = $2"
+	_, err := tx.ExecContext(ctx, query, user.Totp_last_step, user.Id)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *UserRepository) CreateRecoveryCode(ctx context.Context, tx *sql.Tx, recoveryCode domain.UserRecoveryCode) {
+	query := "INSERT INTO user_recovery_codes (user_id,code_hash,created_at) VALUES ($1,$2,$3)"
+	_, err := tx.ExecContext(ctx, query, recoveryCode.User_id, recoveryCode.Code_hash, recoveryCode.Created_at)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *UserRepository) DeleteRecoveryCodes(ctx context.Context, tx *sql.Tx, uuid string) {
+	query := "DELETE FROM user_recovery_codes WHERE user_id = $1"
+	_, err := tx.ExecContext(ctx, query, uuid)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *UserRepository) FindUnusedRecoveryCodes(ctx context.Context, tx *sql.Tx, uuid string) []domain.UserRecoveryCode {
+	query := "SELECT id,code_hash FROM user_recovery_codes WHERE user_id = $1 AND used_at IS NULL"
+	rows, err := tx.QueryContext(ctx, query, uuid)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer rows.Close()
+
+	recoveryCodes := []domain.UserRecoveryCode{}
+	for rows.Next() {
+		recoveryCode := domain.UserRecoveryCode{}
+		err = rows.Scan(&recoveryCode.Id, &recoveryCode.Code_hash)
+		if err != nil {
+			respErr := errors.New("failed to scan query result")
+			repository.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		recoveryCodes = append(recoveryCodes, recoveryCode)
+	}
+
+	return recoveryCodes
+}
+
+func (repository *UserRepository) UseRecoveryCode(ctx context.Context, tx *sql.Tx, recoveryCode domain.UserRecoveryCode) {
+	query := "UPDATE user_recovery_codes SET used_at = $1 WHERE id = $2"
+	_, err := tx.ExecContext(ctx, query, recoveryCode.Used_at, recoveryCode.Id)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *UserRepository) CreateTwoFactorChallenge(ctx context.Context, tx *sql.Tx, challenge domain.TwoFactorChallenge) {
+	query := "INSERT INTO two_factor_challenges (id,user_id,expires_at,created_at) VALUES ($1,$2,$3,$4)"
+	_, err := tx.ExecContext(ctx, query, challenge.Id, challenge.User_id, challenge.Expires_at, challenge.Created_at)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+// FindTwoFactorChallenge locks the challenge, so concurrent guesses are counted one after another.
+func (repository *UserRepository) FindTwoFactorChallenge(ctx context.Context, tx *sql.Tx, id string) (domain.TwoFactorChallenge, error) {
+	query := "SELECT id,user_id,attempts,expires_at FROM two_factor_challenges WHERE id = $1 FOR UPDATE"
+
+	challenge := domain.TwoFactorChallenge{}
+	err := tx.QueryRowContext(ctx, query, id).Scan(&challenge.Id, &challenge.User_id, &challenge.Attempts, &challenge.Expires_at)
+	if err == sql.ErrNoRows {
+		return challenge, errors.New("challenge not found")
+	}
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return challenge, nil
+}
+
+func (repository *UserRepository) IncrementTwoFactorChallengeAttempts(ctx context.Context, tx *sql.Tx, id string) {
+	query := "UPDATE two_factor_challenges SET attempts = attempts + 1 WHERE id = $1"
+	_, err := tx.ExecContext(ctx, query, id)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *UserRepository) DeleteTwoFactorChallenge(ctx context.Context, tx *sql.Tx, id string) {
+	query := "DELETE FROM two_factor_challenges WHERE id = $1"
+	_, err := tx.ExecContext(ctx, query, id)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *UserRepository) DeleteExpiredTwoFactorChallenges(ctx context.Context, tx *sql.Tx, uuid string, now time.Time) {
+	query := "DELETE FROM two_factor_challenges WHERE user_id = $1 AND expires_at < $2"
+	_, err := tx.ExecContext(ctx, query, uuid, now)
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (repository *UserRepository) SuggestSellerNames(ctx context.Context, tx *sql.Tx, prefix string, limit int) []string {
+	query := `SELECT name FROM users u
+		WHERE (name ILIKE $1 OR $2 <% name)
+		AND EXISTS (SELECT 1 FROM costumes c WHERE c.user_id = u.id AND c.status = 'published')
+		ORDER BY name ILIKE $1 DESC, similarity(name, $2) DESC, name LIMIT $3`
+
+	return findSuggestions(ctx, tx, repository.Log, query, prefix, limit)
+}
@@ -59,13 +59,21 @@ func (repository *WishlistRepository) FindCostumeIdByOrderId(ctx context.Context
 	return wishlists, nil
 }
 
-func (repository *WishlistRepository) DeleteWishlist(ctx context.Context, tx *sql.Tx, uuid string, costumeid int) {
+func (repository *WishlistRepository) DeleteWishlist(ctx context.Context, tx *sql.Tx, uuid string, costumeid int) bool {
 	query := "DELETE FROM wishlists WHERE user_id=$1 AND costume_id=$2"
-	_, err := tx.ExecContext(ctx, query, uuid, costumeid)
+	result, err := tx.ExecContext(ctx, query, uuid, costumeid)
 	if err != nil {
 		respErr := errors.New("failed to query into database")
 		repository.Log.Panic().Err(err).Msg(respErr.Error())
 	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		respErr := errors.New("failed to query into database")
+		repository.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return deleted > 0
 }
 
 func (repository *WishlistRepository) FindWishlistById(ctx context.Context, tx *sql.Tx, uuid string, costumeid int) error {
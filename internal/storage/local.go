@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type Local struct {
+	Dir     string
+	BaseURL string
+}
+
+func NewLocal(dir string, baseURL string) *Local {
+	return &Local{
+		Dir:     dir,
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+func (local *Local) path(key string) (string, error) {
+	cleanKey := path.Clean("/" + key)
+	if cleanKey == "/" {
+		return "", errors.New("invalid storage key")
+	}
+	return filepath.Join(local.Dir, filepath.FromSlash(cleanKey)), nil
+}
+
+func (local *Local) Put(ctx context.Context, key string, body []byte, contentType string) error {
+	filePath, err := local.path(key)
+	if err != nil {
+		return err
+	}
+
+	// a store without a public url holds private uploads such as identity cards
+	dirMode, fileMode := os.FileMode(0755), os.FileMode(0644)
+	if local.BaseURL == "" {
+		dirMode, fileMode = 0700, 0600
+	}
+
+	err = os.MkdirAll(filepath.Dir(filePath), dirMode)
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first so readers never see a partially written object
+	tmpFile, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.Write(body)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	err = os.Chmod(tmpFile.Name(), fileMode)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile.Name(), filePath)
+}
+
+func (local *Local) Get(ctx context.Context, key string) ([]byte, error) {
+	filePath, err := local.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return body, err
+}
+
+func (local *Local) Delete(ctx context.Context, key string) error {
+	filePath, err := local.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (local *Local) URL(key string) string {
+	return local.BaseURL + "/" + strings.TrimPrefix(key, "/")
+}
+
+// SignedURL cannot enforce an expiry on files served by the static file server, so it only works for a public directory.
+func (local *Local) SignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	if local.BaseURL == "" {
+		return "", errors.New("local storage has no public url")
+	}
+	return local.URL(key), nil
+}
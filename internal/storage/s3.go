@@ -0,0 +1,209 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PublicURL string
+}
+
+// S3 talks to any S3 compatible service with path style requests signed with AWS Signature Version 4,
+// so the same driver works against AWS, MinIO in docker-compose or another stand-in.
+type S3 struct {
+	config S3Config
+	client *http.Client
+}
+
+func NewS3(config S3Config) *S3 {
+	config.Endpoint = strings.TrimSuffix(config.Endpoint, "/")
+	config.PublicURL = strings.TrimSuffix(config.PublicURL, "/")
+	if config.PublicURL == "" {
+		config.PublicURL = config.Endpoint + "/" + config.Bucket
+	}
+
+	return &S3{
+		config: config,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (s3 *S3) objectURL(key string) *url.URL {
+	objectURL, _ := url.Parse(s3.config.Endpoint)
+	objectURL.Path = "/" + s3.config.Bucket + "/" + strings.TrimPrefix(key, "/")
+	return objectURL
+}
+
+func (s3 *S3) do(ctx context.Context, method string, key string, body []byte, contentType string) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, method, s3.objectURL(key).String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+
+	payloadHash := sha256.Sum256(body)
+	s3.sign(request, hex.EncodeToString(payloadHash[:]), time.Now().UTC())
+
+	return s3.client.Do(request)
+}
+
+func (s3 *S3) Put(ctx context.Context, key string, body []byte, contentType string) error {
+	response, err := s3.do(ctx, http.MethodPut, key, body, contentType)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return checkS3Response(response)
+}
+
+func (s3 *S3) Get(ctx context.Context, key string) ([]byte, error) {
+	response, err := s3.do(ctx, http.MethodGet, key, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	err = checkS3Response(response)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(response.Body)
+}
+
+func (s3 *S3) Delete(ctx context.Context, key string) error {
+	response, err := s3.do(ctx, http.MethodDelete, key, nil, "")
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return checkS3Response(response)
+}
+
+func (s3 *S3) URL(key string) string {
+	return s3.config.PublicURL + "/" + strings.TrimPrefix(key, "/")
+}
+
+func (s3 *S3) SignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	now := time.Now().UTC()
+	objectURL := s3.objectURL(key)
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", s3.config.AccessKey+"/"+s3.scope(now))
+	query.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	query.Set("X-Amz-Expires", fmt.Sprintf("%d", int(expires.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+	objectURL.RawQuery = strings.ReplaceAll(query.Encode(), "+", "%20")
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		objectURL.EscapedPath(),
+		objectURL.RawQuery,
+		"host:" + objectURL.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+
+	objectURL.RawQuery += "&X-Amz-Signature=" + s3.signature(now, canonicalRequest)
+
+	return objectURL.String(), nil
+}
+
+func (s3 *S3) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + s3.config.Region + "/s3/aws4_request"
+}
+
+func (s3 *S3) sign(request *http.Request, payloadHash string, now time.Time) {
+	request.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": request.URL.Host}
+	for name := range request.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(request.Header.Get(name))
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	canonicalHeaders := ""
+	for _, name := range names {
+		canonicalHeaders += name + ":" + headers[name] + "\n"
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		request.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3.config.AccessKey, s3.scope(now), signedHeaders, s3.signature(now, canonicalRequest)))
+}
+
+func (s3 *S3) signature(now time.Time, canonicalRequest string) string {
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		now.Format("20060102T150405Z"),
+		s3.scope(now),
+		hex.EncodeToString(canonicalHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s3.config.SecretKey), now.Format("20060102"))
+	key = hmacSHA256(key, s3.config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func checkS3Response(response *http.Response) error {
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+	return fmt.Errorf("s3 request failed with status %d: %s", response.StatusCode, body)
+}
@@ -0,0 +1,19 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+// Storage keeps uploaded media under slash separated keys such as costume/123_original.jpg,
+// the database only ever stores keys and URLs are generated per request.
+type Storage interface {
+	Put(ctx context.Context, key string, body []byte, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+	SignedURL(ctx context.Context, key string, expires time.Duration) (string, error)
+}
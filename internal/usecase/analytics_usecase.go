@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"context"
+	"cosplayrent/internal/helper"
+	"cosplayrent/internal/model/web/analytics"
+	"cosplayrent/internal/repository"
+	"cosplayrent/internal/storage"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/go-playground/validator"
+	"github.com/knadh/koanf/v2"
+	"github.com/rs/zerolog"
+)
+
+const (
+	analyticsDays          = 30
+	maxAnalyticsDays       = 366
+	analyticsTopListings   = 5
+	analyticsRatePrecision = 10
+)
+
+type AnalyticsUsecase struct {
+	StatRepository *repository.CostumeDailyStatRepository
+	Storage        storage.Storage
+	DB             *sql.DB
+	Validate       *validator.Validate
+	Log            *zerolog.Logger
+	Config         *koanf.Koanf
+}
+
+func NewAnalyticsUsecase(statRepository *repository.CostumeDailyStatRepository, storage storage.Storage, DB *sql.DB, validate *validator.Validate, zerolog *zerolog.Logger, koanf *koanf.Koanf) *AnalyticsUsecase {
+	return &AnalyticsUsecase{
+		StatRepository: statRepository,
+		Storage:        storage,
+		DB:             DB,
+		Validate:       validate,
+		Log:            zerolog,
+		Config:         koanf,
+	}
+}
+
+// FindSellerAnalytics reports on the seller's listings from Start_date to End_date, both included, the last 30 days
+// by default. Stats are rolled up with the trending refresh, so today's numbers can be a few minutes behind.
+func (usecase *AnalyticsUsecase) FindSellerAnalytics(ctx context.Context, analyticsRequest analytics.SellerAnalyticsRequest) (analytics.SellerAnalyticsResponse, error) {
+	today := helper.RentalDay(time.Now())
+	if analyticsRequest.End_date == "" {
+		analyticsRequest.End_date = today.Format(helper.RentalDateLayout)
+	}
+	if analyticsRequest.Start_date == "" {
+		end, err := time.Parse(helper.RentalDateLayout, analyticsRequest.End_date)
+		if err != nil {
+			end = today
+		}
+		analyticsRequest.Start_date = end.AddDate(0, 0, -(analyticsDays - 1)).Format(helper.RentalDateLayout)
+	}
+
+	start, end, err := helper.ParseRentalDates(analyticsRequest.Start_date, analyticsRequest.End_date)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return analytics.SellerAnalyticsResponse{}, err
+	}
+
+	if end.After(start.AddDate(0, 0, maxAnalyticsDays-1)) {
+		respErr := fmt.Errorf("the date range covers at most %d days", maxAnalyticsDays)
+		usecase.Log.Warn().Msg(respErr.Error())
+		return analytics.SellerAnalyticsResponse{}, respErr
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	startDate := start.Format(helper.RentalDateLayout)
+	endDate := end.Format(helper.RentalDateLayout)
+
+	totals := usecase.StatRepository.FindTotalsBySeller(ctx, tx, analyticsRequest.Seller_id, startDate, endDate)
+
+	analyticsResponse := analytics.SellerAnalyticsResponse{
+		Start_date: startDate,
+		End_date:   endDate,
+		Funnel: analytics.AnalyticsFunnelResponse{
+			Views:            totals.Views,
+			Wishlist_adds:    totals.Wishlist_adds,
+			Wishlist_removes: totals.Wishlist_removes,
+			Checkouts:        totals.Checkouts,
+			Completed_orders: totals.Completed_orders,
+			Checkout_rate:    percentage(totals.Checkouts, totals.Views),
+			Completion_rate:  percentage(totals.Completed_orders, totals.Checkouts),
+			Conversion_rate:  percentage(totals.Completed_orders, totals.Views),
+		},
+		Revenue: analytics.AnalyticsRevenueResponse{
+			Total: totals.Revenue,
+		},
+	}
+
+	// the chart gets every day of the range, days without activity are zero
+	daily := usecase.StatRepository.FindDailyBySeller(ctx, tx, analyticsRequest.Seller_id, startDate, endDate)
+	for day, i := start, 0; !day.After(end); day = day.AddDate(0, 0, 1) {
+		revenueDay := analytics.AnalyticsRevenueDayResponse{
+			Date: day.Format(helper.RentalDateLayout),
+		}
+		if i < len(daily) && daily[i].Day.Format(helper.RentalDateLayout) == revenueDay.Date {
+			revenueDay.Revenue = daily[i].Revenue
+			revenueDay.Completed_orders = daily[i].Completed_orders
+			i++
+		}
+		analyticsResponse.Revenue.Days = append(analyticsResponse.Revenue.Days, revenueDay)
+	}
+
+	analyticsResponse.Top_listings = usecase.StatRepository.FindTopBySeller(ctx, tx, analyticsRequest.Seller_id, startDate, endDate, analyticsTopListings)
+	for i := range analyticsResponse.Top_listings {
+		listing := &analyticsResponse.Top_listings[i]
+		listing.Conversion_rate = percentage(listing.Completed_orders, listing.Views)
+		if listing.Costume_picture != nil {
+			value := usecase.Storage.URL(*listing.Costume_picture)
+			listing.Costume_picture = &value
+		}
+	}
+
+	return analyticsResponse, nil
+}
+
+// percentage rounds part of whole to one decimal, nil when whole is zero.
+func percentage(part int, whole int) *float64 {
+	if whole == 0 {
+		return nil
+	}
+	value := math.Round(float64(part)/float64(whole)*100*analyticsRatePrecision) / analyticsRatePrecision
+	return &value
+}
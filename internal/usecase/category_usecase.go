@@ -3,30 +3,40 @@ package usecase
 import (
 	"context"
 	"cosplayrent/internal/helper"
+	"cosplayrent/internal/model/domain"
 	"cosplayrent/internal/model/web/category"
 	"cosplayrent/internal/repository"
 	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/go-playground/validator"
 	"github.com/knadh/koanf/v2"
 	"github.com/rs/zerolog"
+	"regexp"
+	"strings"
+	"time"
 )
 
+// attributeKeyPattern keeps attribute keys usable as attr.<key> query parameters.
+var attributeKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
 type CategoryUsecase struct {
-	CategoryRepository *repository.CategoryRepository
-	DB                 *sql.DB
-	Validate           *validator.Validate
-	Log                *zerolog.Logger
-	Config             *koanf.Koanf
+	CategoryRepository          *repository.CategoryRepository
+	CategoryAttributeRepository *repository.CategoryAttributeRepository
+	DB                          *sql.DB
+	Validate                    *validator.Validate
+	Log                         *zerolog.Logger
+	Config                      *koanf.Koanf
 }
 
-func NewCategoryUsecase(categoryRepository *repository.CategoryRepository, DB *sql.DB, validate *validator.Validate, zerolog *zerolog.Logger, koanf *koanf.Koanf) *CategoryUsecase {
+func NewCategoryUsecase(categoryRepository *repository.CategoryRepository, categoryAttributeRepository *repository.CategoryAttributeRepository, DB *sql.DB, validate *validator.Validate, zerolog *zerolog.Logger, koanf *koanf.Koanf) *CategoryUsecase {
 	return &CategoryUsecase{
-		CategoryRepository: categoryRepository,
-		DB:                 DB,
-		Validate:           validate,
-		Log:                zerolog,
-		Config:             koanf,
+		CategoryRepository:          categoryRepository,
+		CategoryAttributeRepository: categoryAttributeRepository,
+		DB:                          DB,
+		Validate:                    validate,
+		Log:                         zerolog,
+		Config:                      koanf,
 	}
 }
 
@@ -47,3 +57,524 @@ func (usecase *CategoryUsecase) FindAllCategory(ctx context.Context) ([]category
 
 	return categories, nil
 }
+
+func (usecase *CategoryUsecase) FindCategoryTree(ctx context.Context) []category.CategoryTreeResponse {
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	return buildCategoryTree(usecase.CategoryRepository.FindAllWithCostumeCount(ctx, tx))
+}
+
+func (usecase *CategoryUsecase) Create(ctx context.Context, categoryRequest category.CategoryCreateRequest) (category.CategoryResponse, error) {
+	err := usecase.Validate.Struct(categoryRequest)
+	if err != nil {
+		respErr := errors.New("invalid request body")
+		usecase.Log.Warn().Err(err).Msg(respErr.Error())
+		return category.CategoryResponse{}, respErr
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	usecase.CategoryRepository.LockTree(ctx, tx)
+
+	if categoryRequest.Parent_id != nil {
+		_, err = usecase.CategoryRepository.FindById(ctx, tx, *categoryRequest.Parent_id)
+		if err != nil {
+			respErr := errors.New("parent category not found")
+			usecase.Log.Warn().Msg(respErr.Error())
+			return category.CategoryResponse{}, respErr
+		}
+	}
+
+	if usecase.CategoryRepository.SiblingNameExists(ctx, tx, categoryRequest.Parent_id, categoryRequest.Name, 0) {
+		respErr := errors.New("category with the same name already exists under this parent")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return category.CategoryResponse{}, respErr
+	}
+
+	slug := categoryRequest.Slug
+	if slug == "" {
+		slug = usecase.uniqueSlug(ctx, tx, categoryRequest.Name)
+	} else {
+		err = usecase.checkSlug(ctx, tx, slug, 0)
+		if err != nil {
+			usecase.Log.Warn().Msg(err.Error())
+			return category.CategoryResponse{}, err
+		}
+	}
+
+	now := time.Now()
+
+	newCategory := domain.Category{
+		Parent_id:  categoryRequest.Parent_id,
+		Name:       categoryRequest.Name,
+		Slug:       slug,
+		Created_at: &now,
+		Updated_at: &now,
+	}
+
+	id := usecase.CategoryRepository.Create(ctx, tx, newCategory)
+
+	return category.CategoryResponse{
+		Id:        id,
+		Parent_id: newCategory.Parent_id,
+		Name:      newCategory.Name,
+		Slug:      newCategory.Slug,
+	}, nil
+}
+
+func (usecase *CategoryUsecase) Update(ctx context.Context, categoryRequest category.CategoryUpdateRequest) (category.CategoryResponse, error) {
+	patchErrors := []error{
+		helper.ValidatePatchField(usecase.Validate, "name", categoryRequest.Name, false, "min=2,max=100"),
+		helper.ValidatePatchField(usecase.Validate, "slug", categoryRequest.Slug, false, "max=120"),
+		helper.ValidatePatchField(usecase.Validate, "parent_id", categoryRequest.Parent_id, true, "min=1"),
+	}
+	for _, err := range patchErrors {
+		if err != nil {
+			usecase.Log.Warn().Msg(err.Error())
+			return category.CategoryResponse{}, err
+		}
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	usecase.CategoryRepository.LockTree(ctx, tx)
+
+	current, err := usecase.CategoryRepository.FindById(ctx, tx, categoryRequest.Id)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return category.CategoryResponse{}, err
+	}
+
+	parentId := current.Parent_id
+	if categoryRequest.Parent_id.Set {
+		parentId = nil
+		if !categoryRequest.Parent_id.Null {
+			parentId = &categoryRequest.Parent_id.Value
+
+			_, err = usecase.CategoryRepository.FindById(ctx, tx, *parentId)
+			if err != nil {
+				respErr := errors.New("parent category not found")
+				usecase.Log.Warn().Msg(respErr.Error())
+				return category.CategoryResponse{}, respErr
+			}
+
+			if usecase.CategoryRepository.InSubtree(ctx, tx, current.Id, *parentId) {
+				respErr := errors.New("category cannot be moved under itself or its subcategory")
+				usecase.Log.Warn().Msg(respErr.Error())
+				return category.CategoryResponse{}, respErr
+			}
+		}
+	}
+
+	name := current.Name
+	if categoryRequest.Name.Set {
+		name = categoryRequest.Name.Value
+	}
+
+	if usecase.CategoryRepository.SiblingNameExists(ctx, tx, parentId, name, current.Id) {
+		respErr := errors.New("category with the same name already exists under this parent")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return category.CategoryResponse{}, respErr
+	}
+
+	// renaming keeps the slug so links to the category stay valid, a new slug has to be asked for explicitly
+	slug := current.Slug
+	if categoryRequest.Slug.Set {
+		slug = categoryRequest.Slug.Value
+		err = usecase.checkSlug(ctx, tx, slug, current.Id)
+		if err != nil {
+			usecase.Log.Warn().Msg(err.Error())
+			return category.CategoryResponse{}, err
+		}
+	}
+
+	now := time.Now()
+
+	usecase.CategoryRepository.Update(ctx, tx, categoryRequest, &now)
+
+	return category.CategoryResponse{
+		Id:        current.Id,
+		Parent_id: parentId,
+		Name:      name,
+		Slug:      slug,
+	}, nil
+}
+
+// Delete refuses to drop a category that still holds costumes or subcategories unless a target to move them to is given.
+func (usecase *CategoryUsecase) Delete(ctx context.Context, categoryRequest category.CategoryDeleteRequest) error {
+	err := usecase.Validate.Struct(categoryRequest)
+	if err != nil {
+		respErr := errors.New("invalid reassign_to")
+		usecase.Log.Warn().Err(err).Msg(respErr.Error())
+		return respErr
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	usecase.CategoryRepository.LockTree(ctx, tx)
+
+	_, err = usecase.CategoryRepository.FindById(ctx, tx, categoryRequest.Id)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return err
+	}
+
+	costumeCount, childCount := usecase.CategoryRepository.CountUsage(ctx, tx, categoryRequest.Id)
+
+	if costumeCount > 0 || childCount > 0 {
+		if categoryRequest.Reassign_to == nil {
+			respErr := fmt.Errorf("category still has %d costumes and %d subcategories, set reassign_to to move them", costumeCount, childCount)
+			usecase.Log.Warn().Msg(respErr.Error())
+			return respErr
+		}
+
+		_, err = usecase.CategoryRepository.FindById(ctx, tx, *categoryRequest.Reassign_to)
+		if err != nil {
+			respErr := errors.New("reassign category not found")
+			usecase.Log.Warn().Msg(respErr.Error())
+			return respErr
+		}
+
+		if usecase.CategoryRepository.InSubtree(ctx, tx, categoryRequest.Id, *categoryRequest.Reassign_to) {
+			respErr := errors.New("cannot reassign to the deleted category or its subcategory")
+			usecase.Log.Warn().Msg(respErr.Error())
+			return respErr
+		}
+
+		attributeMoves, err := usecase.planAttributeMoves(ctx, tx, categoryRequest.Id, *categoryRequest.Reassign_to)
+		if err != nil {
+			usecase.Log.Warn().Msg(err.Error())
+			return err
+		}
+
+		now := time.Now()
+
+		usecase.CategoryRepository.Reassign(ctx, tx, categoryRequest.Id, *categoryRequest.Reassign_to, &now)
+
+		for _, move := range attributeMoves {
+			if move.into == 0 {
+				usecase.CategoryAttributeRepository.Move(ctx, tx, move.attribute.Id, *categoryRequest.Reassign_to, &now)
+			} else {
+				usecase.CategoryAttributeRepository.MergeValues(ctx, tx, move.attribute.Id, move.into)
+			}
+		}
+	}
+
+	usecase.CategoryRepository.Delete(ctx, tx, categoryRequest.Id)
+
+	return nil
+}
+
+// attributeMove carries an attribute of a deleted category over to the category its costumes move to, into is the
+// attribute with the same key there whose values it merges into, or 0 when the definition moves itself.
+type attributeMove struct {
+	attribute domain.CategoryAttribute
+	into      int
+}
+
+// planAttributeMoves works out where each attribute of the deleted category goes before anything is written, so a
+// conflicting definition rejects the delete instead of the values being dropped with the category.
+func (usecase *CategoryUsecase) planAttributeMoves(ctx context.Context, tx *sql.Tx, fromId int, toId int) ([]attributeMove, error) {
+	targets := map[string]domain.CategoryAttribute{}
+	for _, attribute := range usecase.CategoryAttributeRepository.FindEffective(ctx, tx, toId) {
+		targets[attribute.Key] = attribute
+	}
+
+	moves := []attributeMove{}
+	for _, attribute := range usecase.CategoryAttributeRepository.FindByCategory(ctx, tx, fromId) {
+		target, ok := targets[attribute.Key]
+		if !ok {
+			moves = append(moves, attributeMove{attribute: attribute})
+			continue
+		}
+
+		if target.Type != attribute.Type {
+			return nil, fmt.Errorf("attribute %s is %s here but %s in the reassign category", attribute.Key, attribute.Type, target.Type)
+		}
+
+		if target.Type == "enum" || target.Type == "multi_enum" {
+			if count := usecase.CategoryAttributeRepository.CountValuesOutsideOptions(ctx, tx, attribute.Id, target.Options); count > 0 {
+				return nil, fmt.Errorf("%d costumes have %s values the reassign category does not offer", count, attribute.Key)
+			}
+		}
+
+		moves = append(moves, attributeMove{attribute: attribute, into: target.Id})
+	}
+
+	return moves, nil
+}
+
+// FindAttributes lists the attributes costumes in the category can carry, including those inherited from parent categories.
+func (usecase *CategoryUsecase) FindAttributes(ctx context.Context, categoryId int) ([]category.CategoryAttributeResponse, error) {
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	_, err = usecase.CategoryRepository.FindById(ctx, tx, categoryId)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return nil, err
+	}
+
+	attributes := []category.CategoryAttributeResponse{}
+	for _, attribute := range usecase.CategoryAttributeRepository.FindEffective(ctx, tx, categoryId) {
+		attributes = append(attributes, categoryAttributeResponse(attribute))
+	}
+
+	return attributes, nil
+}
+
+func (usecase *CategoryUsecase) CreateAttribute(ctx context.Context, attributeRequest category.CategoryAttributeCreateRequest) (category.CategoryAttributeResponse, error) {
+	err := usecase.Validate.Struct(attributeRequest)
+	if err != nil {
+		respErr := errors.New("invalid request body")
+		usecase.Log.Warn().Err(err).Msg(respErr.Error())
+		return category.CategoryAttributeResponse{}, respErr
+	}
+
+	if !attributeKeyPattern.MatchString(attributeRequest.Key) {
+		respErr := errors.New("key may only contain lowercase letters, digits and underscores and must start with a letter")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return category.CategoryAttributeResponse{}, respErr
+	}
+
+	err = checkAttributeOptions(attributeRequest.Type, attributeRequest.Options)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return category.CategoryAttributeResponse{}, err
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	_, err = usecase.CategoryRepository.FindById(ctx, tx, attributeRequest.Category_id)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return category.CategoryAttributeResponse{}, err
+	}
+
+	if usecase.CategoryAttributeRepository.KeyExists(ctx, tx, attributeRequest.Category_id, attributeRequest.Key) {
+		respErr := errors.New("attribute key is already used in this category")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return category.CategoryAttributeResponse{}, respErr
+	}
+
+	now := time.Now()
+
+	attribute := domain.CategoryAttribute{
+		Category_id: attributeRequest.Category_id,
+		Key:         attributeRequest.Key,
+		Label:       attributeRequest.Label,
+		Type:        attributeRequest.Type,
+		Options:     attributeRequest.Options,
+		Required:    attributeRequest.Required,
+		Created_at:  &now,
+		Updated_at:  &now,
+	}
+	if attribute.Options == nil {
+		attribute.Options = []string{}
+	}
+
+	attribute.Id = usecase.CategoryAttributeRepository.Create(ctx, tx, attribute)
+
+	return categoryAttributeResponse(attribute), nil
+}
+
+func (usecase *CategoryUsecase) UpdateAttribute(ctx context.Context, attributeRequest category.CategoryAttributeUpdateRequest) (category.CategoryAttributeResponse, error) {
+	patchErrors := []error{
+		helper.ValidatePatchField(usecase.Validate, "label", attributeRequest.Label, false, "min=1,max=100"),
+		helper.ValidatePatchField(usecase.Validate, "options", attributeRequest.Options, false, "max=50,dive,required,max=50"),
+		helper.ValidatePatchField(usecase.Validate, "required", attributeRequest.Required, false, ""),
+	}
+	for _, err := range patchErrors {
+		if err != nil {
+			usecase.Log.Warn().Msg(err.Error())
+			return category.CategoryAttributeResponse{}, err
+		}
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	attribute, err := usecase.CategoryAttributeRepository.FindById(ctx, tx, attributeRequest.Category_id, attributeRequest.Id)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return category.CategoryAttributeResponse{}, err
+	}
+
+	if attributeRequest.Options.Set {
+		err = checkAttributeOptions(attribute.Type, attributeRequest.Options.Value)
+		if err != nil {
+			usecase.Log.Warn().Msg(err.Error())
+			return category.CategoryAttributeResponse{}, err
+		}
+
+		// removing an option that costumes still use would leave them with a value the attribute rejects
+		inUse := usecase.CategoryAttributeRepository.CountValuesOutsideOptions(ctx, tx, attribute.Id, attributeRequest.Options.Value)
+		if inUse > 0 {
+			respErr := fmt.Errorf("%d costumes still use an option that would be removed", inUse)
+			usecase.Log.Warn().Msg(respErr.Error())
+			return category.CategoryAttributeResponse{}, respErr
+		}
+
+		attribute.Options = attributeRequest.Options.Value
+	}
+	if attributeRequest.Label.Set {
+		attribute.Label = attributeRequest.Label.Value
+	}
+	if attributeRequest.Required.Set {
+		attribute.Required = attributeRequest.Required.Value
+	}
+
+	now := time.Now()
+
+	usecase.CategoryAttributeRepository.Update(ctx, tx, attributeRequest, &now)
+
+	return categoryAttributeResponse(attribute), nil
+}
+
+// DeleteAttribute also removes the values costumes stored for the attribute.
+func (usecase *CategoryUsecase) DeleteAttribute(ctx context.Context, categoryId int, attributeId int) error {
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	_, err = usecase.CategoryAttributeRepository.FindById(ctx, tx, categoryId, attributeId)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return err
+	}
+
+	usecase.CategoryAttributeRepository.Delete(ctx, tx, attributeId)
+
+	return nil
+}
+
+func checkAttributeOptions(attributeType string, options []string) error {
+	isEnum := attributeType == "enum" || attributeType == "multi_enum"
+	if isEnum && len(options) == 0 {
+		return errors.New(attributeType + " attributes need at least one option")
+	}
+	if !isEnum && len(options) > 0 {
+		return errors.New("only enum and multi_enum attributes have options")
+	}
+
+	seen := map[string]bool{}
+	for _, option := range options {
+		if seen[strings.ToLower(option)] {
+			return errors.New("duplicate option " + option)
+		}
+		seen[strings.ToLower(option)] = true
+	}
+	return nil
+}
+
+func categoryAttributeResponse(attribute domain.CategoryAttribute) category.CategoryAttributeResponse {
+	return category.CategoryAttributeResponse{
+		Id:          attribute.Id,
+		Category_id: attribute.Category_id,
+		Key:         attribute.Key,
+		Label:       attribute.Label,
+		Type:        attribute.Type,
+		Options:     attribute.Options,
+		Required:    attribute.Required,
+	}
+}
+
+func (usecase *CategoryUsecase) checkSlug(ctx context.Context, tx *sql.Tx, slug string, excludeId int) error {
+	if !helper.IsSlug(slug) {
+		return errors.New("slug may only contain lowercase letters, digits and single dashes")
+	}
+	if usecase.CategoryRepository.SlugExists(ctx, tx, slug, excludeId) {
+		return errors.New("slug is already used")
+	}
+	return nil
+}
+
+// uniqueSlug derives a slug from the name and appends -2, -3, ... until it no longer collides.
+func (usecase *CategoryUsecase) uniqueSlug(ctx context.Context, tx *sql.Tx, name string) string {
+	base := helper.Slugify(name)
+	if base == "" {
+		base = "category"
+	}
+
+	slug := base
+	for i := 2; usecase.CategoryRepository.SlugExists(ctx, tx, slug, 0); i++ {
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+	return slug
+}
+
+// buildCategoryTree nests the flat list under its parents and sums the costume counts of every subtree.
+func buildCategoryTree(categories []category.CategoryTreeResponse) []category.CategoryTreeResponse {
+	childrenOf := map[int][]int{}
+	roots := []int{}
+	for i, node := range categories {
+		if node.Parent_id == nil {
+			roots = append(roots, i)
+		} else {
+			childrenOf[*node.Parent_id] = append(childrenOf[*node.Parent_id], i)
+		}
+	}
+
+	var build func(index int) category.CategoryTreeResponse
+	build = func(index int) category.CategoryTreeResponse {
+		node := categories[index]
+		node.Total_costume_count = node.Costume_count
+		node.Children = []category.CategoryTreeResponse{}
+		for _, childIndex := range childrenOf[node.Id] {
+			child := build(childIndex)
+			node.Total_costume_count += child.Total_costume_count
+			node.Children = append(node.Children, child)
+		}
+		return node
+	}
+
+	tree := []category.CategoryTreeResponse{}
+	for _, index := range roots {
+		tree = append(tree, build(index))
+	}
+	return tree
+}
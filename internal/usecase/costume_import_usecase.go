@@ -0,0 +1,469 @@
+package usecase
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"cosplayrent/internal/helper"
+	"cosplayrent/internal/model/web/category"
+	"cosplayrent/internal/model/web/costume"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator"
+)
+
+const (
+	maxImportRows       = 200
+	maxImportImageBytes = 5 * 1024 * 1024
+)
+
+// costumeCSVColumns is the layout written by Export, Import accepts the columns in any order and ignores id.
+var costumeCSVColumns = []string{"id", "name", "description", "bahan", "ukuran", "berat", "kategori", "price", "status", "images", "variants", "attributes"}
+
+var requiredImportColumns = []string{"name", "description", "bahan", "ukuran", "berat", "kategori", "price", "images"}
+
+type costumeImportRow struct {
+	line    int
+	request costume.CostumeCreateRequest
+	errors  []string
+}
+
+// Import creates one listing per csv row. Rows are independent, a row that fails validation or creation is reported
+// and skipped. Images are http(s) urls or file names inside the optional zip, separated by "|". Imported listings
+// reach the followers' feed without a notification each.
+func (usecase *CostumeUsecase) Import(ctx context.Context, uuid string, file io.Reader, images *zip.Reader) (costume.CostumeImportResponse, error) {
+	categories, err := usecase.importCategories(ctx, uuid)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return costume.CostumeImportResponse{}, err
+	}
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		respErr := errors.New("csv file is empty or malformed")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return costume.CostumeImportResponse{}, respErr
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, ok := columns[name]; !ok {
+			columns[name] = i
+		}
+	}
+	for _, name := range requiredImportColumns {
+		if _, ok := columns[name]; !ok {
+			respErr := fmt.Errorf("csv is missing the %s column", name)
+			usecase.Log.Warn().Msg(respErr.Error())
+			return costume.CostumeImportResponse{}, respErr
+		}
+	}
+
+	rows := []costumeImportRow{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			respErr := fmt.Errorf("invalid csv: %s", err.Error())
+			usecase.Log.Warn().Msg(respErr.Error())
+			return costume.CostumeImportResponse{}, respErr
+		}
+		if len(rows) == maxImportRows {
+			respErr := fmt.Errorf("csv can have at most %d rows", maxImportRows)
+			usecase.Log.Warn().Msg(respErr.Error())
+			return costume.CostumeImportResponse{}, respErr
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, parseCostumeImportRow(line, record, columns, categories))
+	}
+
+	if len(rows) == 0 {
+		respErr := errors.New("csv has no rows")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return costume.CostumeImportResponse{}, respErr
+	}
+
+	zipImages := map[string]*zip.File{}
+	if images != nil {
+		for _, zipFile := range images.File {
+			name := strings.ToLower(path.Base(zipFile.Name))
+			if _, ok := zipImages[name]; !ok && !zipFile.FileInfo().IsDir() {
+				zipImages[name] = zipFile
+			}
+		}
+	}
+
+	report := costume.CostumeImportResponse{
+		Total: len(rows),
+		Rows:  make([]costume.CostumeImportRowResponse, len(rows)),
+	}
+
+	for i, row := range rows {
+		row.errors = usecase.validateImportRow(row.request, row.errors)
+
+		if len(row.errors) == 0 {
+			var imagePaths []string
+			imagePaths, row.errors = usecase.saveImportImages(ctx, row.request.Images, zipImages)
+			if len(row.errors) == 0 {
+				row.request.Images = imagePaths
+				// create writes nothing when it fails, so the images have no listing pointing at them
+				costumeId, _, err := usecase.create(ctx, row.request, uuid, false)
+				if err != nil {
+					for _, imagePath := range imagePaths {
+						helper.RemoveImage(ctx, usecase.Storage, imagePath)
+					}
+					row.errors = []string{err.Error()}
+				} else {
+					report.Rows[i].Costume_id = &costumeId
+				}
+			}
+		}
+
+		status := "created"
+		if report.Rows[i].Costume_id != nil {
+			report.Created++
+		} else {
+			status = "failed"
+			report.Failed++
+		}
+
+		report.Rows[i].Row = row.line
+		report.Rows[i].Name = row.request.Name
+		report.Rows[i].Status = status
+		report.Rows[i].Errors = row.errors
+	}
+
+	return report, nil
+}
+
+// Export writes the seller's listings that are not archived in the layout Import reads back.
+func (usecase *CostumeUsecase) Export(ctx context.Context, uuid string) [][]string {
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	records := [][]string{costumeCSVColumns}
+
+	sellerCostumes, err := usecase.CostumeRepository.FindSellerCostume(ctx, tx, uuid)
+	if err != nil {
+		return records
+	}
+
+	categories, _ := usecase.CategoryRepository.FindAllCategory(ctx, tx)
+	categorySlugs := map[string]string{}
+	for _, category := range categories {
+		categorySlugs[strconv.Itoa(category.Id)] = category.Slug
+	}
+
+	costumeIds := make([]int, len(sellerCostumes))
+	for i := range sellerCostumes {
+		costumeIds[i] = sellerCostumes[i].Id
+	}
+	imagesByCostume := usecase.CostumeImageRepository.FindByCostumeIds(ctx, tx, costumeIds)
+	attributesByCostume := usecase.CostumeAttributeRepository.FindByCostumeIds(ctx, tx, costumeIds)
+	variantsByCostume := usecase.VariantRepository.FindByCostumeIds(ctx, tx, costumeIds)
+
+	for _, sellerCostume := range sellerCostumes {
+		imageURLs := []string{}
+		for _, image := range imagesByCostume[sellerCostume.Id] {
+			imageURLs = append(imageURLs, usecase.Storage.URL(image.Url))
+		}
+
+		variants := []string{}
+		for _, variant := range variantsByCostume[sellerCostume.Id] {
+			value := fmt.Sprintf("%s:%d", variant.Size, variant.Quantity)
+			if variant.Price != nil {
+				value += ":" + strconv.FormatFloat(*variant.Price, 'f', -1, 64)
+			}
+			variants = append(variants, value)
+		}
+
+		attributes := ""
+		if len(attributesByCostume[sellerCostume.Id]) > 0 {
+			values := map[string]json.RawMessage{}
+			for _, attribute := range attributesByCostume[sellerCostume.Id] {
+				values[attribute.Key] = attribute.Value
+			}
+			encoded, err := json.Marshal(values)
+			if err != nil {
+				respErr := errors.New("failed to encode costume attributes")
+				usecase.Log.Panic().Err(err).Msg(respErr.Error())
+			}
+			attributes = string(encoded)
+		}
+
+		kategori, ok := categorySlugs[sellerCostume.Kategori]
+		if !ok {
+			kategori = sellerCostume.Kategori
+		}
+
+		ukuran := ""
+		if sellerCostume.Ukuran != nil {
+			ukuran = *sellerCostume.Ukuran
+		}
+
+		records = append(records, []string{
+			strconv.Itoa(sellerCostume.Id),
+			sellerCostume.Name,
+			sellerCostume.Description,
+			sellerCostume.Bahan,
+			ukuran,
+			strconv.Itoa(sellerCostume.Berat),
+			kategori,
+			strconv.FormatFloat(sellerCostume.Price, 'f', -1, 64),
+			sellerCostume.Status,
+			strings.Join(imageURLs, "|"),
+			strings.Join(variants, "|"),
+			attributes,
+		})
+	}
+
+	return records
+}
+
+// importCategories checks the seller once for the whole file and returns the categories keyed by id, slug and name.
+// A name shared by categories under different parents maps to nil, those have to be referenced by slug.
+func (usecase *CostumeUsecase) importCategories(ctx context.Context, uuid string) (map[string]*category.CategoryResponse, error) {
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	_, err = usecase.UserRepository.CheckUserStatus(ctx, tx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, _ := usecase.CategoryRepository.FindAllCategory(ctx, tx)
+
+	categoriesByKey := map[string]*category.CategoryResponse{}
+	for i := range categories {
+		categoriesByKey[strconv.Itoa(categories[i].Id)] = &categories[i]
+		categoriesByKey[categories[i].Slug] = &categories[i]
+	}
+	for i := range categories {
+		name := strings.ToLower(categories[i].Name)
+		if existing, ok := categoriesByKey[name]; ok && existing != &categories[i] {
+			categoriesByKey[name] = nil
+			continue
+		}
+		categoriesByKey[name] = &categories[i]
+	}
+
+	return categoriesByKey, nil
+}
+
+func parseCostumeImportRow(line int, record []string, columns map[string]int, categories map[string]*category.CategoryResponse) costumeImportRow {
+	cell := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	row := costumeImportRow{
+		line: line,
+		request: costume.CostumeCreateRequest{
+			Name:        cell("name"),
+			Description: cell("description"),
+			Bahan:       cell("bahan"),
+			Ukuran:      cell("ukuran"),
+			Status:      cell("status"),
+		},
+		errors: []string{},
+	}
+
+	if value := cell("berat"); value != "" {
+		berat, err := strconv.Atoi(value)
+		if err != nil {
+			row.errors = append(row.errors, "berat must be a whole number")
+		}
+		row.request.Berat = berat
+	}
+
+	if value := cell("price"); value != "" {
+		price, err := strconv.ParseFloat(value, 64)
+		if err != nil || price <= 0 {
+			row.errors = append(row.errors, "price must be a positive number")
+		}
+		row.request.Price = price
+	}
+
+	if value := cell("kategori"); value != "" {
+		category, ok := categories[strings.ToLower(value)]
+		if !ok {
+			row.errors = append(row.errors, fmt.Sprintf("kategori %s not found", value))
+		} else if category == nil {
+			row.errors = append(row.errors, fmt.Sprintf("kategori %s matches more than one category, use its slug", value))
+		} else {
+			row.request.Kategori = category.Id
+		}
+	}
+
+	if value := cell("images"); value != "" {
+		for _, image := range strings.Split(value, "|") {
+			row.request.Images = append(row.request.Images, strings.TrimSpace(image))
+		}
+	}
+
+	if value := cell("variants"); value != "" {
+		for _, variant := range strings.Split(value, "|") {
+			parts := strings.Split(strings.TrimSpace(variant), ":")
+			if len(parts) < 2 || len(parts) > 3 {
+				row.errors = append(row.errors, "variants must look like S:2|M:1:150000")
+				break
+			}
+
+			quantity, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+			if err != nil {
+				row.errors = append(row.errors, "variants must look like S:2|M:1:150000")
+				break
+			}
+
+			variantRequest := costume.CostumeVariantRequest{Size: strings.TrimSpace(parts[0]), Quantity: quantity}
+			if len(parts) == 3 {
+				price, err := strconv.ParseFloat(strings.TrimSpace(parts[2]), 64)
+				if err != nil {
+					row.errors = append(row.errors, "variants must look like S:2|M:1:150000")
+					break
+				}
+				variantRequest.Price = &price
+			}
+			row.request.Variants = append(row.request.Variants, variantRequest)
+		}
+	}
+
+	if value := cell("attributes"); value != "" {
+		err := json.Unmarshal([]byte(value), &row.request.Attributes)
+		if err != nil {
+			row.errors = append(row.errors, "attributes must be a json object")
+		}
+	}
+
+	return row
+}
+
+// validateImportRow applies the CostumeCreateRequest rules and reports every failing field instead of the first one,
+// fields that already failed to parse are not reported twice.
+func (usecase *CostumeUsecase) validateImportRow(request costume.CostumeCreateRequest, rowErrors []string) []string {
+	parseErrors := slices.Clone(rowErrors)
+
+	err := usecase.Validate.Struct(request)
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		if !errors.As(err, &validationErrors) {
+			return append(rowErrors, err.Error())
+		}
+
+		for _, fieldError := range validationErrors {
+			field := strings.ToLower(strings.TrimPrefix(fieldError.Namespace(), "CostumeCreateRequest."))
+			column, _, _ := strings.Cut(strings.Split(field, ".")[0], "[")
+			if slices.ContainsFunc(parseErrors, func(parseError string) bool { return strings.HasPrefix(parseError, column+" ") }) {
+				continue
+			}
+			message := fmt.Sprintf("%s failed on %s", field, fieldError.Tag())
+			if fieldError.Param() != "" {
+				message += "=" + fieldError.Param()
+			}
+			rowErrors = append(rowErrors, message)
+		}
+	}
+
+	if len(request.Images) > maxCostumeImages {
+		rowErrors = append(rowErrors, fmt.Sprintf("a costume can have at most %d images", maxCostumeImages))
+	}
+
+	sizes := []string{}
+	for _, variant := range request.Variants {
+		size := strings.ToLower(variant.Size)
+		if slices.Contains(sizes, size) {
+			rowErrors = append(rowErrors, "variant sizes must be unique")
+			break
+		}
+		sizes = append(sizes, size)
+	}
+
+	return rowErrors
+}
+
+// saveImportImages stores a row's images, nothing stays behind when one of them fails.
+func (usecase *CostumeUsecase) saveImportImages(ctx context.Context, references []string, zipImages map[string]*zip.File) ([]string, []string) {
+	imagePaths := []string{}
+	for _, reference := range references {
+		data, err := usecase.loadImportImage(ctx, reference, zipImages)
+		if err == nil {
+			var imagePath string
+			imagePath, err = helper.SaveImage(ctx, usecase.Storage, bytes.NewReader(data), "costume")
+			if err != nil && !errors.Is(err, helper.ErrInvalidImage) {
+				respErr := errors.New("failed to save costume image")
+				usecase.Log.Panic().Err(err).Msg(respErr.Error())
+			}
+			imagePaths = append(imagePaths, imagePath)
+		}
+
+		if err != nil {
+			for _, imagePath := range imagePaths {
+				if imagePath != "" {
+					helper.RemoveImage(ctx, usecase.Storage, imagePath)
+				}
+			}
+			return nil, []string{fmt.Sprintf("image %s: %s", reference, err.Error())}
+		}
+	}
+
+	return imagePaths, nil
+}
+
+func (usecase *CostumeUsecase) loadImportImage(ctx context.Context, reference string, zipImages map[string]*zip.File) ([]byte, error) {
+	if strings.HasPrefix(reference, "http://") || strings.HasPrefix(reference, "https://") {
+		return helper.FetchImage(ctx, reference, maxImportImageBytes)
+	}
+
+	zipFile, ok := zipImages[strings.ToLower(path.Base(reference))]
+	if !ok {
+		return nil, errors.New("not found in the images zip")
+	}
+	if zipFile.UncompressedSize64 > maxImportImageBytes {
+		return nil, errors.New("image exceeds 5 mb")
+	}
+
+	file, err := zipFile.Open()
+	if err != nil {
+		return nil, errors.New("could not read image from the zip")
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxImportImageBytes+1))
+	if err != nil {
+		return nil, errors.New("could not read image from the zip")
+	}
+	if len(data) > maxImportImageBytes {
+		return nil, errors.New("image exceeds 5 mb")
+	}
+
+	return data, nil
+}
@@ -1,49 +1,149 @@
 package usecase
 
 import (
+	"bytes"
 	"context"
 	"cosplayrent/internal/helper"
 	"cosplayrent/internal/model/domain"
 	"cosplayrent/internal/model/web/costume"
 	"cosplayrent/internal/repository"
+	"cosplayrent/internal/storage"
 	"database/sql"
+	"encoding/json"
 	"errors"
-	"os"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/go-playground/validator"
 	"github.com/knadh/koanf/v2"
 	"github.com/rs/zerolog"
+	"gopkg.in/gomail.v2"
 )
 
+// maxCostumeImages caps the photos a single costume can carry.
+const maxCostumeImages = 8
+
+// restockFeedInterval keeps a seller tuning quantities from flooding the followers' feed.
+const restockFeedInterval = 24 * time.Hour
+
+// viewInterval is how long repeated views of a listing by the same user or visitor count as one.
+const viewInterval = 30 * time.Minute
+
+const (
+	availabilityCalendarDays    = 30
+	maxAvailabilityCalendarDays = 90
+	maxBlackoutDays             = 365
+)
+
+const similarLimit = 10
+
+// defaultSimilarAttributeKeys are the attributes compared for similar costumes unless SIMILAR_ATTRIBUTE_KEYS lists others.
+const defaultSimilarAttributeKeys = "franchise,character,series"
+
+// costumeStatusTransitions lists where a listing can go from each status, archived is final.
+var costumeStatusTransitions = map[string][]string{
+	"draft":     {"published", "archived"},
+	"published": {"paused", "archived"},
+	"paused":    {"published", "archived"},
+}
+
 type CostumeUsecase struct {
-	UserRepository     *repository.UserRepository
-	CostumeRepository  *repository.CostumeRepository
-	CategoryRepository *repository.CategoryRepository
-	DB                 *sql.DB
-	Validate           *validator.Validate
-	Log                *zerolog.Logger
-	Config             *koanf.Koanf
+	UserRepository              *repository.UserRepository
+	CostumeRepository           *repository.CostumeRepository
+	CostumeImageRepository      *repository.CostumeImageRepository
+	CategoryRepository          *repository.CategoryRepository
+	CategoryAttributeRepository *repository.CategoryAttributeRepository
+	CostumeAttributeRepository  *repository.CostumeAttributeRepository
+	MeasurementRepository       *repository.CostumeMeasurementRepository
+	UserMeasurementRepository   *repository.UserMeasurementRepository
+	VariantRepository           *repository.CostumeVariantRepository
+	PricingRepository           *repository.CostumePricingRepository
+	BlackoutRepository          *repository.CostumeBlackoutRepository
+	FollowRepository            *repository.FollowRepository
+	ActivityRepository          *repository.CostumeActivityRepository
+	EventRepository             *repository.CostumeEventRepository
+	NotificationUsecase         *NotificationUsecase
+	Storage                     storage.Storage
+	DB                          *sql.DB
+	Validate                    *validator.Validate
+	Log                         *zerolog.Logger
+	Config                      *koanf.Koanf
 }
 
-func NewCostumeUsecase(userRepository *repository.UserRepository, costumeRepository *repository.CostumeRepository, categoryRepository *repository.CategoryRepository, DB *sql.DB, validate *validator.Validate, zerolog *zerolog.Logger, koanf *koanf.Koanf) *CostumeUsecase {
+func NewCostumeUsecase(userRepository *repository.UserRepository, costumeRepository *repository.CostumeRepository, costumeImageRepository *repository.CostumeImageRepository, categoryRepository *repository.CategoryRepository, categoryAttributeRepository *repository.CategoryAttributeRepository, costumeAttributeRepository *repository.CostumeAttributeRepository, measurementRepository *repository.CostumeMeasurementRepository, userMeasurementRepository *repository.UserMeasurementRepository, variantRepository *repository.CostumeVariantRepository, pricingRepository *repository.CostumePricingRepository, blackoutRepository *repository.CostumeBlackoutRepository, followRepository *repository.FollowRepository, activityRepository *repository.CostumeActivityRepository, eventRepository *repository.CostumeEventRepository, notificationUsecase *NotificationUsecase, storage storage.Storage, DB *sql.DB, validate *validator.Validate, zerolog *zerolog.Logger, koanf *koanf.Koanf) *CostumeUsecase {
 	return &CostumeUsecase{
-		UserRepository:     userRepository,
-		CostumeRepository:  costumeRepository,
-		CategoryRepository: categoryRepository,
-		DB:                 DB,
-		Validate:           validate,
-		Log:                zerolog,
-		Config:             koanf,
+		UserRepository:              userRepository,
+		CostumeRepository:           costumeRepository,
+		CostumeImageRepository:      costumeImageRepository,
+		CategoryRepository:          categoryRepository,
+		CategoryAttributeRepository: categoryAttributeRepository,
+		CostumeAttributeRepository:  costumeAttributeRepository,
+		MeasurementRepository:       measurementRepository,
+		UserMeasurementRepository:   userMeasurementRepository,
+		VariantRepository:           variantRepository,
+		PricingRepository:           pricingRepository,
+		BlackoutRepository:          blackoutRepository,
+		FollowRepository:            followRepository,
+		ActivityRepository:          activityRepository,
+		EventRepository:             eventRepository,
+		NotificationUsecase:         notificationUsecase,
+		Storage:                     storage,
+		DB:                          DB,
+		Validate:                    validate,
+		Log:                         zerolog,
+		Config:                      koanf,
 	}
 }
 
 func (usecase *CostumeUsecase) Create(ctx context.Context, userRequest costume.CostumeCreateRequest, uuid string) error {
+	_, emails, err := usecase.create(ctx, userRequest, uuid, true)
+	if err != nil {
+		return err
+	}
+
+	usecase.NotificationUsecase.SendEmails(emails)
+
+	return nil
+}
+
+// create stores a new listing and returns its id with the follower emails to send once it has committed,
+// notifyFollowers is off for bulk imports so followers are not sent one email per row. Nothing is written when it
+// returns an error.
+func (usecase *CostumeUsecase) create(ctx context.Context, userRequest costume.CostumeCreateRequest, uuid string, notifyFollowers bool) (int, []*gomail.Message, error) {
 	err := usecase.Validate.Struct(userRequest)
 	if err != nil {
 		respErr := errors.New("invalid request body")
 		usecase.Log.Warn().Err(respErr).Msg(err.Error())
-		return respErr
+		return 0, nil, respErr
+	}
+
+	if len(userRequest.Images) > maxCostumeImages {
+		respErr := fmt.Errorf("a costume can have at most %d images", maxCostumeImages)
+		usecase.Log.Warn().Msg(respErr.Error())
+		return 0, nil, respErr
+	}
+
+	if userRequest.Status == "" {
+		userRequest.Status = "published"
+	}
+
+	// listings created without variants keep working as a single unit in the listing's own size
+	if len(userRequest.Variants) == 0 {
+		userRequest.Variants = []costume.CostumeVariantRequest{{Size: userRequest.Ukuran, Quantity: 1}}
+	}
+
+	sizes := map[string]bool{}
+	for _, variant := range userRequest.Variants {
+		size := strings.ToLower(strings.TrimSpace(variant.Size))
+		if sizes[size] {
+			respErr := errors.New("variant sizes must be unique")
+			usecase.Log.Warn().Msg(respErr.Error())
+			return 0, nil, respErr
+		}
+		sizes[size] = true
 	}
 
 	tx, err := usecase.DB.Begin()
@@ -54,6 +154,25 @@ func (usecase *CostumeUsecase) Create(ctx context.Context, userRequest costume.C
 
 	defer helper.CommitOrRollback(tx)
 
+	seller, err := usecase.UserRepository.CheckUserStatus(ctx, tx, uuid)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return 0, nil, err
+	}
+
+	_, err = usecase.CategoryRepository.FindCategoryNameById(ctx, tx, userRequest.Kategori)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return 0, nil, err
+	}
+
+	// every check runs before the first write, returning an error still commits the transaction
+	attributeChanges, err := usecase.checkCostumeAttributes(ctx, tx, 0, userRequest.Kategori, userRequest.Attributes)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return 0, nil, err
+	}
+
 	now := time.Now()
 
 	costumeDomain := domain.Costume{
@@ -65,22 +184,82 @@ func (usecase *CostumeUsecase) Create(ctx context.Context, userRequest costume.C
 		Berat:       userRequest.Berat,
 		Kategori:    userRequest.Kategori,
 		Price:       userRequest.Price,
-		Picture:     *userRequest.Picture,
+		Picture:     userRequest.Images[0],
+		Status:      userRequest.Status,
 		Created_at:  &now,
 		Updated_at:  &now,
 	}
 
-	usecase.CostumeRepository.Create(ctx, tx, costumeDomain)
+	costumeId := usecase.CostumeRepository.Create(ctx, tx, costumeDomain)
 
-	return nil
+	for i, path := range userRequest.Images {
+		usecase.CostumeImageRepository.Create(ctx, tx, domain.CostumeImage{
+			Costume_id: costumeId,
+			Path:       path,
+			Position:   i,
+			Is_cover:   i == 0,
+			Created_at: &now,
+		})
+	}
+
+	for _, variant := range userRequest.Variants {
+		usecase.VariantRepository.Create(ctx, tx, domain.CostumeVariant{
+			Costume_id: costumeId,
+			Size:       strings.TrimSpace(variant.Size),
+			Quantity:   variant.Quantity,
+			Price:      variant.Price,
+			Created_at: &now,
+			Updated_at: &now,
+		})
+	}
+
+	usecase.saveCostumeAttributes(ctx, tx, costumeId, attributeChanges)
+
+	var emails []*gomail.Message
+	if costumeDomain.Status == "published" {
+		emails = usecase.announceNewListing(ctx, tx, uuid, seller.Name, costumeId, costumeDomain.Name, notifyFollowers, &now)
+	}
+
+	return costumeId, emails, nil
 }
 
-func (usecase *CostumeUsecase) Update(ctx context.Context, userRequest costume.CostumeUpdateRequest, uuid string) error {
-	err := usecase.Validate.Struct(userRequest)
+func (usecase *CostumeUsecase) Update(ctx context.Context, userRequest costume.CostumeUpdateRequest, uuid string) (costume.CostumeResponse, error) {
+	costumeResponse, replacedPath, err := usecase.updateRow(ctx, userRequest, uuid)
 	if err != nil {
-		respErr := errors.New("invalid request body")
-		usecase.Log.Warn().Err(respErr).Msg(err.Error())
-		return respErr
+		return costume.CostumeResponse{}, err
+	}
+
+	// the row points at the new cover once updateRow has committed, a file that fails to go is only left unreferenced
+	if replacedPath != "" {
+		err = helper.RemoveImage(ctx, usecase.Storage, replacedPath)
+		if err != nil {
+			respErr := errors.New("failed to remove costume image")
+			usecase.Log.Warn().Err(respErr).Msg(err.Error())
+		}
+	}
+
+	return costumeResponse, nil
+}
+
+// updateRow applies the update and returns the updated costume with the path of the cover a new costume_picture
+// replaced, the files are left for the caller to delete after the commit.
+func (usecase *CostumeUsecase) updateRow(ctx context.Context, userRequest costume.CostumeUpdateRequest, uuid string) (costume.CostumeResponse, string, error) {
+	patchErrors := []error{
+		helper.ValidatePatchField(usecase.Validate, "name", userRequest.Name, false, "min=5,max=30"),
+		helper.ValidatePatchField(usecase.Validate, "description", userRequest.Description, false, "min=5,max=1000"),
+		helper.ValidatePatchField(usecase.Validate, "bahan", userRequest.Bahan, false, "min=5,max=30"),
+		helper.ValidatePatchField(usecase.Validate, "ukuran", userRequest.Ukuran, false, "min=1,max=4"),
+		helper.ValidatePatchField(usecase.Validate, "berat", userRequest.Berat, false, "min=1"),
+		helper.ValidatePatchField(usecase.Validate, "kategori", userRequest.Kategori, false, "min=1"),
+		helper.ValidatePatchField(usecase.Validate, "price", userRequest.Price, false, "gt=0"),
+		helper.ValidatePatchField(usecase.Validate, "costume_picture", userRequest.Picture, false, "max=255"),
+		helper.ValidatePatchField(usecase.Validate, "attributes", userRequest.Attributes, false, "max=30"),
+	}
+	for _, err := range patchErrors {
+		if err != nil {
+			usecase.Log.Warn().Msg(err.Error())
+			return costume.CostumeResponse{}, "", err
+		}
 	}
 
 	tx, err := usecase.DB.Begin()
@@ -91,35 +270,165 @@ func (usecase *CostumeUsecase) Update(ctx context.Context, userRequest costume.C
 
 	defer helper.CommitOrRollback(tx)
 
+	err = usecase.CostumeRepository.CheckCostume(ctx, tx, uuid, userRequest.Id)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return costume.CostumeResponse{}, "", err
+	}
+
+	if userRequest.Kategori.Set {
+		_, err = usecase.CategoryRepository.FindCategoryNameById(ctx, tx, userRequest.Kategori.Value)
+		if err != nil {
+			usecase.Log.Warn().Msg(err.Error())
+			return costume.CostumeResponse{}, "", err
+		}
+	}
+
+	// a new category can bring other attributes, so stored values are checked again even without new ones. The check
+	// runs before any write because returning an error still commits the transaction.
+	checkAttributes := userRequest.Attributes.Set || userRequest.Kategori.Set
+	attributeChanges := costumeAttributeChanges{}
+	if checkAttributes {
+		current, err := usecase.CostumeRepository.FindById(ctx, tx, userRequest.Id)
+		if err != nil {
+			usecase.Log.Warn().Msg(err.Error())
+			return costume.CostumeResponse{}, "", err
+		}
+
+		categoryId := current.Kategori_id
+		if userRequest.Kategori.Set {
+			categoryId = userRequest.Kategori.Value
+		}
+
+		attributeChanges, err = usecase.checkCostumeAttributes(ctx, tx, userRequest.Id, categoryId, userRequest.Attributes.Value)
+		if err != nil {
+			usecase.Log.Warn().Msg(err.Error())
+			return costume.CostumeResponse{}, "", err
+		}
+	}
+
+	var replacedPath string
+	if userRequest.Picture.Set {
+		current, err := usecase.CostumeRepository.FindById(ctx, tx, userRequest.Id)
+		if err != nil {
+			usecase.Log.Warn().Msg(err.Error())
+			return costume.CostumeResponse{}, "", err
+		}
+		if current.Picture != nil && *current.Picture != userRequest.Picture.Value {
+			replacedPath = *current.Picture
+		}
+	}
+
 	now := time.Now()
 
-	costumeDomain := domain.Costume{
-		Id:          userRequest.Id,
-		User_id:     uuid,
-		Name:        userRequest.Name,
-		Description: userRequest.Description,
-		Bahan:       userRequest.Bahan,
-		Ukuran:      userRequest.Ukuran,
-		Berat:       userRequest.Berat,
-		Kategori:    userRequest.Kategori,
-		Available:   userRequest.Available,
-		Price:       userRequest.Price,
-		Picture:     *userRequest.Picture,
-		Updated_at:  &now,
+	usecase.CostumeRepository.Update(ctx, tx, userRequest, &now)
+
+	if userRequest.Picture.Set {
+		usecase.CostumeImageRepository.UpdateCoverPath(ctx, tx, userRequest.Id, userRequest.Picture.Value)
+	}
+
+	if checkAttributes {
+		usecase.saveCostumeAttributes(ctx, tx, userRequest.Id, attributeChanges)
 	}
 
-	err = usecase.CostumeRepository.CheckCostume(ctx, tx, uuid, costumeDomain.Id)
+	costumeResponse, err := usecase.findSellerCostume(ctx, tx, uuid, userRequest.Id)
 	if err != nil {
-		usecase.Log.Warn().Msg(err.Error())
-		return err
+		return costume.CostumeResponse{}, "", err
 	}
 
-	usecase.CostumeRepository.Update(ctx, tx, costumeDomain)
+	return costumeResponse, replacedPath, nil
+}
 
-	return nil
+func (usecase *CostumeUsecase) FindAll(ctx context.Context, searchRequest costume.CostumeSearchRequest) (costume.CostumeListResponse, error) {
+	if searchRequest.Sort == "" {
+		searchRequest.Sort = "newest"
+	}
+	if searchRequest.Unpaged {
+		searchRequest.Limit = 50
+	}
+	if searchRequest.Limit == 0 {
+		searchRequest.Limit = 20
+	}
+
+	err := usecase.Validate.Struct(searchRequest)
+	if err != nil {
+		respErr := errors.New("invalid search parameters")
+		usecase.Log.Warn().Err(respErr).Msg(err.Error())
+		return costume.CostumeListResponse{}, respErr
+	}
+
+	if searchRequest.Min_price != nil && searchRequest.Max_price != nil && *searchRequest.Min_price > *searchRequest.Max_price {
+		respErr := errors.New("min_price must not be greater than max_price")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return costume.CostumeListResponse{}, respErr
+	}
+
+	if searchRequest.Cursor != "" {
+		cursor, err := helper.DecodeCursor(searchRequest.Cursor)
+		if err != nil || cursor.Sort != searchRequest.Sort {
+			respErr := errors.New("invalid cursor")
+			usecase.Log.Warn().Msg(respErr.Error())
+			return costume.CostumeListResponse{}, respErr
+		}
+		searchRequest.After_value = &cursor.Value
+		searchRequest.After_id = cursor.Id
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	costumes := usecase.CostumeRepository.FindAll(ctx, tx, searchRequest)
+
+	// an unpaged request walks every page with the same keyset query, so the whole catalog comes back as it did before
+	// paging existed
+	if searchRequest.Unpaged {
+		page := costumes
+		costumes = []costume.CostumeResponse{}
+		for len(page) > searchRequest.Limit {
+			page = page[:searchRequest.Limit]
+			costumes = append(costumes, page...)
+			last := page[len(page)-1]
+			searchRequest.After_value = &last.Sort_value
+			searchRequest.After_id = last.Id
+			page = usecase.CostumeRepository.FindAll(ctx, tx, searchRequest)
+		}
+		costumes = append(costumes, page...)
+	}
+
+	listResponse := costume.CostumeListResponse{}
+	if !searchRequest.Unpaged && len(costumes) > searchRequest.Limit {
+		costumes = costumes[:searchRequest.Limit]
+		last := costumes[len(costumes)-1]
+		nextCursor := helper.EncodeCursor(helper.Cursor{Sort: searchRequest.Sort, Value: last.Sort_value, Id: last.Id})
+		listResponse.Next_cursor = &nextCursor
+	}
+
+	usecase.completeCostumeList(ctx, tx, costumes, searchRequest.Buyer_id)
+
+	listResponse.Costumes = costumes
+
+	return listResponse, nil
 }
 
-func (usecase *CostumeUsecase) FindAll(ctx context.Context) ([]costume.CostumeResponse, error) {
+// FindSimilar recommends listings like the given one. SIMILAR_EXCLUDE_UNAVAILABLE and SIMILAR_EXCLUDE_SAME_SELLER both
+// default to true.
+func (usecase *CostumeUsecase) FindSimilar(ctx context.Context, similarRequest costume.CostumeSimilarRequest) ([]costume.CostumeResponse, error) {
+	if similarRequest.Limit == 0 {
+		similarRequest.Limit = similarLimit
+	}
+
+	err := usecase.Validate.Struct(similarRequest)
+	if err != nil {
+		respErr := errors.New("limit must be between 1 and 20")
+		usecase.Log.Warn().Err(respErr).Msg(err.Error())
+		return nil, respErr
+	}
+
 	tx, err := usecase.DB.Begin()
 	if err != nil {
 		respErr := errors.New("failed to start transaction")
@@ -128,28 +437,76 @@ func (usecase *CostumeUsecase) FindAll(ctx context.Context) ([]costume.CostumeRe
 
 	defer helper.CommitOrRollback(tx)
 
-	costume, err := usecase.CostumeRepository.FindAll(ctx, tx)
+	costumeResult, err := usecase.CostumeRepository.FindById(ctx, tx, similarRequest.Costume_id)
 	if err != nil {
 		usecase.Log.Warn().Msg(err.Error())
-		return costume, err
+		return nil, err
 	}
 
-	imageEnv := usecase.Config.String("IMAGE_ENV")
+	if costumeResult.Status == "draft" || costumeResult.Status == "archived" {
+		respErr := errors.New("costume not found")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return nil, respErr
+	}
 
-	for i := range costume {
-		userResult, err := usecase.UserRepository.FindByUUID(ctx, tx, costume[i].User_id)
-		if err != nil {
-			usecase.Log.Warn().Msg(err.Error())
-			return costume, err
-		}
-		costume[i].Username = userResult.Name
-		if costume[i].Picture != nil {
-			value := imageEnv + *costume[i].Picture
-			costume[i].Picture = &value
+	attributeKeys := []string{}
+	for _, key := range strings.Split(usecase.configString("SIMILAR_ATTRIBUTE_KEYS", defaultSimilarAttributeKeys), ",") {
+		if strings.TrimSpace(key) != "" {
+			attributeKeys = append(attributeKeys, strings.TrimSpace(key))
 		}
 	}
 
-	return costume, nil
+	costumes := usecase.CostumeRepository.FindSimilar(ctx, tx, costumeResult.Id, attributeKeys, usecase.configBool("SIMILAR_EXCLUDE_UNAVAILABLE", true), usecase.configBool("SIMILAR_EXCLUDE_SAME_SELLER", true), similarRequest.Limit)
+	usecase.completeCostumeList(ctx, tx, costumes, similarRequest.Buyer_id)
+
+	return costumes, nil
+}
+
+func (usecase *CostumeUsecase) configString(key string, fallback string) string {
+	if !usecase.Config.Exists(key) {
+		return fallback
+	}
+	return usecase.Config.String(key)
+}
+
+func (usecase *CostumeUsecase) configBool(key string, fallback bool) bool {
+	if !usecase.Config.Exists(key) {
+		return fallback
+	}
+	return usecase.Config.Bool(key)
+}
+
+// completeCostumeList fills a page of listings in place with media urls, attributes, variants, measurements and,
+// when the buyer saved their measurements, fit flags.
+func (usecase *CostumeUsecase) completeCostumeList(ctx context.Context, tx *sql.Tx, costumes []costume.CostumeResponse, buyerId string) {
+	costumeIds := make([]int, len(costumes))
+	for i := range costumes {
+		costumeIds[i] = costumes[i].Id
+	}
+	imagesByCostume := usecase.CostumeImageRepository.FindByCostumeIds(ctx, tx, costumeIds)
+	attributesByCostume := usecase.CostumeAttributeRepository.FindByCostumeIds(ctx, tx, costumeIds)
+	variantsByCostume := usecase.VariantRepository.FindByCostumeIds(ctx, tx, costumeIds)
+	measurementsByCostume := usecase.MeasurementRepository.FindByCostumeIds(ctx, tx, costumeIds)
+	buyerMeasurement, hasBuyerMeasurement := findBuyerMeasurement(ctx, tx, usecase.UserMeasurementRepository, buyerId)
+
+	for i := range costumes {
+		if costumes[i].Picture != nil {
+			costumes[i].Picture_variants = helper.ImageVariantURLs(usecase.Storage, costumes[i].Picture)
+			value := usecase.Storage.URL(*costumes[i].Picture)
+			costumes[i].Picture = &value
+		}
+		costumes[i].Images = costumeImageURLs(usecase.Storage, imagesByCostume[costumes[i].Id])
+		costumes[i].Attributes = costumeAttributes(attributesByCostume[costumes[i].Id])
+		costumes[i].Variants = costumeVariants(variantsByCostume[costumes[i].Id], costumes[i].Price)
+
+		measurement, ok := measurementsByCostume[costumes[i].Id]
+		if ok {
+			costumes[i].Measurements = costumeMeasurementResponse(measurement)
+			if hasBuyerMeasurement {
+				costumes[i].Fit = listFit(measurement, buyerMeasurement)
+			}
+		}
+	}
 }
 
 func (usecase *CostumeUsecase) FindSellerCostume(ctx context.Context, userUUID string) ([]costume.SellerCostumeResponse, error) {
@@ -167,19 +524,37 @@ func (usecase *CostumeUsecase) FindSellerCostume(ctx context.Context, userUUID s
 		return costume, err
 	}
 
-	imageEnv := usecase.Config.String("IMAGE_ENV")
+	costumeIds := make([]int, len(costume))
+	for i := range costume {
+		costumeIds[i] = costume[i].Id
+	}
+	imagesByCostume := usecase.CostumeImageRepository.FindByCostumeIds(ctx, tx, costumeIds)
+	attributesByCostume := usecase.CostumeAttributeRepository.FindByCostumeIds(ctx, tx, costumeIds)
+	variantsByCostume := usecase.VariantRepository.FindByCostumeIds(ctx, tx, costumeIds)
+	measurementsByCostume := usecase.MeasurementRepository.FindByCostumeIds(ctx, tx, costumeIds)
 
 	for i := range costume {
 		if costume[i].Picture != nil {
-			value := imageEnv + *costume[i].Picture
+			costume[i].Picture_variants = helper.ImageVariantURLs(usecase.Storage, costume[i].Picture)
+			value := usecase.Storage.URL(*costume[i].Picture)
 			costume[i].Picture = &value
 		}
+		costume[i].Images = costumeImageURLs(usecase.Storage, imagesByCostume[costume[i].Id])
+		costume[i].Attributes = costumeAttributes(attributesByCostume[costume[i].Id])
+		costume[i].Variants = costumeVariants(variantsByCostume[costume[i].Id], costume[i].Price)
+
+		measurement, ok := measurementsByCostume[costume[i].Id]
+		if ok {
+			costume[i].Measurements = costumeMeasurementResponse(measurement)
+		}
 	}
 
 	return costume, nil
 }
 
-func (usecase *CostumeUsecase) FindById(ctx context.Context, id int) (costume.CostumeResponse, error) {
+// FindById returns a listing for buyers and records the view, viewerUUID is empty for anonymous requests and visitorId
+// then tells repeated views from the same client apart.
+func (usecase *CostumeUsecase) FindById(ctx context.Context, id int, quoteRequest costume.CostumeQuoteRequest, viewerUUID string, visitorId string) (costume.CostumeResponse, error) {
 	tx, err := usecase.DB.Begin()
 	if err != nil {
 		respErr := errors.New("failed to start transaction")
@@ -195,6 +570,29 @@ func (usecase *CostumeUsecase) FindById(ctx context.Context, id int) (costume.Co
 		return costume, err
 	}
 
+	// drafts are not public yet and archived listings are gone for buyers, paused ones stay visible
+	if costume.Status == "draft" || costume.Status == "archived" {
+		respErr := errors.New("costume not found")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return costume, respErr
+	}
+
+	// sellers checking their own listing are not buyer interest
+	if viewerUUID != costume.User_id {
+		now := time.Now()
+		view := domain.CostumeEvent{
+			Costume_id: costume.Id,
+			Type:       "view",
+			Created_at: &now,
+		}
+		if viewerUUID != "" {
+			view.User_id = &viewerUUID
+		} else {
+			view.Visitor_id = &visitorId
+		}
+		usecase.EventRepository.CreateView(ctx, tx, view, now.Add(-viewInterval))
+	}
+
 	categoryName, err := usecase.CategoryRepository.FindCategoryNameById(ctx, tx, costume.Kategori_id)
 	if err != nil {
 		usecase.Log.Warn().Msg(err.Error())
@@ -209,18 +607,43 @@ func (usecase *CostumeUsecase) FindById(ctx context.Context, id int) (costume.Co
 
 	costume.Username = user.Name
 
-	imageEnv := usecase.Config.String("IMAGE_ENV")
+	seller, err := usecase.UserRepository.FindVacationById(ctx, tx, costume.User_id)
+	if err == nil {
+		costume.Seller_vacation = sellerVacationResponse(seller)
+	}
 
 	if user.Profile_picture != nil {
-		value := imageEnv + *user.Profile_picture
+		value := usecase.Storage.URL(*user.Profile_picture)
 		costume.Profile_picture = &value
 	}
 
 	if costume.Picture != nil {
-		value := imageEnv + *costume.Picture
+		costume.Picture_variants = helper.ImageVariantURLs(usecase.Storage, costume.Picture)
+		value := usecase.Storage.URL(*costume.Picture)
 		costume.Picture = &value
 	}
 
+	costume.Images = costumeImageURLs(usecase.Storage, usecase.CostumeImageRepository.FindByCostumeIds(ctx, tx, []int{costume.Id})[costume.Id])
+	costume.Attributes = costumeAttributes(usecase.CostumeAttributeRepository.FindByCostumeIds(ctx, tx, []int{costume.Id})[costume.Id])
+	costume.Variants = costumeVariants(usecase.VariantRepository.FindByCostumeIds(ctx, tx, []int{costume.Id})[costume.Id], costume.Price)
+
+	measurement, ok := usecase.MeasurementRepository.FindByCostumeIds(ctx, tx, []int{costume.Id})[costume.Id]
+	if ok {
+		costume.Measurements = costumeMeasurementResponse(measurement)
+	}
+
+	pricing := findCostumePricing(ctx, tx, usecase.PricingRepository, costume.Id)
+	costume.Pricing = costumePricingResponse(pricing, costume.Price)
+
+	if quoteRequest.Start_date != "" || quoteRequest.End_date != "" {
+		quote, err := quoteCostume(costume, pricing, quoteRequest)
+		if err != nil {
+			usecase.Log.Warn().Msg(err.Error())
+			return costume, err
+		}
+		costume.Price_quote = &quote
+	}
+
 	costume.Kategori = categoryName
 
 	return costume, nil
@@ -235,8 +658,11 @@ func (usecase *CostumeUsecase) FindSellerCostumeByCostumeID(ctx context.Context,
 
 	defer helper.CommitOrRollback(tx)
 
-	costume := costume.CostumeResponse{}
-	costume, err = usecase.CostumeRepository.FindSellerCostumeByCostumeID(ctx, tx, userUUID, costumeID)
+	return usecase.findSellerCostume(ctx, tx, userUUID, costumeID)
+}
+
+func (usecase *CostumeUsecase) findSellerCostume(ctx context.Context, tx *sql.Tx, userUUID string, costumeID int) (costume.CostumeResponse, error) {
+	costume, err := usecase.CostumeRepository.FindSellerCostumeByCostumeID(ctx, tx, userUUID, costumeID)
 	if err != nil {
 		usecase.Log.Warn().Msg(err.Error())
 		return costume, err
@@ -250,18 +676,29 @@ func (usecase *CostumeUsecase) FindSellerCostumeByCostumeID(ctx context.Context,
 
 	costume.Username = userResult.Name
 
-	imageEnv := usecase.Config.String("IMAGE_ENV")
-
 	if userResult.Profile_picture != nil {
-		value := imageEnv + *userResult.Profile_picture
+		value := usecase.Storage.URL(*userResult.Profile_picture)
 		costume.Profile_picture = &value
 	}
 
 	if costume.Picture != nil {
-		value := imageEnv + *costume.Picture
+		costume.Picture_variants = helper.ImageVariantURLs(usecase.Storage, costume.Picture)
+		value := usecase.Storage.URL(*costume.Picture)
 		costume.Picture = &value
 	}
 
+	costume.Images = costumeImageURLs(usecase.Storage, usecase.CostumeImageRepository.FindByCostumeIds(ctx, tx, []int{costume.Id})[costume.Id])
+	costume.Attributes = costumeAttributes(usecase.CostumeAttributeRepository.FindByCostumeIds(ctx, tx, []int{costume.Id})[costume.Id])
+	costume.Variants = costumeVariants(usecase.VariantRepository.FindByCostumeIds(ctx, tx, []int{costume.Id})[costume.Id], costume.Price)
+
+	measurement, ok := usecase.MeasurementRepository.FindByCostumeIds(ctx, tx, []int{costume.Id})[costume.Id]
+	if ok {
+		costume.Measurements = costumeMeasurementResponse(measurement)
+	}
+
+	costume.Pricing = costumePricingResponse(findCostumePricing(ctx, tx, usecase.PricingRepository, costume.Id), costume.Price)
+	costume.Availability = usecase.findAvailabilitySettings(ctx, tx, costume.Id)
+
 	return costume, nil
 }
 
@@ -280,21 +717,1225 @@ func (usecase *CostumeUsecase) Delete(ctx context.Context, id int, uuid string)
 		return err
 	}
 
-	costumepicture, err := usecase.CostumeRepository.FindPictureById(ctx, tx, id)
+	// images are kept as well, past orders and reviews still show the costume
+	now := time.Now()
+
+	usecase.CostumeRepository.Archive(ctx, tx, id, &now)
+
+	return nil
+}
+
+func (usecase *CostumeUsecase) UpdateStatus(ctx context.Context, uuid string, statusRequest costume.CostumeStatusRequest) (costume.CostumeResponse, error) {
+	costumeResponse, emails, err := usecase.updateStatusRow(ctx, uuid, statusRequest)
+
+	// the emails only exist once the publish was written, and it commits even when reading the listing back fails
+	usecase.NotificationUsecase.SendEmails(emails)
+
+	if err != nil {
+		return costume.CostumeResponse{}, err
+	}
+
+	return costumeResponse, nil
+}
+
+// updateStatusRow moves the listing to the requested status and returns it with the follower emails a first publish
+// produced, they are left for the caller to send after the commit.
+func (usecase *CostumeUsecase) updateStatusRow(ctx context.Context, uuid string, statusRequest costume.CostumeStatusRequest) (costume.CostumeResponse, []*gomail.Message, error) {
+	err := usecase.Validate.Struct(statusRequest)
+	if err != nil {
+		respErr := errors.New("status must be draft, published, paused or archived")
+		usecase.Log.Warn().Err(respErr).Msg(err.Error())
+		return costume.CostumeResponse{}, nil, respErr
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	err = usecase.CostumeRepository.CheckCostume(ctx, tx, uuid, statusRequest.Id)
 	if err != nil {
 		usecase.Log.Warn().Msg(err.Error())
-		return err
+		return costume.CostumeResponse{}, nil, err
+	}
+
+	current, err := usecase.CostumeRepository.FindById(ctx, tx, statusRequest.Id)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return costume.CostumeResponse{}, nil, err
+	}
+
+	if current.Status != statusRequest.Status && !slices.Contains(costumeStatusTransitions[current.Status], statusRequest.Status) {
+		respErr := fmt.Errorf("a %s costume cannot be moved to %s", current.Status, statusRequest.Status)
+		usecase.Log.Warn().Msg(respErr.Error())
+		return costume.CostumeResponse{}, nil, respErr
+	}
+
+	now := time.Now()
+
+	if statusRequest.Status == "archived" {
+		usecase.CostumeRepository.Archive(ctx, tx, statusRequest.Id, &now)
+	} else {
+		usecase.CostumeRepository.UpdateStatus(ctx, tx, statusRequest.Id, statusRequest.Status, &now)
+	}
+
+	var emails []*gomail.Message
+
+	// drafts can only be published once, so this is the listing's first appearance in the catalog
+	if current.Status == "draft" && statusRequest.Status == "published" {
+		sellerName, err := usecase.UserRepository.FindNameById(ctx, tx, uuid)
+		if err != nil {
+			respErr := errors.New("failed to find seller")
+			usecase.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		emails = usecase.announceNewListing(ctx, tx, uuid, sellerName, statusRequest.Id, current.Name, true, &now)
 	}
 
-	usecase.CostumeRepository.Delete(ctx, tx, id, uuid)
+	costumeResponse, err := usecase.findSellerCostume(ctx, tx, uuid, statusRequest.Id)
+	if err != nil {
+		return costume.CostumeResponse{}, emails, err
+	}
 
-	finalCostumePicturePath := ".." + *costumepicture
+	return costumeResponse, emails, nil
+}
 
-	err = os.Remove(finalCostumePicturePath)
+func (usecase *CostumeUsecase) AddImages(ctx context.Context, uuid string, costumeId int, paths []string) ([]costume.CostumeImageResponse, error) {
+	tx, err := usecase.DB.Begin()
 	if err != nil {
-		respErr := errors.New("failed to remove costume")
+		respErr := errors.New("failed to start transaction")
 		usecase.Log.Panic().Err(err).Msg(respErr.Error())
 	}
 
-	return nil
+	defer helper.CommitOrRollback(tx)
+
+	err = usecase.CostumeRepository.CheckCostume(ctx, tx, uuid, costumeId)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return nil, err
+	}
+
+	images := usecase.CostumeImageRepository.FindByCostumeId(ctx, tx, costumeId)
+	if len(images)+len(paths) > maxCostumeImages {
+		respErr := fmt.Errorf("a costume can have at most %d images", maxCostumeImages)
+		usecase.Log.Warn().Msg(respErr.Error())
+		return nil, respErr
+	}
+
+	nextPosition := 0
+	if len(images) > 0 {
+		nextPosition = images[len(images)-1].Position + 1
+	}
+
+	now := time.Now()
+
+	for i, path := range paths {
+		usecase.CostumeImageRepository.Create(ctx, tx, domain.CostumeImage{
+			Costume_id: costumeId,
+			Path:       path,
+			Position:   nextPosition + i,
+			Created_at: &now,
+		})
+	}
+
+	return usecase.findCostumeImages(ctx, tx, costumeId), nil
+}
+
+func (usecase *CostumeUsecase) ReorderImages(ctx context.Context, uuid string, costumeId int, orderRequest costume.CostumeImageOrderRequest) ([]costume.CostumeImageResponse, error) {
+	err := usecase.Validate.Struct(orderRequest)
+	if err != nil {
+		respErr := errors.New("invalid request body")
+		usecase.Log.Warn().Err(respErr).Msg(err.Error())
+		return nil, respErr
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	err = usecase.CostumeRepository.CheckCostume(ctx, tx, uuid, costumeId)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return nil, err
+	}
+
+	images := usecase.CostumeImageRepository.FindByCostumeId(ctx, tx, costumeId)
+
+	remaining := map[int]bool{}
+	for _, image := range images {
+		remaining[image.Id] = true
+	}
+	for _, imageId := range orderRequest.Image_ids {
+		if !remaining[imageId] {
+			respErr := errors.New("image_ids must list every image of the costume exactly once")
+			usecase.Log.Warn().Msg(respErr.Error())
+			return nil, respErr
+		}
+		delete(remaining, imageId)
+	}
+	if len(remaining) != 0 {
+		respErr := errors.New("image_ids must list every image of the costume exactly once")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return nil, respErr
+	}
+
+	for position, imageId := range orderRequest.Image_ids {
+		usecase.CostumeImageRepository.UpdatePosition(ctx, tx, domain.CostumeImage{
+			Id:         imageId,
+			Costume_id: costumeId,
+			Position:   position,
+		})
+	}
+
+	return usecase.findCostumeImages(ctx, tx, costumeId), nil
+}
+
+func (usecase *CostumeUsecase) SetCoverImage(ctx context.Context, uuid string, costumeId int, imageId int) ([]costume.CostumeImageResponse, error) {
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	err = usecase.CostumeRepository.CheckCostume(ctx, tx, uuid, costumeId)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return nil, err
+	}
+
+	_, err = findCostumeImage(usecase.CostumeImageRepository.FindByCostumeId(ctx, tx, costumeId), imageId)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return nil, err
+	}
+
+	usecase.CostumeImageRepository.SetCover(ctx, tx, costumeId, imageId)
+
+	return usecase.findCostumeImages(ctx, tx, costumeId), nil
+}
+
+func (usecase *CostumeUsecase) DeleteImage(ctx context.Context, uuid string, costumeId int, imageId int) ([]costume.CostumeImageResponse, error) {
+	images, removedPath, err := usecase.deleteImageRow(ctx, uuid, costumeId, imageId)
+	if err != nil {
+		return nil, err
+	}
+
+	// the row is gone once deleteImageRow has committed, a file that fails to go is only left unreferenced
+	err = helper.RemoveImage(ctx, usecase.Storage, removedPath)
+	if err != nil {
+		respErr := errors.New("failed to remove costume image")
+		usecase.Log.Warn().Err(respErr).Msg(err.Error())
+	}
+
+	return images, nil
+}
+
+// deleteImageRow removes the image from the costume and returns the remaining images with the removed image's path,
+// the files are left for the caller to delete after the commit.
+func (usecase *CostumeUsecase) deleteImageRow(ctx context.Context, uuid string, costumeId int, imageId int) ([]costume.CostumeImageResponse, string, error) {
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	err = usecase.CostumeRepository.CheckCostume(ctx, tx, uuid, costumeId)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return nil, "", err
+	}
+
+	images := usecase.CostumeImageRepository.FindByCostumeId(ctx, tx, costumeId)
+
+	image, err := findCostumeImage(images, imageId)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return nil, "", err
+	}
+
+	if len(images) == 1 {
+		respErr := errors.New("a costume must keep at least one image")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return nil, "", respErr
+	}
+
+	usecase.CostumeImageRepository.Delete(ctx, tx, image)
+
+	// the first remaining image by position takes over as cover
+	if image.Is_cover {
+		for _, remaining := range images {
+			if remaining.Id != image.Id {
+				usecase.CostumeImageRepository.SetCover(ctx, tx, costumeId, remaining.Id)
+				break
+			}
+		}
+	}
+
+	return usecase.findCostumeImages(ctx, tx, costumeId), image.Path, nil
+}
+
+func (usecase *CostumeUsecase) UpdateMeasurements(ctx context.Context, uuid string, measurementRequest costume.CostumeMeasurementRequest) (costume.CostumeMeasurementResponse, error) {
+	err := usecase.Validate.Struct(measurementRequest)
+	if err != nil {
+		respErr := errors.New("invalid request body")
+		usecase.Log.Warn().Err(respErr).Msg(err.Error())
+		return costume.CostumeMeasurementResponse{}, respErr
+	}
+
+	if measurementRequest.Height_min != nil && measurementRequest.Height_max != nil && *measurementRequest.Height_min > *measurementRequest.Height_max {
+		respErr := errors.New("height_min must not be greater than height_max")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return costume.CostumeMeasurementResponse{}, respErr
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	err = usecase.CostumeRepository.CheckCostume(ctx, tx, uuid, measurementRequest.Costume_id)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return costume.CostumeMeasurementResponse{}, err
+	}
+
+	now := time.Now()
+
+	measurement := domain.CostumeMeasurement{
+		Costume_id: measurementRequest.Costume_id,
+		Chest:      measurementRequest.Chest,
+		Waist:      measurementRequest.Waist,
+		Hip:        measurementRequest.Hip,
+		Height_min: measurementRequest.Height_min,
+		Height_max: measurementRequest.Height_max,
+		Shoe_size:  measurementRequest.Shoe_size,
+		Updated_at: &now,
+	}
+
+	usecase.MeasurementRepository.Save(ctx, tx, measurement)
+
+	return *costumeMeasurementResponse(measurement), nil
+}
+
+func (usecase *CostumeUsecase) FindFit(ctx context.Context, uuid string, costumeId int) (costume.CostumeFitResponse, error) {
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	err = usecase.CostumeRepository.CheckCostumeId(ctx, tx, costumeId)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return costume.CostumeFitResponse{}, err
+	}
+
+	buyerMeasurement, err := usecase.UserMeasurementRepository.FindByUserId(ctx, tx, uuid)
+	if err != nil {
+		respErr := errors.New("save your measurements first")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return costume.CostumeFitResponse{}, respErr
+	}
+
+	measurement, ok := usecase.MeasurementRepository.FindByCostumeIds(ctx, tx, []int{costumeId})[costumeId]
+	if !ok {
+		return costume.CostumeFitResponse{Verdict: "unknown", Dimensions: []costume.CostumeFitDimensionResponse{}}, nil
+	}
+
+	return helper.ScoreFit(measurement, buyerMeasurement), nil
+}
+
+func (usecase *CostumeUsecase) UpdatePricing(ctx context.Context, uuid string, pricingRequest costume.CostumePricingRequest) (costume.CostumePricingResponse, error) {
+	err := usecase.Validate.Struct(pricingRequest)
+	if err != nil {
+		respErr := errors.New("invalid request body")
+		usecase.Log.Warn().Err(respErr).Msg(err.Error())
+		return costume.CostumePricingResponse{}, respErr
+	}
+
+	pricing, err := pricingFromRequest(pricingRequest)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return costume.CostumePricingResponse{}, err
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	err = usecase.CostumeRepository.CheckCostume(ctx, tx, uuid, pricingRequest.Costume_id)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return costume.CostumePricingResponse{}, err
+	}
+
+	costumeResult, err := usecase.CostumeRepository.FindById(ctx, tx, pricingRequest.Costume_id)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return costume.CostumePricingResponse{}, err
+	}
+
+	now := time.Now()
+	pricing.Updated_at = &now
+
+	usecase.PricingRepository.Save(ctx, tx, pricing)
+
+	return *costumePricingResponse(pricing, costumeResult.Price), nil
+}
+
+func (usecase *CostumeUsecase) FindAvailability(ctx context.Context, costumeId int, availabilityRequest costume.CostumeAvailabilityRequest) (costume.CostumeAvailabilityResponse, error) {
+	today := helper.RentalDay(time.Now())
+	if availabilityRequest.Start_date == "" {
+		availabilityRequest.Start_date = today.Format(helper.RentalDateLayout)
+	}
+	if availabilityRequest.End_date == "" {
+		start, err := time.Parse(helper.RentalDateLayout, availabilityRequest.Start_date)
+		if err != nil {
+			start = today
+		}
+		availabilityRequest.End_date = start.AddDate(0, 0, availabilityCalendarDays-1).Format(helper.RentalDateLayout)
+	}
+
+	start, end, err := helper.ParseRentalDates(availabilityRequest.Start_date, availabilityRequest.End_date)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return costume.CostumeAvailabilityResponse{}, err
+	}
+
+	if start.Before(today) {
+		respErr := errors.New("start_date must not be in the past")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return costume.CostumeAvailabilityResponse{}, respErr
+	}
+
+	if end.After(start.AddDate(0, 0, maxAvailabilityCalendarDays-1)) {
+		respErr := fmt.Errorf("the calendar covers at most %d days", maxAvailabilityCalendarDays)
+		usecase.Log.Warn().Msg(respErr.Error())
+		return costume.CostumeAvailabilityResponse{}, respErr
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	costumeResult, err := usecase.CostumeRepository.FindById(ctx, tx, costumeId)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return costume.CostumeAvailabilityResponse{}, err
+	}
+
+	if costumeResult.Status == "draft" || costumeResult.Status == "archived" {
+		respErr := errors.New("costume not found")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return costume.CostumeAvailabilityResponse{}, respErr
+	}
+
+	variants := usecase.VariantRepository.FindByCostumeIds(ctx, tx, []int{costumeId})[costumeId]
+	if availabilityRequest.Variant_id != 0 {
+		index := slices.IndexFunc(variants, func(v domain.CostumeVariant) bool { return v.Id == availabilityRequest.Variant_id })
+		if index < 0 {
+			respErr := errors.New("variant not found")
+			usecase.Log.Warn().Msg(respErr.Error())
+			return costume.CostumeAvailabilityResponse{}, respErr
+		}
+		variants = variants[index : index+1]
+	}
+
+	seller, err := usecase.UserRepository.FindVacationById(ctx, tx, costumeResult.User_id)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return costume.CostumeAvailabilityResponse{}, err
+	}
+	vacationStart, vacationEnd, onVacation := helper.VacationWindow(seller, today)
+
+	blackouts := usecase.BlackoutRepository.FindByCostumeId(ctx, tx, costumeId, start)
+	booked := usecase.VariantRepository.BookedUnits(ctx, tx, costumeId, start, end)
+
+	availabilityResponse := costume.CostumeAvailabilityResponse{
+		Costume_id:      costumeId,
+		Variant_id:      availabilityRequest.Variant_id,
+		Turnaround_days: usecase.CostumeRepository.FindTurnaroundDays(ctx, tx, costumeId),
+		Days:            []costume.CostumeAvailabilityDayResponse{},
+	}
+
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format(helper.RentalDateLayout)
+
+		if onVacation && !day.Before(vacationStart) && !day.After(vacationEnd) {
+			availabilityResponse.Days = append(availabilityResponse.Days, costume.CostumeAvailabilityDayResponse{Date: date, Status: "vacation"})
+			continue
+		}
+
+		blackedOut := slices.ContainsFunc(blackouts, func(b domain.CostumeBlackout) bool {
+			return !day.Before(helper.RentalDay(b.Start_date)) && !day.After(helper.RentalDay(b.End_date))
+		})
+		if blackedOut {
+			availabilityResponse.Days = append(availabilityResponse.Days, costume.CostumeAvailabilityDayResponse{Date: date, Status: "blackout"})
+			continue
+		}
+
+		available := 0
+		for _, variant := range variants {
+			available += max(variant.Quantity-booked[variant.Id][date], 0)
+		}
+
+		status := "available"
+		if available == 0 {
+			status = "booked"
+		}
+		availabilityResponse.Days = append(availabilityResponse.Days, costume.CostumeAvailabilityDayResponse{Date: date, Status: status, Available: available})
+	}
+
+	return availabilityResponse, nil
+}
+
+func (usecase *CostumeUsecase) UpdateTurnaround(ctx context.Context, uuid string, turnaroundRequest costume.CostumeTurnaroundRequest) (costume.CostumeAvailabilitySettingsResponse, error) {
+	err := usecase.Validate.Struct(turnaroundRequest)
+	if err != nil {
+		respErr := errors.New("invalid request body")
+		usecase.Log.Warn().Err(respErr).Msg(err.Error())
+		return costume.CostumeAvailabilitySettingsResponse{}, respErr
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	err = usecase.CostumeRepository.CheckCostume(ctx, tx, uuid, turnaroundRequest.Costume_id)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return costume.CostumeAvailabilitySettingsResponse{}, err
+	}
+
+	now := time.Now()
+
+	usecase.CostumeRepository.UpdateTurnaround(ctx, tx, turnaroundRequest.Costume_id, turnaroundRequest.Turnaround_days, &now)
+
+	return *usecase.findAvailabilitySettings(ctx, tx, turnaroundRequest.Costume_id), nil
+}
+
+func (usecase *CostumeUsecase) CreateBlackout(ctx context.Context, uuid string, blackoutRequest costume.CostumeBlackoutRequest) (costume.CostumeAvailabilitySettingsResponse, error) {
+	err := usecase.Validate.Struct(blackoutRequest)
+	if err != nil {
+		respErr := errors.New("invalid request body")
+		usecase.Log.Warn().Err(respErr).Msg(err.Error())
+		return costume.CostumeAvailabilitySettingsResponse{}, respErr
+	}
+
+	start, end, err := helper.ParseRentalDates(blackoutRequest.Start_date, blackoutRequest.End_date)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return costume.CostumeAvailabilitySettingsResponse{}, err
+	}
+
+	if end.Before(helper.RentalDay(time.Now())) {
+		respErr := errors.New("end_date must not be in the past")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return costume.CostumeAvailabilitySettingsResponse{}, respErr
+	}
+
+	if end.After(start.AddDate(0, 0, maxBlackoutDays-1)) {
+		respErr := fmt.Errorf("a blackout can last at most %d days", maxBlackoutDays)
+		usecase.Log.Warn().Msg(respErr.Error())
+		return costume.CostumeAvailabilitySettingsResponse{}, respErr
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	err = usecase.CostumeRepository.CheckCostume(ctx, tx, uuid, blackoutRequest.Costume_id)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return costume.CostumeAvailabilitySettingsResponse{}, err
+	}
+
+	if usecase.BlackoutRepository.Overlaps(ctx, tx, blackoutRequest.Costume_id, start, end) {
+		respErr := errors.New("blackout overlaps an existing one")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return costume.CostumeAvailabilitySettingsResponse{}, respErr
+	}
+
+	// orders already placed keep their dates, the seller has to cancel them first
+	for _, days := range usecase.VariantRepository.BookedUnits(ctx, tx, blackoutRequest.Costume_id, start, end) {
+		for day, booked := range days {
+			if booked > 0 {
+				respErr := fmt.Errorf("costume is booked on %s", day)
+				usecase.Log.Warn().Msg(respErr.Error())
+				return costume.CostumeAvailabilitySettingsResponse{}, respErr
+			}
+		}
+	}
+
+	if blackoutRequest.Reason != nil {
+		reason := strings.TrimSpace(*blackoutRequest.Reason)
+		blackoutRequest.Reason = &reason
+	}
+
+	now := time.Now()
+
+	usecase.BlackoutRepository.Create(ctx, tx, domain.CostumeBlackout{
+		Costume_id: blackoutRequest.Costume_id,
+		Start_date: start,
+		End_date:   end,
+		Reason:     blackoutRequest.Reason,
+		Created_at: &now,
+	})
+
+	return *usecase.findAvailabilitySettings(ctx, tx, blackoutRequest.Costume_id), nil
+}
+
+func (usecase *CostumeUsecase) DeleteBlackout(ctx context.Context, uuid string, costumeId int, blackoutId int) (costume.CostumeAvailabilitySettingsResponse, error) {
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	err = usecase.CostumeRepository.CheckCostume(ctx, tx, uuid, costumeId)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return costume.CostumeAvailabilitySettingsResponse{}, err
+	}
+
+	err = usecase.BlackoutRepository.Delete(ctx, tx, costumeId, blackoutId)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return costume.CostumeAvailabilitySettingsResponse{}, err
+	}
+
+	return *usecase.findAvailabilitySettings(ctx, tx, costumeId), nil
+}
+
+// findAvailabilitySettings lists the blackouts that have not ended yet.
+func (usecase *CostumeUsecase) findAvailabilitySettings(ctx context.Context, tx *sql.Tx, costumeId int) *costume.CostumeAvailabilitySettingsResponse {
+	settings := &costume.CostumeAvailabilitySettingsResponse{
+		Turnaround_days: usecase.CostumeRepository.FindTurnaroundDays(ctx, tx, costumeId),
+		Blackouts:       []costume.CostumeBlackoutResponse{},
+	}
+
+	for _, blackout := range usecase.BlackoutRepository.FindByCostumeId(ctx, tx, costumeId, helper.RentalDay(time.Now())) {
+		settings.Blackouts = append(settings.Blackouts, costume.CostumeBlackoutResponse{
+			Id:         blackout.Id,
+			Start_date: blackout.Start_date.Format(helper.RentalDateLayout),
+			End_date:   blackout.End_date.Format(helper.RentalDateLayout),
+			Reason:     blackout.Reason,
+		})
+	}
+
+	return settings
+}
+
+func (usecase *CostumeUsecase) FindVariants(ctx context.Context, costumeId int) ([]costume.CostumeVariantResponse, error) {
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	costumeResult, err := usecase.CostumeRepository.FindById(ctx, tx, costumeId)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return nil, err
+	}
+
+	return costumeVariants(usecase.VariantRepository.FindByCostumeIds(ctx, tx, []int{costumeId})[costumeId], costumeResult.Price), nil
+}
+
+func (usecase *CostumeUsecase) CreateVariant(ctx context.Context, uuid string, variantRequest costume.CostumeVariantRequest) ([]costume.CostumeVariantResponse, error) {
+	err := usecase.Validate.Struct(variantRequest)
+	if err != nil {
+		respErr := errors.New("invalid request body")
+		usecase.Log.Warn().Err(respErr).Msg(err.Error())
+		return nil, respErr
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	err = usecase.CostumeRepository.CheckCostume(ctx, tx, uuid, variantRequest.Costume_id)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return nil, err
+	}
+
+	size := strings.TrimSpace(variantRequest.Size)
+	if usecase.VariantRepository.SizeExists(ctx, tx, variantRequest.Costume_id, size, 0) {
+		respErr := errors.New("variant size already exists")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return nil, respErr
+	}
+
+	now := time.Now()
+
+	usecase.VariantRepository.Create(ctx, tx, domain.CostumeVariant{
+		Costume_id: variantRequest.Costume_id,
+		Size:       size,
+		Quantity:   variantRequest.Quantity,
+		Price:      variantRequest.Price,
+		Created_at: &now,
+		Updated_at: &now,
+	})
+
+	usecase.recordRestock(ctx, tx, uuid, variantRequest.Costume_id, &now)
+
+	return usecase.findCostumeVariants(ctx, tx, variantRequest.Costume_id), nil
+}
+
+func (usecase *CostumeUsecase) UpdateVariant(ctx context.Context, uuid string, variantRequest costume.CostumeVariantUpdateRequest) ([]costume.CostumeVariantResponse, error) {
+	patchErrors := []error{
+		helper.ValidatePatchField(usecase.Validate, "size", variantRequest.Size, false, "min=1,max=30"),
+		helper.ValidatePatchField(usecase.Validate, "quantity", variantRequest.Quantity, false, "min=0,max=1000"),
+		helper.ValidatePatchField(usecase.Validate, "price", variantRequest.Price, true, "gt=0"),
+	}
+	for _, err := range patchErrors {
+		if err != nil {
+			usecase.Log.Warn().Msg(err.Error())
+			return nil, err
+		}
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	err = usecase.CostumeRepository.CheckCostume(ctx, tx, uuid, variantRequest.Costume_id)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return nil, err
+	}
+
+	variant, err := usecase.VariantRepository.FindByIdForUpdate(ctx, tx, variantRequest.Costume_id, variantRequest.Id)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return nil, err
+	}
+
+	if variantRequest.Quantity.Set {
+		today := helper.RentalDay(time.Now())
+		lastDay := helper.LastRentalDay(today).AddDate(0, 0, usecase.CostumeRepository.FindTurnaroundDays(ctx, tx, variantRequest.Costume_id))
+		for day, booked := range usecase.VariantRepository.BookedUnits(ctx, tx, variantRequest.Costume_id, today, lastDay)[variant.Id] {
+			if variantRequest.Quantity.Value < booked {
+				respErr := fmt.Errorf("quantity cannot be lower than the %d units booked on %s", booked, day)
+				usecase.Log.Warn().Msg(respErr.Error())
+				return nil, respErr
+			}
+		}
+	}
+
+	if variantRequest.Size.Set {
+		variantRequest.Size.Value = strings.TrimSpace(variantRequest.Size.Value)
+		if usecase.VariantRepository.SizeExists(ctx, tx, variantRequest.Costume_id, variantRequest.Size.Value, variant.Id) {
+			respErr := errors.New("variant size already exists")
+			usecase.Log.Warn().Msg(respErr.Error())
+			return nil, respErr
+		}
+	}
+
+	now := time.Now()
+
+	usecase.VariantRepository.Update(ctx, tx, variantRequest, &now)
+
+	if variantRequest.Quantity.Set && variantRequest.Quantity.Value > variant.Quantity {
+		usecase.recordRestock(ctx, tx, uuid, variantRequest.Costume_id, &now)
+	}
+
+	return usecase.findCostumeVariants(ctx, tx, variantRequest.Costume_id), nil
+}
+
+func (usecase *CostumeUsecase) DeleteVariant(ctx context.Context, uuid string, costumeId int, variantId int) ([]costume.CostumeVariantResponse, error) {
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	err = usecase.CostumeRepository.CheckCostume(ctx, tx, uuid, costumeId)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return nil, err
+	}
+
+	variant, err := usecase.VariantRepository.FindByIdForUpdate(ctx, tx, costumeId, variantId)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return nil, err
+	}
+
+	if variant.Reserved > 0 {
+		respErr := errors.New("variant has units reserved by running orders")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return nil, respErr
+	}
+
+	if len(usecase.VariantRepository.FindByCostumeIds(ctx, tx, []int{costumeId})[costumeId]) == 1 {
+		respErr := errors.New("a costume must keep at least one variant")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return nil, respErr
+	}
+
+	usecase.VariantRepository.Delete(ctx, tx, variant.Id)
+
+	return usecase.findCostumeVariants(ctx, tx, costumeId), nil
+}
+
+// announceNewListing puts a published listing into the followers' feed and, when notifyFollowers is set, into their
+// inbox. The returned emails are sent by the caller after the commit.
+func (usecase *CostumeUsecase) announceNewListing(ctx context.Context, tx *sql.Tx, sellerId string, sellerName string, costumeId int, costumeName string, notifyFollowers bool, now *time.Time) []*gomail.Message {
+	usecase.ActivityRepository.Create(ctx, tx, domain.CostumeActivity{
+		Costume_id: costumeId,
+		Seller_id:  sellerId,
+		Type:       "new_listing",
+		Created_at: now,
+	})
+
+	if !notifyFollowers {
+		return nil
+	}
+
+	followers := usecase.FollowRepository.FindFollowers(ctx, tx, sellerId)
+	return usecase.NotificationUsecase.CreateNewListingNotification(ctx, tx, followers, sellerName, costumeId, costumeName)
+}
+
+// recordRestock puts a published listing that got more units back into the feed, at most once a day per listing.
+func (usecase *CostumeUsecase) recordRestock(ctx context.Context, tx *sql.Tx, sellerId string, costumeId int, now *time.Time) {
+	costumeResult, err := usecase.CostumeRepository.FindById(ctx, tx, costumeId)
+	if err != nil {
+		respErr := errors.New("failed to find costume")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	if costumeResult.Status != "published" || usecase.ActivityRepository.ExistsSince(ctx, tx, costumeId, "restock", now.Add(-restockFeedInterval)) {
+		return
+	}
+
+	usecase.ActivityRepository.Create(ctx, tx, domain.CostumeActivity{
+		Costume_id: costumeId,
+		Seller_id:  sellerId,
+		Type:       "restock",
+		Created_at: now,
+	})
+}
+
+func (usecase *CostumeUsecase) findCostumeVariants(ctx context.Context, tx *sql.Tx, costumeId int) []costume.CostumeVariantResponse {
+	costumeResult, err := usecase.CostumeRepository.FindById(ctx, tx, costumeId)
+	if err != nil {
+		respErr := errors.New("failed to find costume")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return costumeVariants(usecase.VariantRepository.FindByCostumeIds(ctx, tx, []int{costumeId})[costumeId], costumeResult.Price)
+}
+
+func (usecase *CostumeUsecase) findCostumeImages(ctx context.Context, tx *sql.Tx, costumeId int) []costume.CostumeImageResponse {
+	images := usecase.CostumeImageRepository.FindByCostumeIds(ctx, tx, []int{costumeId})
+	return costumeImageURLs(usecase.Storage, images[costumeId])
+}
+
+func findCostumeImage(images []domain.CostumeImage, imageId int) (domain.CostumeImage, error) {
+	for _, image := range images {
+		if image.Id == imageId {
+			return image, nil
+		}
+	}
+	return domain.CostumeImage{}, errors.New("image not found")
+}
+
+func costumeImageURLs(store storage.Storage, images []costume.CostumeImageResponse) []costume.CostumeImageResponse {
+	urls := make([]costume.CostumeImageResponse, len(images))
+	for i, image := range images {
+		image.Variants = helper.ImageVariantURLs(store, &image.Url)
+		image.Url = store.URL(image.Url)
+		urls[i] = image
+	}
+	return urls
+}
+
+func costumeAttributes(attributes []costume.CostumeAttributeResponse) []costume.CostumeAttributeResponse {
+	if attributes == nil {
+		return []costume.CostumeAttributeResponse{}
+	}
+	return attributes
+}
+
+// costumeAttributeChanges is what checkCostumeAttributes accepted, Keep_ids are the attributes left with a value.
+type costumeAttributeChanges struct {
+	Keep_ids   []int
+	Values     map[int]json.RawMessage
+	Attributes []domain.CategoryAttribute
+}
+
+// checkCostumeAttributes checks the submitted values against the attributes of the category in canonical form, without
+// writing anything, so a rejected request leaves the listing untouched. Values of attributes the category no longer
+// has are dropped, a key sent as null removes its value. costumeId is 0 for a listing that does not exist yet.
+func (usecase *CostumeUsecase) checkCostumeAttributes(ctx context.Context, tx *sql.Tx, costumeId int, categoryId int, values map[string]json.RawMessage) (costumeAttributeChanges, error) {
+	definitions := usecase.CategoryAttributeRepository.FindEffective(ctx, tx, categoryId)
+
+	definitionsByKey := map[string]domain.CategoryAttribute{}
+	for _, definition := range definitions {
+		definitionsByKey[definition.Key] = definition
+	}
+
+	stored := map[int]bool{}
+	for _, attribute := range usecase.CostumeAttributeRepository.FindByCostumeIds(ctx, tx, []int{costumeId})[costumeId] {
+		stored[attribute.Attribute_id] = true
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	normalized := map[int]json.RawMessage{}
+	removed := map[int]bool{}
+	for _, key := range keys {
+		definition, ok := definitionsByKey[key]
+		if !ok {
+			return costumeAttributeChanges{}, fmt.Errorf("unknown attribute %s for this category", key)
+		}
+
+		if bytes.Equal(bytes.TrimSpace(values[key]), []byte("null")) {
+			removed[definition.Id] = true
+			continue
+		}
+
+		value, err := normalizeAttributeValue(definition, values[key])
+		if err != nil {
+			return costumeAttributeChanges{}, err
+		}
+		normalized[definition.Id] = value
+	}
+
+	keepIds := []int{}
+	for _, definition := range definitions {
+		hasValue := normalized[definition.Id] != nil || (stored[definition.Id] && !removed[definition.Id])
+		if definition.Required && !hasValue {
+			return costumeAttributeChanges{}, fmt.Errorf("attribute %s is required", definition.Key)
+		}
+		if hasValue {
+			keepIds = append(keepIds, definition.Id)
+		}
+	}
+
+	return costumeAttributeChanges{Keep_ids: keepIds, Values: normalized, Attributes: definitions}, nil
+}
+
+func (usecase *CostumeUsecase) saveCostumeAttributes(ctx context.Context, tx *sql.Tx, costumeId int, changes costumeAttributeChanges) {
+	usecase.CostumeAttributeRepository.DeleteExcept(ctx, tx, costumeId, changes.Keep_ids)
+
+	for _, definition := range changes.Attributes {
+		if value, ok := changes.Values[definition.Id]; ok {
+			usecase.CostumeAttributeRepository.Save(ctx, tx, costumeId, definition.Id, value)
+		}
+	}
+}
+
+// normalizeAttributeValue checks a value against the attribute's type and returns it re-encoded,
+// enum values take the casing of the matching option so filters and responses stay consistent.
+func normalizeAttributeValue(definition domain.CategoryAttribute, value json.RawMessage) (json.RawMessage, error) {
+	invalidErr := fmt.Errorf("invalid value for attribute %s, expected %s", definition.Key, definition.Type)
+
+	var normalized interface{}
+	switch definition.Type {
+	case "text":
+		var text string
+		if json.Unmarshal(value, &text) != nil {
+			return nil, invalidErr
+		}
+		text = strings.TrimSpace(text)
+		if text == "" || utf8.RuneCountInString(text) > 100 {
+			return nil, fmt.Errorf("attribute %s must be between 1 and 100 characters", definition.Key)
+		}
+		normalized = text
+	case "number":
+		var number float64
+		if json.Unmarshal(value, &number) != nil {
+			return nil, invalidErr
+		}
+		normalized = number
+	case "boolean":
+		var boolean bool
+		if json.Unmarshal(value, &boolean) != nil {
+			return nil, invalidErr
+		}
+		normalized = boolean
+	case "enum":
+		var option string
+		if json.Unmarshal(value, &option) != nil {
+			return nil, invalidErr
+		}
+		match, ok := matchAttributeOption(definition.Options, option)
+		if !ok {
+			return nil, fmt.Errorf("attribute %s must be one of %s", definition.Key, strings.Join(definition.Options, ", "))
+		}
+		normalized = match
+	case "multi_enum":
+		var options []string
+		if json.Unmarshal(value, &options) != nil || len(options) == 0 {
+			return nil, fmt.Errorf("attribute %s must be a non-empty list", definition.Key)
+		}
+		selected := []string{}
+		for _, option := range options {
+			match, ok := matchAttributeOption(definition.Options, option)
+			if !ok {
+				return nil, fmt.Errorf("attribute %s only accepts %s", definition.Key, strings.Join(definition.Options, ", "))
+			}
+			if !slices.Contains(selected, match) {
+				selected = append(selected, match)
+			}
+		}
+		normalized = selected
+	default:
+		return nil, invalidErr
+	}
+
+	encoded, err := json.Marshal(normalized)
+	if err != nil {
+		return nil, invalidErr
+	}
+	return encoded, nil
+}
+
+func matchAttributeOption(options []string, value string) (string, bool) {
+	for _, option := range options {
+		if strings.EqualFold(option, strings.TrimSpace(value)) {
+			return option, true
+		}
+	}
+	return "", false
+}
+
+func costumeVariants(variants []domain.CostumeVariant, price float64) []costume.CostumeVariantResponse {
+	responses := make([]costume.CostumeVariantResponse, len(variants))
+	for i, variant := range variants {
+		responses[i] = costume.CostumeVariantResponse{
+			Id:             variant.Id,
+			Size:           variant.Size,
+			Quantity:       variant.Quantity,
+			Available:      max(variant.Quantity-variant.Booked_today, 0),
+			Price:          variantPrice(variant, price),
+			Price_override: variant.Price,
+		}
+	}
+
+	return responses
+}
+
+func variantPrice(variant domain.CostumeVariant, price float64) float64 {
+	if variant.Price != nil {
+		return *variant.Price
+	}
+
+	return price
+}
+
+func costumeMeasurementResponse(measurement domain.CostumeMeasurement) *costume.CostumeMeasurementResponse {
+	return &costume.CostumeMeasurementResponse{
+		Chest:      measurement.Chest,
+		Waist:      measurement.Waist,
+		Hip:        measurement.Hip,
+		Height_min: measurement.Height_min,
+		Height_max: measurement.Height_max,
+		Shoe_size:  measurement.Shoe_size,
+	}
+}
+
+// findBuyerMeasurement loads the signed in buyer's measurements for list fit flags, anonymous requests pass "".
+func findBuyerMeasurement(ctx context.Context, tx *sql.Tx, repository *repository.UserMeasurementRepository, buyerId string) (domain.UserMeasurement, bool) {
+	if buyerId == "" {
+		return domain.UserMeasurement{}, false
+	}
+
+	measurement, err := repository.FindByUserId(ctx, tx, buyerId)
+	if err != nil {
+		return domain.UserMeasurement{}, false
+	}
+
+	return measurement, true
+}
+
+// listFit is the compact fit flag shown on list results, the per dimension breakdown is left to the fit endpoint.
+func listFit(measurement domain.CostumeMeasurement, buyerMeasurement domain.UserMeasurement) *costume.CostumeFitResponse {
+	fit := helper.ScoreFit(measurement, buyerMeasurement)
+	if fit.Score == nil {
+		return nil
+	}
+	fit.Dimensions = nil
+
+	return &fit
+}
+
+// pricingFromRequest checks the rules against each other, validate tags only cover single fields.
+func pricingFromRequest(pricingRequest costume.CostumePricingRequest) (domain.CostumePricing, error) {
+	pricing := domain.CostumePricing{
+		Costume_id:        pricingRequest.Costume_id,
+		Min_days:          max(pricingRequest.Min_days, 1),
+		Max_days:          pricingRequest.Max_days,
+		Weekend_surcharge: pricingRequest.Weekend_surcharge_percent,
+		Discounts:         []domain.PricingDiscount{},
+		Date_rules:        []domain.PricingDateRule{},
+	}
+
+	if pricing.Max_days != nil && *pricing.Max_days < pricing.Min_days {
+		return pricing, errors.New("max_days must not be less than min_days")
+	}
+
+	for _, discount := range pricingRequest.Discounts {
+		if slices.ContainsFunc(pricing.Discounts, func(d domain.PricingDiscount) bool { return d.Min_days == discount.Min_days }) {
+			return pricing, fmt.Errorf("more than one discount for %d days", discount.Min_days)
+		}
+		pricing.Discounts = append(pricing.Discounts, domain.PricingDiscount{Min_days: discount.Min_days, Percent: discount.Percent})
+	}
+	slices.SortFunc(pricing.Discounts, func(a, b domain.PricingDiscount) int { return a.Min_days - b.Min_days })
+
+	for _, rule := range pricingRequest.Date_rules {
+		start, end, err := helper.ParseRentalDates(rule.Start_date, rule.End_date)
+		if err != nil {
+			return pricing, fmt.Errorf("date rule %q: %s", rule.Label, err.Error())
+		}
+		if rule.Type == "surcharge" && rule.Value > 300 {
+			return pricing, fmt.Errorf("date rule %q: surcharge must not exceed 300 percent", rule.Label)
+		}
+		pricing.Date_rules = append(pricing.Date_rules, domain.PricingDateRule{
+			Label:      strings.TrimSpace(rule.Label),
+			Start_date: start.Format(helper.RentalDateLayout),
+			End_date:   end.Format(helper.RentalDateLayout),
+			Type:       rule.Type,
+			Value:      rule.Value,
+		})
+	}
+	slices.SortFunc(pricing.Date_rules, func(a, b domain.PricingDateRule) int { return strings.Compare(a.Start_date, b.Start_date) })
+
+	// a day can only be priced by one rule
+	for i := 1; i < len(pricing.Date_rules); i++ {
+		if pricing.Date_rules[i].Start_date <= pricing.Date_rules[i-1].End_date {
+			return pricing, fmt.Errorf("date rules %q and %q overlap", pricing.Date_rules[i-1].Label, pricing.Date_rules[i].Label)
+		}
+	}
+
+	return pricing, nil
+}
+
+// findCostumePricing falls back to the plain daily rate for listings whose seller never set pricing rules.
+func findCostumePricing(ctx context.Context, tx *sql.Tx, repository *repository.CostumePricingRepository, costumeId int) domain.CostumePricing {
+	pricing, err := repository.FindByCostumeId(ctx, tx, costumeId)
+	if err != nil {
+		return domain.CostumePricing{Costume_id: costumeId, Min_days: 1}
+	}
+
+	return pricing
+}
+
+func costumePricingResponse(pricing domain.CostumePricing, price float64) *costume.CostumePricingResponse {
+	response := &costume.CostumePricingResponse{
+		Daily_rate:                price,
+		Min_days:                  pricing.Min_days,
+		Max_days:                  pricing.Max_days,
+		Weekend_surcharge_percent: pricing.Weekend_surcharge,
+		Discounts:                 make([]costume.CostumePricingDiscountResponse, len(pricing.Discounts)),
+		Date_rules:                make([]costume.CostumePricingDateRuleResponse, len(pricing.Date_rules)),
+	}
+	for i, discount := range pricing.Discounts {
+		response.Discounts[i] = costume.CostumePricingDiscountResponse{Min_days: discount.Min_days, Percent: discount.Percent}
+	}
+	for i, rule := range pricing.Date_rules {
+		response.Date_rules[i] = costume.CostumePricingDateRuleResponse{
+			Label:      rule.Label,
+			Start_date: rule.Start_date,
+			End_date:   rule.End_date,
+			Type:       rule.Type,
+			Value:      rule.Value,
+		}
+	}
+
+	return response
+}
+
+// quoteCostume prices a date range at the requested variant's rate, or the listing price when no variant is given.
+func quoteCostume(costumeResponse costume.CostumeResponse, pricing domain.CostumePricing, quoteRequest costume.CostumeQuoteRequest) (costume.CostumePriceQuoteResponse, error) {
+	start, end, err := helper.ParseRentalDates(quoteRequest.Start_date, quoteRequest.End_date)
+	if err != nil {
+		return costume.CostumePriceQuoteResponse{}, err
+	}
+
+	rate := costumeResponse.Price
+	if quoteRequest.Variant_id != 0 {
+		index := slices.IndexFunc(costumeResponse.Variants, func(v costume.CostumeVariantResponse) bool { return v.Id == quoteRequest.Variant_id })
+		if index < 0 {
+			return costume.CostumePriceQuoteResponse{}, errors.New("variant not found")
+		}
+		rate = costumeResponse.Variants[index].Price
+	}
+
+	quote, err := helper.QuoteRental(rate, pricing, start, end, time.Now())
+	if err != nil {
+		return quote, err
+	}
+	quote.Variant_id = quoteRequest.Variant_id
+
+	return quote, nil
+}
+
+// sellerVacationResponse flags a listing from its seller's vacation, nil when the seller is not going away.
+func sellerVacationResponse(seller domain.User) *costume.SellerVacationResponse {
+	today := helper.RentalDay(time.Now())
+
+	start, end, ok := helper.VacationWindow(seller, today)
+	if !ok {
+		return nil
+	}
+
+	return &costume.SellerVacationResponse{
+		Start_date: start.Format(helper.RentalDateLayout),
+		End_date:   end.Format(helper.RentalDateLayout),
+		Message:    seller.Vacation_message,
+		Active:     !start.After(today),
+	}
 }
@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"cosplayrent/internal/model/domain"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeAttributeValue(t *testing.T) {
+	text := domain.CategoryAttribute{Key: "character", Type: "text"}
+	number := domain.CategoryAttribute{Key: "wig_length", Type: "number"}
+	boolean := domain.CategoryAttribute{Key: "includes_props", Type: "boolean"}
+	enum := domain.CategoryAttribute{Key: "franchise", Type: "enum", Options: []string{"Genshin Impact", "Naruto"}}
+	multiEnum := domain.CategoryAttribute{Key: "pieces", Type: "multi_enum", Options: []string{"Wig", "Shoes", "Props"}}
+
+	tests := []struct {
+		name       string
+		definition domain.CategoryAttribute
+		value      string
+		want       string
+		wantErr    bool
+	}{
+		{name: "text is trimmed", definition: text, value: `"  Raiden Shogun "`, want: `"Raiden Shogun"`},
+		{name: "blank text", definition: text, value: `"   "`, wantErr: true},
+		{name: "text of 100 characters", definition: text, value: `"` + strings.Repeat("a", 100) + `"`, want: `"` + strings.Repeat("a", 100) + `"`},
+		{name: "text over 100 characters", definition: text, value: `"` + strings.Repeat("a", 101) + `"`, wantErr: true},
+		{name: "text given a number", definition: text, value: `12`, wantErr: true},
+		{name: "number", definition: number, value: `45.5`, want: `45.5`},
+		{name: "number given a string", definition: number, value: `"45"`, wantErr: true},
+		{name: "boolean", definition: boolean, value: `true`, want: `true`},
+		{name: "boolean given a string", definition: boolean, value: `"yes"`, wantErr: true},
+		{name: "enum takes the option's spelling", definition: enum, value: `" naruto"`, want: `"Naruto"`},
+		{name: "enum outside the options", definition: enum, value: `"Bleach"`, wantErr: true},
+		{name: "multi_enum drops duplicates", definition: multiEnum, value: `["wig","Shoes","WIG"]`, want: `["Wig","Shoes"]`},
+		{name: "multi_enum outside the options", definition: multiEnum, value: `["Wig","Cape"]`, wantErr: true},
+		{name: "empty multi_enum", definition: multiEnum, value: `[]`, wantErr: true},
+		{name: "multi_enum given a single value", definition: multiEnum, value: `"Wig"`, wantErr: true},
+		{name: "unknown type", definition: domain.CategoryAttribute{Key: "size", Type: "date"}, value: `"2026-10-19"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeAttributeValue(tt.definition, json.RawMessage(tt.value))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("accepted %s as %s", tt.value, tt.definition.Type)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("normalized = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,7 +3,9 @@ package usecase
 import (
 	"bytes"
 	"context"
+	"cosplayrent/internal/helper"
 	"cosplayrent/internal/model/domain"
+	"cosplayrent/internal/model/web/notification"
 	"cosplayrent/internal/repository"
 	"database/sql"
 	"errors"
@@ -12,26 +14,34 @@ import (
 	"github.com/rs/zerolog"
 	"gopkg.in/gomail.v2"
 	"html/template"
+	"time"
 )
 
 const CONFIG_SMTP_HOST = "smtp.gmail.com"
 const CONFIG_SMTP_PORT = 587
 
+const userNotificationLimit = 50
+
+// emailBatchSize caps how many emails go out over one SMTP connection.
+const emailBatchSize = 50
+
 type NotificationUsecase struct {
-	NotificationRepository *repository.NotificationRepository
-	DB                     *sql.DB
-	Validate               *validator.Validate
-	Log                    *zerolog.Logger
-	Config                 *koanf.Koanf
+	NotificationRepository     *repository.NotificationRepository
+	UserNotificationRepository *repository.UserNotificationRepository
+	DB                         *sql.DB
+	Validate                   *validator.Validate
+	Log                        *zerolog.Logger
+	Config                     *koanf.Koanf
 }
 
-func NewNotificationUsecase(notificationRepository *repository.NotificationRepository, DB *sql.DB, validate *validator.Validate, zerolog *zerolog.Logger, koanf *koanf.Koanf) *NotificationUsecase {
+func NewNotificationUsecase(notificationRepository *repository.NotificationRepository, userNotificationRepository *repository.UserNotificationRepository, DB *sql.DB, validate *validator.Validate, zerolog *zerolog.Logger, koanf *koanf.Koanf) *NotificationUsecase {
 	return &NotificationUsecase{
-		NotificationRepository: notificationRepository,
-		DB:                     DB,
-		Validate:               validate,
-		Log:                    zerolog,
-		Config:                 koanf,
+		NotificationRepository:     notificationRepository,
+		UserNotificationRepository: userNotificationRepository,
+		DB:                         DB,
+		Validate:                   validate,
+		Log:                        zerolog,
+		Config:                     koanf,
 	}
 }
 
@@ -42,9 +52,44 @@ func (usecase *NotificationUsecase) SendRegisterNotification(ctx context.Context
 		usecase.Log.Panic().Err(err).Msg(respErr.Error())
 	}
 
-	template, err := template.New("emailtemplate").Parse(notification.Template_body)
+	data := domain.EmailNotification{
+		Username: username,
+		Code:     code,
+	}
+
+	err = usecase.sendEmail(notification, useremail, data)
 	if err != nil {
-		respErr := errors.New("failed to parse html template")
+		respErr := errors.New("failed to send register notification")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+// CreateIdentityCardNotification returns the email telling the user about the review decision, which the caller sends
+// with SendEmails once tx has committed.
+func (usecase *NotificationUsecase) CreateIdentityCardNotification(ctx context.Context, tx *sql.Tx, username string, useremail string, status string, reason string) *gomail.Message {
+	templateName := "identitycard_approved"
+	if status == "Rejected" {
+		templateName = "identitycard_rejected"
+	}
+
+	notification, err := usecase.NotificationRepository.FindNotificationTemplateByName(ctx, tx, templateName)
+	if err != nil {
+		respErr := errors.New("failed to find notification template")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	data := domain.EmailNotification{
+		Username: username,
+		Reason:   reason,
+	}
+
+	return usecase.emailMessage(notification, useremail, data)
+}
+
+func (usecase *NotificationUsecase) SendEmailChangeVerification(ctx context.Context, tx *sql.Tx, username string, newemail string, code string) {
+	notification, err := usecase.NotificationRepository.FindNotificationTemplateByName(ctx, tx, "email_change_verification")
+	if err != nil {
+		respErr := errors.New("failed to find notification template")
 		usecase.Log.Panic().Err(err).Msg(respErr.Error())
 	}
 
@@ -53,6 +98,126 @@ func (usecase *NotificationUsecase) SendRegisterNotification(ctx context.Context
 		Code:     code,
 	}
 
+	err = usecase.sendEmail(notification, newemail, data)
+	if err != nil {
+		respErr := errors.New("failed to send email change verification")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+func (usecase *NotificationUsecase) SendEmailChangedNotice(ctx context.Context, tx *sql.Tx, username string, oldemail string, newemail string) {
+	notification, err := usecase.NotificationRepository.FindNotificationTemplateByName(ctx, tx, "email_changed")
+	if err != nil {
+		respErr := errors.New("failed to find notification template")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	data := domain.EmailNotification{
+		Username: username,
+		NewEmail: newemail,
+	}
+
+	err = usecase.sendEmail(notification, oldemail, data)
+	if err != nil {
+		respErr := errors.New("failed to send email changed notice")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+}
+
+// CreateNewListingNotification puts the new listing into every follower's inbox within tx and returns their emails,
+// which the caller sends with SendEmails once tx has committed.
+func (usecase *NotificationUsecase) CreateNewListingNotification(ctx context.Context, tx *sql.Tx, followers []domain.User, sellerName string, costumeId int, costumeName string) []*gomail.Message {
+	if len(followers) == 0 {
+		return nil
+	}
+
+	notification, err := usecase.NotificationRepository.FindNotificationTemplateByName(ctx, tx, "new_listing")
+	if err != nil {
+		respErr := errors.New("failed to find notification template")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	now := time.Now()
+
+	followerIds := make([]string, len(followers))
+	for i, follower := range followers {
+		followerIds[i] = follower.Id
+	}
+	usecase.UserNotificationRepository.CreateForUsers(ctx, tx, followerIds, domain.UserNotification{
+		Type:       "new_listing",
+		Title:      "New costume from " + sellerName,
+		Body:       sellerName + " just listed " + costumeName + ".",
+		Costume_id: &costumeId,
+		Created_at: &now,
+	})
+
+	messages := make([]*gomail.Message, len(followers))
+	for i, follower := range followers {
+		messages[i] = usecase.emailMessage(notification, follower.Email, domain.EmailNotification{
+			Username: follower.Name,
+			Seller:   sellerName,
+			Costume:  costumeName,
+		})
+	}
+
+	return messages
+}
+
+// SendEmails sends the messages in batches in the background, a failed batch is logged and does not affect the caller.
+func (usecase *NotificationUsecase) SendEmails(messages []*gomail.Message) {
+	if len(messages) == 0 {
+		return
+	}
+
+	go func() {
+		for start := 0; start < len(messages); start += emailBatchSize {
+			err := usecase.emailDialer().DialAndSend(messages[start:min(start+emailBatchSize, len(messages))]...)
+			if err != nil {
+				usecase.Log.Warn().Err(err).Msg("failed to send notification emails")
+			}
+		}
+	}()
+}
+
+func (usecase *NotificationUsecase) FindUserNotifications(ctx context.Context, uuid string) notification.UserNotificationListResponse {
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	return notification.UserNotificationListResponse{
+		Unread:        usecase.UserNotificationRepository.CountUnread(ctx, tx, uuid),
+		Notifications: usecase.UserNotificationRepository.FindByUserId(ctx, tx, uuid, userNotificationLimit),
+	}
+}
+
+func (usecase *NotificationUsecase) MarkAllRead(ctx context.Context, uuid string) {
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	now := time.Now()
+	usecase.UserNotificationRepository.MarkAllRead(ctx, tx, uuid, &now)
+}
+
+func (usecase *NotificationUsecase) sendEmail(notification domain.Notification, useremail string, data domain.EmailNotification) error {
+	return usecase.emailDialer().DialAndSend(usecase.emailMessage(notification, useremail, data))
+}
+
+func (usecase *NotificationUsecase) emailMessage(notification domain.Notification, useremail string, data domain.EmailNotification) *gomail.Message {
+	template, err := template.New("emailtemplate").Parse(notification.Template_body)
+	if err != nil {
+		respErr := errors.New("failed to parse html template")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
 	var tmpl bytes.Buffer
 	err = template.Execute(&tmpl, data)
 	if err != nil {
@@ -61,8 +226,6 @@ func (usecase *NotificationUsecase) SendRegisterNotification(ctx context.Context
 	}
 
 	CONFIG_SENDER_NAME := usecase.Config.String("CONFIG_SENDER_NAME")
-	CONFIG_AUTH_EMAIL := usecase.Config.String("CONFIG_AUTH_EMAIL")
-	CONFIG_AUTH_PASSWORD := usecase.Config.String("CONFIG_AUTH_PASSWORD")
 
 	mailer := gomail.NewMessage()
 	mailer.SetHeader("From", CONFIG_SENDER_NAME)
@@ -70,16 +233,17 @@ func (usecase *NotificationUsecase) SendRegisterNotification(ctx context.Context
 	mailer.SetHeader("Subject", notification.Template_subject)
 	mailer.SetBody("text/html", tmpl.String())
 
-	dialer := gomail.NewDialer(
+	return mailer
+}
+
+func (usecase *NotificationUsecase) emailDialer() *gomail.Dialer {
+	CONFIG_AUTH_EMAIL := usecase.Config.String("CONFIG_AUTH_EMAIL")
+	CONFIG_AUTH_PASSWORD := usecase.Config.String("CONFIG_AUTH_PASSWORD")
+
+	return gomail.NewDialer(
 		CONFIG_SMTP_HOST,
 		CONFIG_SMTP_PORT,
 		CONFIG_AUTH_EMAIL,
 		CONFIG_AUTH_PASSWORD,
 	)
-
-	err = dialer.DialAndSend(mailer)
-	if err != nil {
-		respErr := errors.New("failed to send register notification")
-		usecase.Log.Panic().Err(err).Msg(respErr.Error())
-	}
 }
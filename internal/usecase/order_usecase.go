@@ -7,8 +7,12 @@ import (
 	"cosplayrent/internal/model/web/midtrans"
 	"cosplayrent/internal/model/web/order"
 	"cosplayrent/internal/repository"
+	"cosplayrent/internal/storage"
 	"database/sql"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator"
@@ -17,25 +21,38 @@ import (
 	"github.com/rs/zerolog"
 )
 
+// orderAdminFee is the flat fee the client adds to every order total, on top of the rental and the shipping cost.
+const orderAdminFee = 3000
+
 type OrderUsecase struct {
 	UserRepository     *repository.UserRepository
 	CostumeRepository  *repository.CostumeRepository
+	VariantRepository  *repository.CostumeVariantRepository
+	PricingRepository  *repository.CostumePricingRepository
+	BlackoutRepository *repository.CostumeBlackoutRepository
 	CategoryRepository *repository.CategoryRepository
 	OrderRepository    *repository.OrderRepository
+	EventRepository    *repository.CostumeEventRepository
 	MidtransUsecase    *MidtransUsecase
+	Storage            storage.Storage
 	DB                 *sql.DB
 	Validate           *validator.Validate
 	Log                *zerolog.Logger
 	Config             *koanf.Koanf
 }
 
-func NewOrderUsecase(userRepository *repository.UserRepository, costumeRepository *repository.CostumeRepository, categoryRepository *repository.CategoryRepository, orderRepository *repository.OrderRepository, midtransUsecase *MidtransUsecase, db *sql.DB, validator *validator.Validate, zerolog *zerolog.Logger, koanf *koanf.Koanf) *OrderUsecase {
+func NewOrderUsecase(userRepository *repository.UserRepository, costumeRepository *repository.CostumeRepository, variantRepository *repository.CostumeVariantRepository, pricingRepository *repository.CostumePricingRepository, blackoutRepository *repository.CostumeBlackoutRepository, categoryRepository *repository.CategoryRepository, orderRepository *repository.OrderRepository, eventRepository *repository.CostumeEventRepository, midtransUsecase *MidtransUsecase, storage storage.Storage, db *sql.DB, validator *validator.Validate, zerolog *zerolog.Logger, koanf *koanf.Koanf) *OrderUsecase {
 	return &OrderUsecase{
 		UserRepository:     userRepository,
 		CostumeRepository:  costumeRepository,
+		VariantRepository:  variantRepository,
+		PricingRepository:  pricingRepository,
+		BlackoutRepository: blackoutRepository,
 		CategoryRepository: categoryRepository,
 		OrderRepository:    orderRepository,
+		EventRepository:    eventRepository,
 		MidtransUsecase:    midtransUsecase,
+		Storage:            storage,
 		DB:                 db,
 		Validate:           validator,
 		Log:                zerolog,
@@ -51,6 +68,12 @@ func (usecase *OrderUsecase) Create(ctx context.Context, uuid string, userReques
 		return midtrans.MidtransResponse{}, respErr
 	}
 
+	startDate, endDate, err := helper.ParseRentalDates(userRequest.Start_date, userRequest.End_date)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return midtrans.MidtransResponse{}, err
+	}
+
 	tx, err := usecase.DB.Begin()
 	if err != nil {
 		respErr := errors.New("failed to start transaction")
@@ -59,6 +82,20 @@ func (usecase *OrderUsecase) Create(ctx context.Context, uuid string, userReques
 
 	defer helper.CommitOrRollback(tx)
 
+	_, err = usecase.UserRepository.CheckUserStatus(ctx, tx, uuid)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return midtrans.MidtransResponse{}, err
+	}
+
+	if userRequest.Payment_method == "Emoney" {
+		err = verifyTwoFactorCode(ctx, tx, usecase.UserRepository, usecase.Config.String("TWO_FACTOR_KEY"), uuid, userRequest.Totp_code, false)
+		if err != nil {
+			usecase.Log.Warn().Msg(err.Error())
+			return midtrans.MidtransResponse{}, err
+		}
+	}
+
 	userResult, err := usecase.UserRepository.FindBasicInfo(ctx, tx, uuid)
 	if err != nil {
 		usecase.Log.Warn().Msg(err.Error())
@@ -74,7 +111,9 @@ func (usecase *OrderUsecase) Create(ctx context.Context, uuid string, userReques
 		Costumer_id:          uuid,
 		Seller_id:            userRequest.Seller_id,
 		Costume_id:           userRequest.Costume_id,
-		Total_amount:         userRequest.TotalAmount - 3000,
+		Start_date:           &startDate,
+		End_date:             &endDate,
+		Total_amount:         userRequest.TotalAmount - orderAdminFee,
 		Shipment_origin:      userRequest.Shipment_origin,
 		Shipment_destination: userRequest.Shippment_destination,
 		Created_at:           &now,
@@ -119,13 +158,55 @@ func (usecase *OrderUsecase) Create(ctx context.Context, uuid string, userReques
 		return midtrans.MidtransResponse{}, err
 	}
 
+	err = usecase.checkSellerVacation(ctx, tx, userRequest.Seller_id, startDate, endDate)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return midtrans.MidtransResponse{}, err
+	}
+
 	err = usecase.CostumeRepository.CheckCostume(ctx, tx, userRequest.Seller_id, userRequest.Costume_id)
 	if err != nil {
 		usecase.Log.Warn().Msg(err.Error())
 		return midtrans.MidtransResponse{}, err
 	}
 
+	variant, rate, err := usecase.findOrderVariant(ctx, tx, userRequest)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return midtrans.MidtransResponse{}, err
+	}
+
+	quote, err := helper.QuoteRental(rate, findCostumePricing(ctx, tx, usecase.PricingRepository, userRequest.Costume_id), startDate, endDate, now)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return midtrans.MidtransResponse{}, err
+	}
+
+	if userRequest.Costume_price != quote.Total {
+		respErr := errors.New("costume price does not match the rental price")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return midtrans.MidtransResponse{}, respErr
+	}
+
+	// total is what gets stored, debited and sent to midtrans, shipping is added on top by the client so the only bound
+	// the server can hold it to is the quote plus the admin fee
+	if userRequest.TotalAmount < quote.Total+orderAdminFee {
+		respErr := errors.New("total is below the rental price")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return midtrans.MidtransResponse{}, respErr
+	}
+
+	// nothing has been written yet, so an unavailable error leaves the transaction clean for the commit
+	err = usecase.reserveRentalDates(ctx, tx, userRequest.Costume_id, variant.Id, startDate, endDate)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return midtrans.MidtransResponse{}, err
+	}
+
+	orderToDatabase.Variant_id = variant.Id
+
 	usecase.OrderRepository.Create(ctx, tx, orderToDatabase)
+	usecase.EventRepository.CreateForOrder(ctx, tx, orderid, "checkout", now)
 
 	if userRequest.Payment_method == "Emoney" {
 		usecase.UserRepository.AfterBuy(ctx, tx, userRequest.TotalAmount, &now, uuid, userRequest.Seller_id)
@@ -135,7 +216,7 @@ func (usecase *OrderUsecase) Create(ctx context.Context, uuid string, userReques
 		return midtrans.MidtransResponse{}, nil
 	}
 
-	userRequest.TotalAmount = userRequest.TotalAmount + 3000
+	userRequest.TotalAmount = userRequest.TotalAmount + orderAdminFee
 	result := usecase.MidtransUsecase.CreateTransaction(ctx, SendOrderToMidtrans)
 	payment.Midtrans_redirect_url = result.RedirectUrl
 	payment.Midtrans_url_expired_time = now.Add(24 * time.Hour)
@@ -147,6 +228,81 @@ func (usecase *OrderUsecase) Create(ctx context.Context, uuid string, userReques
 	return result, nil
 }
 
+// findOrderVariant picks the variant the order reserves and its daily rate, variant_id may only be left out for
+// single variant listings.
+func (usecase *OrderUsecase) findOrderVariant(ctx context.Context, tx *sql.Tx, userRequest order.OrderRequest) (domain.CostumeVariant, float64, error) {
+	costumeResult, err := usecase.CostumeRepository.FindById(ctx, tx, userRequest.Costume_id)
+	if err != nil {
+		return domain.CostumeVariant{}, 0, err
+	}
+
+	if costumeResult.Status != "published" {
+		return domain.CostumeVariant{}, 0, errors.New("costume is not available for rent")
+	}
+
+	variants := usecase.VariantRepository.FindByCostumeIds(ctx, tx, []int{userRequest.Costume_id})[userRequest.Costume_id]
+
+	variant := domain.CostumeVariant{}
+	if userRequest.Variant_id == 0 {
+		if len(variants) != 1 {
+			return domain.CostumeVariant{}, 0, errors.New("variant_id is required")
+		}
+		variant = variants[0]
+	} else {
+		index := slices.IndexFunc(variants, func(v domain.CostumeVariant) bool { return v.Id == userRequest.Variant_id })
+		if index < 0 {
+			return domain.CostumeVariant{}, 0, errors.New("variant not found")
+		}
+		variant = variants[index]
+	}
+
+	return variant, variantPrice(variant, costumeResult.Price), nil
+}
+
+// checkSellerVacation turns orders away while the seller is away, and for rentals that fall into an upcoming vacation.
+func (usecase *OrderUsecase) checkSellerVacation(ctx context.Context, tx *sql.Tx, sellerId string, start time.Time, end time.Time) error {
+	seller, err := usecase.UserRepository.FindVacationById(ctx, tx, sellerId)
+	if err != nil {
+		return err
+	}
+
+	today := helper.RentalDay(time.Now())
+
+	vacationStart, vacationEnd, ok := helper.VacationWindow(seller, today)
+	if !ok {
+		return nil
+	}
+
+	if !vacationStart.After(today) || (!start.After(vacationEnd) && !end.Before(vacationStart)) {
+		return fmt.Errorf("seller is on vacation from %s to %s", vacationStart.Format(helper.RentalDateLayout), vacationEnd.Format(helper.RentalDateLayout))
+	}
+
+	return nil
+}
+
+// reserveRentalDates checks the rental plus the costume's turnaround against blackouts and the units already booked. The
+// variant row stays locked until the order commits, so concurrent checkouts cannot both take the last free unit.
+func (usecase *OrderUsecase) reserveRentalDates(ctx context.Context, tx *sql.Tx, costumeId int, variantId int, start time.Time, end time.Time) error {
+	variant, err := usecase.VariantRepository.FindByIdForUpdate(ctx, tx, costumeId, variantId)
+	if err != nil {
+		return err
+	}
+
+	occupiedUntil := end.AddDate(0, 0, usecase.CostumeRepository.FindTurnaroundDays(ctx, tx, costumeId))
+
+	if usecase.BlackoutRepository.Overlaps(ctx, tx, costumeId, start, occupiedUntil) {
+		return errors.New("costume is unavailable on these dates")
+	}
+
+	for _, booked := range usecase.VariantRepository.BookedUnits(ctx, tx, costumeId, start, occupiedUntil)[variant.Id] {
+		if booked >= variant.Quantity {
+			return errors.New("variant is booked on these dates")
+		}
+	}
+
+	return usecase.VariantRepository.Reserve(ctx, tx, costumeId, variant.Id)
+}
+
 func (usecase *OrderUsecase) CheckStatusPayment(ctx context.Context, orderid string) (string, error) {
 	tx, err := usecase.DB.Begin()
 	if err != nil {
@@ -210,8 +366,6 @@ func (usecase *OrderUsecase) FindListPaymentTransaction(ctx context.Context, uui
 		return []order.PaymentTransationForOrderResponse{}, err
 	}
 
-	imageEnv := usecase.Config.String("IMAGE_ENV")
-
 	for i := range orders {
 		costumeResult, err := usecase.CostumeRepository.FindById(ctx, tx, orders[i].Costume_id)
 		if err != nil {
@@ -223,7 +377,7 @@ func (usecase *OrderUsecase) FindListPaymentTransaction(ctx context.Context, uui
 		orders[i].Costume_price = costumeResult.Price
 		orders[i].Costume_size = *costumeResult.Ukuran
 		if costumeResult.Picture != nil {
-			value := imageEnv + *costumeResult.Picture
+			value := usecase.Storage.URL(*costumeResult.Picture)
 			orders[i].Costume_picture = &value
 		}
 
@@ -269,7 +423,7 @@ func (usecase *OrderUsecase) GetDetailOrderByOrderId(ctx context.Context, seller
 		return order.OrderDetailByOrderIdResponse{}, err
 	}
 
-	userIdentityCardPicture, err := usecase.UserRepository.GetIdentityCard(ctx, tx, userResult.Id)
+	_, err = usecase.UserRepository.GetIdentityCard(ctx, tx, userResult.Id)
 	if err != nil {
 		usecase.Log.Warn().Msg(err.Error())
 		return order.OrderDetailByOrderIdResponse{}, err
@@ -281,17 +435,12 @@ func (usecase *OrderUsecase) GetDetailOrderByOrderId(ctx context.Context, seller
 		return order.OrderDetailByOrderIdResponse{}, err
 	}
 
-	imageEnv := usecase.Config.String("IMAGE_ENV")
-
 	if costumeResult.Picture != nil {
-		value := imageEnv + *costumeResult.Picture
+		value := usecase.Storage.URL(*costumeResult.Picture)
 		costumeResult.Picture = &value
 	}
 
-	if userIdentityCardPicture != "" {
-		value := imageEnv + userIdentityCardPicture
-		userIdentityCardPicture = value
-	}
+	userIdentityCardPicture := identityCardURL(usecase.Config.String("APP_URL"), userResult.Id)
 
 	orderResponse := order.OrderDetailByOrderIdResponse{
 		Costume_name:             costumeResult.Name,
@@ -324,6 +473,18 @@ func (usecase *OrderUsecase) CreateOrderEvent(ctx context.Context, uuid string,
 
 	defer helper.CommitOrRollback(tx)
 
+	orderResult, latestStatus, err := usecase.OrderRepository.FindEventStateById(ctx, tx, orderId)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return err
+	}
+
+	err = checkOrderEvent(orderResult, latestStatus, uuid, orderRequest.OrderEventStatus)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return err
+	}
+
 	now := time.Now()
 
 	orderEvent := domain.OrderEvents{
@@ -336,6 +497,52 @@ func (usecase *OrderUsecase) CreateOrderEvent(ctx context.Context, uuid string,
 	}
 
 	usecase.OrderRepository.CreateOrderEvents(ctx, tx, orderEvent)
+
+	// a finished or cancelled rental gives its unit back to the variant
+	if orderEvent.Status == "Completed" || orderEvent.Status == "Cancelled" {
+		variantId, ok := usecase.OrderRepository.ReleaseReservation(ctx, tx, orderId)
+		if ok {
+			usecase.VariantRepository.Release(ctx, tx, variantId)
+		}
+	}
+
+	if orderEvent.Status == "Completed" {
+		usecase.EventRepository.CreateForOrder(ctx, tx, orderId, "rental", now)
+	}
+
+	return nil
+}
+
+// checkOrderEvent decides whether the caller may add status to an order whose latest event is latestStatus. Only the
+// seller moves a paid order along and completes it, the customer may only cancel before the seller has acted on the
+// payment, and nothing follows a completed or cancelled order. Paid is written by the payment flow alone.
+func checkOrderEvent(orderResult domain.Order, latestStatus string, uuid string, status string) error {
+	if uuid != orderResult.Seller_id && uuid != orderResult.Costumer_id {
+		return errors.New("order not found")
+	}
+
+	if status == "Paid" {
+		return errors.New("the payment records Paid itself")
+	}
+
+	if latestStatus == "Completed" || latestStatus == "Cancelled" {
+		return errors.New("the order is already " + strings.ToLower(latestStatus))
+	}
+
+	if uuid != orderResult.Seller_id {
+		if status != "Cancelled" {
+			return errors.New("a customer can only cancel an order")
+		}
+		if latestStatus != "" && latestStatus != "Paid" {
+			return errors.New("the seller is already handling this order")
+		}
+		return nil
+	}
+
+	if status != "Cancelled" && latestStatus == "" {
+		return errors.New("the order is not paid yet")
+	}
+
 	return nil
 }
 
@@ -354,8 +561,6 @@ func (usecase *OrderUsecase) GetAllSellerOrder(ctx context.Context, sellerid str
 		return sellerOrderResult, err
 	}
 
-	imageEnv := usecase.Config.String("IMAGE_ENV")
-
 	for i := range sellerOrderResult {
 		costumeResult, err := usecase.CostumeRepository.FindById(ctx, tx, sellerOrderResult[i].Costume_id)
 		if err != nil {
@@ -368,7 +573,7 @@ func (usecase *OrderUsecase) GetAllSellerOrder(ctx context.Context, sellerid str
 		sellerOrderResult[i].Costume_price = costumeResult.Price
 		sellerOrderResult[i].Costume_size = costumeResult.Ukuran
 		if costumeResult.Picture != nil {
-			value := imageEnv + *costumeResult.Picture
+			value := usecase.Storage.URL(*costumeResult.Picture)
 			sellerOrderResult[i].Costume_picture = &value
 		}
 	}
@@ -409,10 +614,8 @@ func (usecase *OrderUsecase) GetUserDetailOrder(ctx context.Context, userid stri
 		return order.GetUserOrderDetailResponse{}, err
 	}
 
-	imageEnv := usecase.Config.String("IMAGE_ENV")
-
 	if costumeResult.Picture != nil {
-		value := imageEnv + *costumeResult.Picture
+		value := usecase.Storage.URL(*costumeResult.Picture)
 		costumeResult.Picture = &value
 	}
 
@@ -445,8 +648,6 @@ func (usecase *OrderUsecase) GetAllUserOrder(ctx context.Context, userid string)
 		return userOrderResult, err
 	}
 
-	imageEnv := usecase.Config.String("IMAGE_ENV")
-
 	for i := range userOrderResult {
 		costumeResult, err := usecase.CostumeRepository.FindById(ctx, tx, userOrderResult[i].Costume_id)
 		if err != nil {
@@ -459,7 +660,7 @@ func (usecase *OrderUsecase) GetAllUserOrder(ctx context.Context, userid string)
 		userOrderResult[i].Costume_price = costumeResult.Price
 		userOrderResult[i].Costume_size = costumeResult.Ukuran
 		if costumeResult.Picture != nil {
-			value := imageEnv + *costumeResult.Picture
+			value := usecase.Storage.URL(*costumeResult.Picture)
 			userOrderResult[i].Costume_picture = &value
 		}
 	}
@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"cosplayrent/internal/model/domain"
+	"testing"
+)
+
+func TestCheckOrderEvent(t *testing.T) {
+	orderResult := domain.Order{Id: "order", Seller_id: "seller", Costumer_id: "customer"}
+
+	tests := []struct {
+		name         string
+		latestStatus string
+		uuid         string
+		status       string
+		wantErr      bool
+	}{
+		{name: "third party completes", latestStatus: "Paid", uuid: "stranger", status: "Completed", wantErr: true},
+		{name: "third party cancels", latestStatus: "Paid", uuid: "stranger", status: "Cancelled", wantErr: true},
+		{name: "third party ships", latestStatus: "Paid", uuid: "stranger", status: "Shipped", wantErr: true},
+		{name: "seller completes a paid order", latestStatus: "Paid", uuid: "seller", status: "Completed"},
+		{name: "seller completes a shipped order", latestStatus: "Shipped", uuid: "seller", status: "Completed"},
+		{name: "seller completes an unpaid order", latestStatus: "", uuid: "seller", status: "Completed", wantErr: true},
+		{name: "seller completes twice", latestStatus: "Completed", uuid: "seller", status: "Completed", wantErr: true},
+		{name: "seller completes a cancelled order", latestStatus: "Cancelled", uuid: "seller", status: "Completed", wantErr: true},
+		{name: "seller cancels an unpaid order", latestStatus: "", uuid: "seller", status: "Cancelled"},
+		{name: "seller marks an order paid", latestStatus: "", uuid: "seller", status: "Paid", wantErr: true},
+		{name: "customer completes", latestStatus: "Shipped", uuid: "customer", status: "Completed", wantErr: true},
+		{name: "customer cancels a paid order", latestStatus: "Paid", uuid: "customer", status: "Cancelled"},
+		{name: "customer cancels an unpaid order", latestStatus: "", uuid: "customer", status: "Cancelled"},
+		{name: "customer cancels a shipped order", latestStatus: "Shipped", uuid: "customer", status: "Cancelled", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkOrderEvent(orderResult, tt.latestStatus, tt.uuid, tt.status)
+			if tt.wantErr && err == nil {
+				t.Fatalf("%s was allowed to add %s after %q", tt.uuid, tt.status, tt.latestStatus)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"cosplayrent/internal/model/domain"
 	"cosplayrent/internal/model/web/review"
 	"cosplayrent/internal/repository"
+	"cosplayrent/internal/storage"
 	"database/sql"
 	"errors"
 	"github.com/go-playground/validator"
@@ -19,17 +20,19 @@ type ReviewUsecase struct {
 	CostumeRepository *repository.CostumeRepository
 	ReviewRepository  *repository.ReviewRepository
 	OrderRepository   *repository.OrderRepository
+	Storage           storage.Storage
 	DB                *sql.DB
 	Validate          *validator.Validate
 	Log               *zerolog.Logger
 	Config            *koanf.Koanf
 }
 
-func NewReviewUsecase(userRepository *repository.UserRepository, costumeRepository *repository.CostumeRepository, reviewRepository *repository.ReviewRepository, db *sql.DB, validate *validator.Validate, zerolog *zerolog.Logger, koanf *koanf.Koanf) *ReviewUsecase {
+func NewReviewUsecase(userRepository *repository.UserRepository, costumeRepository *repository.CostumeRepository, reviewRepository *repository.ReviewRepository, storage storage.Storage, db *sql.DB, validate *validator.Validate, zerolog *zerolog.Logger, koanf *koanf.Koanf) *ReviewUsecase {
 	return &ReviewUsecase{
 		UserRepository:    userRepository,
 		CostumeRepository: costumeRepository,
 		ReviewRepository:  reviewRepository,
+		Storage:           storage,
 		DB:                db,
 		Validate:          validate,
 		Log:               zerolog,
@@ -77,12 +80,37 @@ func (usecase *ReviewUsecase) Create(ctx context.Context, request review.ReviewC
 	return nil
 }
 
-func (usecase *ReviewUsecase) Update(ctx context.Context, request review.ReviewUpdateRequest, uuid string, reviewid int) error {
-	err := usecase.Validate.Struct(request)
+func (usecase *ReviewUsecase) Update(ctx context.Context, request review.ReviewUpdateRequest, uuid string, reviewid int) (review.ReviewResponse, error) {
+	reviewResult, replacedPicture, err := usecase.updateRow(ctx, request, uuid, reviewid)
 	if err != nil {
-		respErr := errors.New("invalid request body")
-		usecase.Log.Warn().Err(respErr).Msg(err.Error())
-		return respErr
+		return reviewResult, err
+	}
+
+	// the row points at the new picture once updateRow has committed, a file that fails to go is only left unreferenced
+	if replacedPicture != nil {
+		err = helper.RemoveImage(ctx, usecase.Storage, *replacedPicture)
+		if err != nil {
+			respErr := errors.New("failed to remove review picture")
+			usecase.Log.Warn().Err(respErr).Msg(err.Error())
+		}
+	}
+
+	return reviewResult, nil
+}
+
+// updateRow applies the update and returns the updated review with the picture a new review_picture replaced, the
+// files are left for the caller to delete after the commit.
+func (usecase *ReviewUsecase) updateRow(ctx context.Context, request review.ReviewUpdateRequest, uuid string, reviewid int) (review.ReviewResponse, *string, error) {
+	patchErrors := []error{
+		helper.ValidatePatchField(usecase.Validate, "review_picture", request.Review_picture, false, "max=255"),
+		helper.ValidatePatchField(usecase.Validate, "description", request.Description, false, "min=1"),
+		helper.ValidatePatchField(usecase.Validate, "rating", request.Rating, false, "min=1,max=5"),
+	}
+	for _, err := range patchErrors {
+		if err != nil {
+			usecase.Log.Warn().Msg(err.Error())
+			return review.ReviewResponse{}, nil, err
+		}
 	}
 
 	tx, err := usecase.DB.Begin()
@@ -93,37 +121,52 @@ func (usecase *ReviewUsecase) Update(ctx context.Context, request review.ReviewU
 
 	defer helper.CommitOrRollback(tx)
 
-	err = usecase.ReviewRepository.CheckReviewId(ctx, tx, reviewid)
+	err = usecase.ReviewRepository.CheckReviewOwner(ctx, tx, reviewid, uuid)
 	if err != nil {
 		usecase.Log.Warn().Msg(err.Error())
-		return err
+		return review.ReviewResponse{}, nil, err
+	}
+
+	var replacedPicture *string
+	if request.Review_picture.Set {
+		current, err := usecase.ReviewRepository.FindById(ctx, tx, reviewid)
+		if err != nil {
+			usecase.Log.Warn().Msg(err.Error())
+			return review.ReviewResponse{}, nil, err
+		}
+		if current.Review_picture != nil && *current.Review_picture != request.Review_picture.Value {
+			replacedPicture = current.Review_picture
+		}
 	}
 
 	now := time.Now()
 
-	if request.Review_picture != nil {
-		review := domain.Review{
-			Id:             reviewid,
-			Review_picture: *request.Review_picture,
-			Description:    request.Description,
-			Rating:         request.Rating,
-			Updated_at:     &now,
-		}
+	usecase.ReviewRepository.Update(ctx, tx, reviewid, request, &now)
 
-		usecase.ReviewRepository.Update(ctx, tx, review)
-		return nil
+	reviewResult, err := usecase.ReviewRepository.FindById(ctx, tx, reviewid)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return reviewResult, nil, err
+	}
 
-	} else {
-		review := domain.Review{
-			Id:          reviewid,
-			Description: request.Description,
-			Rating:      request.Rating,
-			Updated_at:  &now,
-		}
+	userResult, err := usecase.UserRepository.FindNameAndProfile(ctx, tx, reviewResult.User_id)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return reviewResult, nil, err
+	}
 
-		usecase.ReviewRepository.Update(ctx, tx, review)
-		return nil
+	reviewResult.Name = userResult.Name
+	if userResult.Profile_picture != nil {
+		value := usecase.Storage.URL(*userResult.Profile_picture)
+		reviewResult.Profile_picture = &value
+	}
+	if reviewResult.Review_picture != nil {
+		reviewResult.Review_picture_variants = helper.ImageVariantURLs(usecase.Storage, reviewResult.Review_picture)
+		value := usecase.Storage.URL(*reviewResult.Review_picture)
+		reviewResult.Review_picture = &value
 	}
+
+	return reviewResult, replacedPicture, nil
 }
 
 func (usecase *ReviewUsecase) FindUserReview(ctx context.Context, uuid string) ([]review.UserReviewResponse, error) {
@@ -141,8 +184,6 @@ func (usecase *ReviewUsecase) FindUserReview(ctx context.Context, uuid string) (
 		return orderResult, err
 	}
 
-	imageEnv := usecase.Config.String("IMAGE_ENV")
-
 	for i := range orderResult {
 		costumeResult, err := usecase.CostumeRepository.FindCostumeInfoById(ctx, tx, orderResult[i].Custome_Id)
 		if err != nil {
@@ -155,7 +196,7 @@ func (usecase *ReviewUsecase) FindUserReview(ctx context.Context, uuid string) (
 		sellerNameResult, err := usecase.UserRepository.FindNameById(ctx, tx, orderResult[i].Seller_id)
 		orderResult[i].Seller_name = sellerNameResult
 		orderResult[i].Costume_name = costumeResult.Name
-		orderResult[i].Costume_picture = imageEnv + costumeResult.Costume_picture
+		orderResult[i].Costume_picture = usecase.Storage.URL(costumeResult.Costume_picture)
 		orderResult[i].Costume_size = costumeResult.Costume_size
 		orderResult[i].Costume_weight = costumeResult.Costume_weight
 		orderResult[i].Review_picture = reviewPicture
@@ -180,17 +221,16 @@ func (usecase *ReviewUsecase) FindByCostumeId(ctx context.Context, id int) ([]re
 		return review, err
 	}
 
-	imageEnv := usecase.Config.String("IMAGE_ENV")
-
 	for i := range review {
 		userResult, err := usecase.UserRepository.FindNameAndProfile(ctx, tx, review[i].User_id)
 		helper.PanicIfError(err)
 		review[i].Name = userResult.Name
 		if userResult.Profile_picture != nil {
-			value := imageEnv + *userResult.Profile_picture
+			value := usecase.Storage.URL(*userResult.Profile_picture)
 			review[i].Profile_picture = &value
 		}
-		value := imageEnv + *review[i].Review_picture
+		review[i].Review_picture_variants = helper.ImageVariantURLs(usecase.Storage, review[i].Review_picture)
+		value := usecase.Storage.URL(*review[i].Review_picture)
 		review[i].Review_picture = &value
 	}
 
@@ -212,8 +252,6 @@ func (usecase *ReviewUsecase) FindAllUserReview(ctx context.Context, userid stri
 		return []review.ListOfNonReviewedOrder{}, err
 	}
 
-	imageEnv := usecase.Config.String("IMAGE_ENV")
-
 	var fixedOrderResult []domain.Review
 
 	for i := range userOrderResult {
@@ -252,7 +290,7 @@ func (usecase *ReviewUsecase) FindAllUserReview(ctx context.Context, userid stri
 		}
 
 		if costumeResult.Picture != nil {
-			value := imageEnv + *costumeResult.Picture
+			value := usecase.Storage.URL(*costumeResult.Picture)
 			review.Costume_picture = &value
 		}
 
@@ -277,8 +315,6 @@ func (usecase *ReviewUsecase) FindAllReviewedOrder(ctx context.Context, userid s
 		return []review.ListOfReviewedOrder{}, err
 	}
 
-	imageEnv := usecase.Config.String("IMAGE_ENV")
-
 	var reviewResponse []review.ListOfReviewedOrder
 
 	for _, reviewResult := range userReviewResult {
@@ -298,7 +334,7 @@ func (usecase *ReviewUsecase) FindAllReviewedOrder(ctx context.Context, userid s
 		}
 
 		if costumeResult.Picture != nil {
-			value := imageEnv + *costumeResult.Picture
+			value := usecase.Storage.URL(*costumeResult.Picture)
 			review.Costume_picture = &value
 		}
 
@@ -323,9 +359,7 @@ func (usecase *ReviewUsecase) FindReviewInfoByOrderId(ctx context.Context, useri
 		return review.ReviewInfo{}, err
 	}
 
-	imageEnv := usecase.Config.String("IMAGE_ENV")
-
-	reviewInfoResponse.Review_picture = imageEnv + reviewInfoResponse.Review_picture
+	reviewInfoResponse.Review_picture = usecase.Storage.URL(reviewInfoResponse.Review_picture)
 
 	return reviewInfoResponse, nil
 }
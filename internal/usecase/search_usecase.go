@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"context"
+	"cosplayrent/internal/helper"
+	"cosplayrent/internal/model/web/costume"
+	"cosplayrent/internal/model/web/search"
+	"cosplayrent/internal/repository"
+	"cosplayrent/internal/storage"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/go-playground/validator"
+	"github.com/knadh/koanf/v2"
+	"github.com/rs/zerolog"
+)
+
+type SearchUsecase struct {
+	UserRepository             *repository.UserRepository
+	CostumeRepository          *repository.CostumeRepository
+	CostumeImageRepository     *repository.CostumeImageRepository
+	CostumeAttributeRepository *repository.CostumeAttributeRepository
+	MeasurementRepository      *repository.CostumeMeasurementRepository
+	UserMeasurementRepository  *repository.UserMeasurementRepository
+	CategoryRepository         *repository.CategoryRepository
+	Storage                    storage.Storage
+	Cache                      *memcache.Client
+	DB                         *sql.DB
+	Validate                   *validator.Validate
+	Log                        *zerolog.Logger
+	Config                     *koanf.Koanf
+}
+
+func NewSearchUsecase(userRepository *repository.UserRepository, costumeRepository *repository.CostumeRepository, costumeImageRepository *repository.CostumeImageRepository, costumeAttributeRepository *repository.CostumeAttributeRepository, measurementRepository *repository.CostumeMeasurementRepository, userMeasurementRepository *repository.UserMeasurementRepository, categoryRepository *repository.CategoryRepository, storage storage.Storage, cache *memcache.Client, DB *sql.DB, validate *validator.Validate, zerolog *zerolog.Logger, koanf *koanf.Koanf) *SearchUsecase {
+	return &SearchUsecase{
+		UserRepository:             userRepository,
+		CostumeRepository:          costumeRepository,
+		CostumeImageRepository:     costumeImageRepository,
+		CostumeAttributeRepository: costumeAttributeRepository,
+		MeasurementRepository:      measurementRepository,
+		UserMeasurementRepository:  userMeasurementRepository,
+		CategoryRepository:         categoryRepository,
+		Storage:                    storage,
+		Cache:                      cache,
+		DB:                         DB,
+		Validate:                   validate,
+		Log:                        zerolog,
+		Config:                     koanf,
+	}
+}
+
+func (usecase *SearchUsecase) SearchCostume(ctx context.Context, searchRequest costume.CostumeTextSearchRequest) (costume.CostumeSearchListResponse, error) {
+	if searchRequest.Limit == 0 {
+		searchRequest.Limit = 20
+	}
+
+	err := usecase.Validate.Struct(searchRequest)
+	if err != nil {
+		respErr := errors.New("invalid search parameters")
+		usecase.Log.Warn().Err(respErr).Msg(err.Error())
+		return costume.CostumeSearchListResponse{}, respErr
+	}
+
+	if searchRequest.Cursor != "" {
+		cursor, err := helper.DecodeCursor(searchRequest.Cursor)
+		if err != nil || cursor.Sort != "relevance" {
+			respErr := errors.New("invalid cursor")
+			usecase.Log.Warn().Msg(respErr.Error())
+			return costume.CostumeSearchListResponse{}, respErr
+		}
+		searchRequest.After_rank = &cursor.Value
+		searchRequest.After_id = cursor.Id
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	costumes := usecase.CostumeRepository.Search(ctx, tx, searchRequest)
+
+	listResponse := costume.CostumeSearchListResponse{}
+	if len(costumes) > searchRequest.Limit {
+		costumes = costumes[:searchRequest.Limit]
+		last := costumes[len(costumes)-1]
+		nextCursor := helper.EncodeCursor(helper.Cursor{Sort: "relevance", Value: last.Rank, Id: last.Id})
+		listResponse.Next_cursor = &nextCursor
+	}
+
+	costumeIds := make([]int, len(costumes))
+	for i := range costumes {
+		costumeIds[i] = costumes[i].Id
+	}
+	imagesByCostume := usecase.CostumeImageRepository.FindByCostumeIds(ctx, tx, costumeIds)
+	attributesByCostume := usecase.CostumeAttributeRepository.FindByCostumeIds(ctx, tx, costumeIds)
+	measurementsByCostume := usecase.MeasurementRepository.FindByCostumeIds(ctx, tx, costumeIds)
+	buyerMeasurement, hasBuyerMeasurement := findBuyerMeasurement(ctx, tx, usecase.UserMeasurementRepository, searchRequest.Buyer_id)
+
+	for i := range costumes {
+		if costumes[i].Picture != nil {
+			costumes[i].Picture_variants = helper.ImageVariantURLs(usecase.Storage, costumes[i].Picture)
+			value := usecase.Storage.URL(*costumes[i].Picture)
+			costumes[i].Picture = &value
+		}
+		costumes[i].Images = costumeImageURLs(usecase.Storage, imagesByCostume[costumes[i].Id])
+		costumes[i].Attributes = costumeAttributes(attributesByCostume[costumes[i].Id])
+
+		measurement, ok := measurementsByCostume[costumes[i].Id]
+		if ok && hasBuyerMeasurement {
+			costumes[i].Fit = listFit(measurement, buyerMeasurement)
+		}
+	}
+
+	listResponse.Costumes = costumes
+
+	return listResponse, nil
+}
+
+const (
+	suggestLimit    = 5
+	suggestCacheTTL = 60
+)
+
+func (usecase *SearchUsecase) Suggest(ctx context.Context, prefix string) (search.SearchSuggestResponse, error) {
+	prefix = strings.ToLower(strings.Join(strings.Fields(prefix), " "))
+	if utf8.RuneCountInString(prefix) < 2 || utf8.RuneCountInString(prefix) > 50 {
+		respErr := errors.New("q must be between 2 and 50 characters")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return search.SearchSuggestResponse{}, respErr
+	}
+
+	// memcache keys cannot contain spaces and stop at 250 bytes, 50 runes of hex encoded utf-8 can pass that
+	cacheKey := fmt.Sprintf("SearchSuggestCache_%x", sha1.Sum([]byte(prefix)))
+
+	cachedData, err := usecase.Cache.Get(cacheKey)
+	if err == nil && cachedData != nil {
+		var cachedResponse search.SearchSuggestResponse
+
+		err := json.Unmarshal(cachedData.Value, &cachedResponse)
+		if err == nil {
+			return cachedResponse, nil
+		}
+		usecase.Log.Warn().Err(err).Msg("failed to unmarshal cached suggestion")
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	suggestResponse := search.SearchSuggestResponse{
+		Costumes:   usecase.CostumeRepository.SuggestNames(ctx, tx, prefix, suggestLimit),
+		Categories: usecase.CategoryRepository.SuggestNames(ctx, tx, prefix, suggestLimit),
+		Sellers:    usecase.UserRepository.SuggestSellerNames(ctx, tx, prefix, suggestLimit),
+	}
+
+	// a cache outage only costs a database round trip, so it must not fail the request
+	cacheData, err := json.Marshal(suggestResponse)
+	if err == nil {
+		err = usecase.Cache.Set(&memcache.Item{
+			Key:        cacheKey,
+			Value:      cacheData,
+			Expiration: suggestCacheTTL,
+		})
+		if err != nil {
+			usecase.Log.Warn().Err(err).Msg("failed to set suggestion cache")
+		}
+	}
+
+	return suggestResponse, nil
+}
@@ -0,0 +1,200 @@
+package usecase
+
+import (
+	"context"
+	"cosplayrent/internal/helper"
+	"cosplayrent/internal/model/domain"
+	"cosplayrent/internal/model/web/costume"
+	"cosplayrent/internal/model/web/shop"
+	"cosplayrent/internal/repository"
+	"cosplayrent/internal/storage"
+	"database/sql"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/go-playground/validator"
+	"github.com/knadh/koanf/v2"
+	"github.com/rs/zerolog"
+)
+
+type ShopUsecase struct {
+	UserRepository     *repository.UserRepository
+	FollowRepository   *repository.FollowRepository
+	ActivityRepository *repository.CostumeActivityRepository
+	CostumeUsecase     *CostumeUsecase
+	Storage            storage.Storage
+	DB                 *sql.DB
+	Validate           *validator.Validate
+	Log                *zerolog.Logger
+	Config             *koanf.Koanf
+}
+
+func NewShopUsecase(userRepository *repository.UserRepository, followRepository *repository.FollowRepository, activityRepository *repository.CostumeActivityRepository, costumeUsecase *CostumeUsecase, storage storage.Storage, DB *sql.DB, validate *validator.Validate, zerolog *zerolog.Logger, koanf *koanf.Koanf) *ShopUsecase {
+	return &ShopUsecase{
+		UserRepository:     userRepository,
+		FollowRepository:   followRepository,
+		ActivityRepository: activityRepository,
+		CostumeUsecase:     costumeUsecase,
+		Storage:            storage,
+		DB:                 DB,
+		Validate:           validate,
+		Log:                zerolog,
+		Config:             koanf,
+	}
+}
+
+// FindShop returns a seller's public profile with one page of their published listings, searchRequest pages and
+// filters the listings the same way as the catalog.
+func (usecase *ShopUsecase) FindShop(ctx context.Context, sellerId string, searchRequest costume.CostumeSearchRequest) (shop.ShopResponse, error) {
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	seller, stats, err := usecase.UserRepository.FindShopById(ctx, tx, sellerId)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return shop.ShopResponse{}, err
+	}
+
+	searchRequest.Seller_id = seller.Id
+	listResponse, err := usecase.CostumeUsecase.FindAll(ctx, searchRequest)
+	if err != nil {
+		return shop.ShopResponse{}, err
+	}
+
+	stats.Average_rating = math.Round(stats.Average_rating*10) / 10
+	if stats.Response_rate != nil {
+		rate := math.Round(*stats.Response_rate)
+		stats.Response_rate = &rate
+	}
+
+	shopResponse := shop.ShopResponse{
+		Id:          seller.Id,
+		Name:        seller.Name,
+		Joined_at:   seller.Created_at.Format("2006-01-02"),
+		Verified:    seller.Identity_card_status == "Approved",
+		Following:   searchRequest.Buyer_id != "" && usecase.FollowRepository.Exists(ctx, tx, searchRequest.Buyer_id, seller.Id),
+		Vacation:    sellerVacationResponse(seller),
+		Stats:       stats,
+		Costumes:    listResponse.Costumes,
+		Next_cursor: listResponse.Next_cursor,
+	}
+
+	if seller.Profile_picture != nil {
+		shopResponse.Profile_picture_variants = helper.ImageVariantURLs(usecase.Storage, seller.Profile_picture)
+		value := usecase.Storage.URL(*seller.Profile_picture)
+		shopResponse.Profile_picture = &value
+	}
+
+	if seller.Origin_city_name != "" {
+		shopResponse.City = &seller.Origin_city_name
+	}
+
+	return shopResponse, nil
+}
+
+func (usecase *ShopUsecase) Follow(ctx context.Context, uuid string, sellerId string) error {
+	if uuid == sellerId {
+		respErr := errors.New("you cannot follow yourself")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return respErr
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	err = usecase.UserRepository.CheckUserExistance(ctx, tx, sellerId)
+	if err != nil {
+		respErr := errors.New("shop not found")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return respErr
+	}
+
+	now := time.Now()
+
+	usecase.FollowRepository.Create(ctx, tx, domain.Follow{
+		Follower_id: uuid,
+		Seller_id:   sellerId,
+		Created_at:  &now,
+	})
+
+	return nil
+}
+
+func (usecase *ShopUsecase) Unfollow(ctx context.Context, uuid string, sellerId string) {
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	usecase.FollowRepository.Delete(ctx, tx, uuid, sellerId)
+}
+
+// Feed lists new listings and restocks from the sellers the user follows, newest first.
+func (usecase *ShopUsecase) Feed(ctx context.Context, feedRequest shop.FeedRequest) (shop.FeedResponse, error) {
+	if feedRequest.Limit == 0 {
+		feedRequest.Limit = 20
+	}
+
+	err := usecase.Validate.Struct(feedRequest)
+	if err != nil {
+		respErr := errors.New("limit must be between 1 and 50")
+		usecase.Log.Warn().Err(respErr).Msg(err.Error())
+		return shop.FeedResponse{}, respErr
+	}
+
+	if feedRequest.Cursor != "" {
+		cursor, err := helper.DecodeCursor(feedRequest.Cursor)
+		if err != nil || cursor.Sort != "feed" {
+			respErr := errors.New("invalid cursor")
+			usecase.Log.Warn().Msg(respErr.Error())
+			return shop.FeedResponse{}, respErr
+		}
+		feedRequest.After_value = &cursor.Value
+		feedRequest.After_id = cursor.Id
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	items := usecase.ActivityRepository.FindFeed(ctx, tx, feedRequest.Follower_id, feedRequest.After_value, feedRequest.After_id, feedRequest.Limit)
+
+	feedResponse := shop.FeedResponse{}
+	if len(items) > feedRequest.Limit {
+		items = items[:feedRequest.Limit]
+		last := items[len(items)-1]
+		nextCursor := helper.EncodeCursor(helper.Cursor{Sort: "feed", Value: last.Sort_value, Id: last.Id})
+		feedResponse.Next_cursor = &nextCursor
+	}
+
+	costumes := make([]costume.CostumeResponse, len(items))
+	for i := range items {
+		costumes[i] = items[i].Costume
+	}
+	usecase.CostumeUsecase.completeCostumeList(ctx, tx, costumes, feedRequest.Follower_id)
+	for i := range items {
+		items[i].Costume = costumes[i]
+	}
+
+	feedResponse.Items = items
+
+	return feedResponse, nil
+}
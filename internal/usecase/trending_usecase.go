@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"cosplayrent/internal/helper"
+	"cosplayrent/internal/model/web/costume"
+	"cosplayrent/internal/repository"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/go-playground/validator"
+	"github.com/knadh/koanf/v2"
+	"github.com/rs/zerolog"
+)
+
+const (
+	trendingLimit    = 20
+	trendingCacheTTL = 300
+
+	popularityRefreshInterval = 15 * time.Minute
+	// an event counts half as much after popularityHalfLife and is dropped after popularityWindow
+	popularityHalfLife = 3 * 24 * time.Hour
+	popularityWindow   = 30 * 24 * time.Hour
+)
+
+// popularityWeights makes a completed rental outweigh a wishlist add, and both outweigh a page view.
+var popularityWeights = map[string]float64{
+	"view":     1,
+	"wishlist": 5,
+	"rental":   10,
+}
+
+type TrendingUsecase struct {
+	EventRepository      *repository.CostumeEventRepository
+	PopularityRepository *repository.CostumePopularityRepository
+	StatRepository       *repository.CostumeDailyStatRepository
+	CostumeUsecase       *CostumeUsecase
+	Cache                *memcache.Client
+	DB                   *sql.DB
+	Validate             *validator.Validate
+	Log                  *zerolog.Logger
+	Config               *koanf.Koanf
+}
+
+func NewTrendingUsecase(eventRepository *repository.CostumeEventRepository, popularityRepository *repository.CostumePopularityRepository, statRepository *repository.CostumeDailyStatRepository, costumeUsecase *CostumeUsecase, cache *memcache.Client, DB *sql.DB, validate *validator.Validate, zerolog *zerolog.Logger, koanf *koanf.Koanf) *TrendingUsecase {
+	return &TrendingUsecase{
+		EventRepository:      eventRepository,
+		PopularityRepository: popularityRepository,
+		StatRepository:       statRepository,
+		CostumeUsecase:       costumeUsecase,
+		Cache:                cache,
+		DB:                   DB,
+		Validate:             validate,
+		Log:                  zerolog,
+		Config:               koanf,
+	}
+}
+
+// FindTrending ranks published costumes by their decayed popularity score, costumes without recent activity fill the
+// end of the list newest first.
+func (usecase *TrendingUsecase) FindTrending(ctx context.Context, trendingRequest costume.CostumeTrendingRequest) ([]costume.CostumeResponse, error) {
+	if trendingRequest.Limit == 0 {
+		trendingRequest.Limit = trendingLimit
+	}
+
+	err := usecase.Validate.Struct(trendingRequest)
+	if err != nil {
+		respErr := errors.New("invalid trending parameters")
+		usecase.Log.Warn().Err(respErr).Msg(err.Error())
+		return nil, respErr
+	}
+
+	cacheKey := fmt.Sprintf("TrendingCostumeCache_%d_%d_%d", trendingRequest.Kategori, trendingRequest.City_id, trendingRequest.Limit)
+
+	cachedData, err := usecase.Cache.Get(cacheKey)
+	if err == nil && cachedData != nil {
+		var cachedResponse []costume.CostumeResponse
+
+		err := json.Unmarshal(cachedData.Value, &cachedResponse)
+		if err == nil {
+			return cachedResponse, nil
+		}
+		usecase.Log.Warn().Err(err).Msg("failed to unmarshal cached trending costumes")
+	}
+
+	listResponse, err := usecase.CostumeUsecase.FindAll(ctx, costume.CostumeSearchRequest{
+		Kategori: trendingRequest.Kategori,
+		City_id:  trendingRequest.City_id,
+		Sort:     "trending",
+		Limit:    trendingRequest.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// a cache outage only costs a database round trip, so it must not fail the request
+	cacheData, err := json.Marshal(listResponse.Costumes)
+	if err == nil {
+		err = usecase.Cache.Set(&memcache.Item{
+			Key:        cacheKey,
+			Value:      cacheData,
+			Expiration: trendingCacheTTL,
+		})
+		if err != nil {
+			usecase.Log.Warn().Err(err).Msg("failed to set trending cache")
+		}
+	}
+
+	return listResponse.Costumes, nil
+}
+
+// RefreshPopularity also rolls the events up into the sellers' daily stats before the old ones are pruned. Every day
+// whose events are all still kept is recounted, so a missed run catches up and older days keep their last count.
+func (usecase *TrendingUsecase) RefreshPopularity(ctx context.Context) {
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	now := time.Now()
+	since := now.Add(-popularityWindow)
+
+	usecase.StatRepository.Rollup(ctx, tx, since.AddDate(0, 0, 1).Format(helper.RentalDateLayout))
+	usecase.EventRepository.DeleteBefore(ctx, tx, since)
+	usecase.PopularityRepository.Refresh(ctx, tx, now, since, popularityHalfLife, popularityWeights)
+}
+
+// RunPopularityRefresh rescores costumes right away and then every popularityRefreshInterval, it never returns.
+func (usecase *TrendingUsecase) RunPopularityRefresh() {
+	ticker := time.NewTicker(popularityRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		usecase.refreshPopularityOnce()
+		<-ticker.C
+	}
+}
+
+// refreshPopularityOnce keeps a failed run from taking the server down, the next tick tries again.
+func (usecase *TrendingUsecase) refreshPopularityOnce() {
+	defer func() {
+		if r := recover(); r != nil {
+			usecase.Log.Error().Interface("panic", r).Msg("failed to refresh costume popularity")
+		}
+	}()
+
+	usecase.RefreshPopularity(context.Background())
+}
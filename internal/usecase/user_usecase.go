@@ -6,12 +6,14 @@ import (
 	"cosplayrent/internal/model/domain"
 	"cosplayrent/internal/model/web/user"
 	"cosplayrent/internal/repository"
+	"cosplayrent/internal/storage"
 	"crypto/rand"
 	"database/sql"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"math/big"
-	"os"
+	"strings"
 	"time"
 
 	"github.com/knadh/koanf/v2"
@@ -21,27 +23,50 @@ import (
 	googleuuid "github.com/google/uuid"
 	"github.com/rs/zerolog"
 	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/gomail.v2"
+)
+
+// maxVacationDays caps how long a shop can be closed in one go.
+const maxVacationDays = 365
+
+const (
+	twoFactorChallengeTTL = 5 * time.Minute
+	// a login challenge is dropped after this many wrong codes, the user has to enter the password again. The account
+	// counts wrong codes from every check as well and is locked for twoFactorLockout at the same count, a pending
+	// enrollment is dropped instead
+	maxTwoFactorAttempts = 5
+	twoFactorLockout     = 15 * time.Minute
+	// a pending email change is dropped after this many wrong codes, the user has to request a new code
+	maxEmailChangeAttempts = 5
 )
 
 type UserUsecase struct {
-	UserRepository      *repository.UserRepository
-	CostumeRepository   *repository.CostumeRepository
-	NotificationUsecase *NotificationUsecase
-	DB                  *sql.DB
-	Validate            *validator.Validate
-	Log                 *zerolog.Logger
-	Config              *koanf.Koanf
+	UserRepository        *repository.UserRepository
+	CostumeRepository     *repository.CostumeRepository
+	OrderRepository       *repository.OrderRepository
+	MeasurementRepository *repository.UserMeasurementRepository
+	NotificationUsecase   *NotificationUsecase
+	Storage               storage.Storage
+	PrivateStorage        storage.Storage
+	DB                    *sql.DB
+	Validate              *validator.Validate
+	Log                   *zerolog.Logger
+	Config                *koanf.Koanf
 }
 
-func NewUserUsecase(userRepository *repository.UserRepository, costumeRepository *repository.CostumeRepository, notificationUsecase *NotificationUsecase, DB *sql.DB, validate *validator.Validate, zerolog *zerolog.Logger, koanf *koanf.Koanf) *UserUsecase {
+func NewUserUsecase(userRepository *repository.UserRepository, costumeRepository *repository.CostumeRepository, orderRepository *repository.OrderRepository, measurementRepository *repository.UserMeasurementRepository, notificationUsecase *NotificationUsecase, storage storage.Storage, privateStorage storage.Storage, DB *sql.DB, validate *validator.Validate, zerolog *zerolog.Logger, koanf *koanf.Koanf) *UserUsecase {
 	return &UserUsecase{
-		UserRepository:      userRepository,
-		CostumeRepository:   costumeRepository,
-		NotificationUsecase: notificationUsecase,
-		DB:                  DB,
-		Validate:            validate,
-		Log:                 zerolog,
-		Config:              koanf,
+		UserRepository:        userRepository,
+		CostumeRepository:     costumeRepository,
+		OrderRepository:       orderRepository,
+		MeasurementRepository: measurementRepository,
+		NotificationUsecase:   notificationUsecase,
+		Storage:               storage,
+		PrivateStorage:        privateStorage,
+		DB:                    DB,
+		Validate:              validate,
+		Log:                   zerolog,
+		Config:                koanf,
 	}
 }
 
@@ -94,48 +119,996 @@ func (usecase *UserUsecase) Create(ctx context.Context, request user.UserCreateR
 		"expired": time.Date(2030, 10, 10, 12, 0, 0, 0, time.UTC).Unix(),
 	})
 
-	tokenString, err := token.SignedString(secretKeyByte)
+	tokenString, err := token.SignedString(secretKeyByte)
+	if err != nil {
+		respErr := errors.New("failed to sign a token")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	code, err := generateVerificationCode()
+	if err != nil {
+		return "", err
+	}
+
+	expiredAt := now.Add(5 * time.Minute)
+
+	UserVerification := domain.UserVerification{
+		User_id:           user.Id,
+		Verification_code: code,
+		Created_at:        &now,
+		Updated_at:        &now,
+		Expired_at:        &expiredAt,
+	}
+
+	usecase.UserRepository.CreateUserVerification(ctx, tx, UserVerification)
+
+	usecase.NotificationUsecase.SendRegisterNotification(ctx, tx, user.Name, user.Email, code)
+
+	return tokenString, nil
+}
+
+func (usecase *UserUsecase) Login(ctx context.Context, request user.UserLoginRequest) (user.UserLoginResponse, error) {
+	err := usecase.Validate.Struct(request)
+	if err != nil {
+		respErr := errors.New("invalid request body")
+		usecase.Log.Warn().Err(respErr).Msg(err.Error())
+		return user.UserLoginResponse{}, respErr
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	userRequest := domain.User{
+		Email:    request.Email,
+		Password: request.Password,
+	}
+
+	userResult, err := usecase.UserRepository.Login(ctx, tx, userRequest.Email)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return user.UserLoginResponse{}, err
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(userResult.Password), []byte(userRequest.Password))
+	if err != nil {
+		respErr := errors.New("wrong password")
+		usecase.Log.Warn().Err(respErr).Msg(err.Error())
+		return user.UserLoginResponse{}, respErr
+	}
+
+	twoFactorResult, err := usecase.UserRepository.FindTwoFactorById(ctx, tx, userResult.Id)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return user.UserLoginResponse{}, err
+	}
+
+	secretKeyByte := []byte(usecase.Config.String("SECRET_KEY"))
+
+	if twoFactorResult.Totp_enabled {
+		now := time.Now()
+		expiresAt := now.Add(twoFactorChallengeTTL)
+		challengeId := googleuuid.New().String()
+
+		usecase.UserRepository.DeleteExpiredTwoFactorChallenges(ctx, tx, userResult.Id, now)
+		usecase.UserRepository.CreateTwoFactorChallenge(ctx, tx, domain.TwoFactorChallenge{
+			Id:         challengeId,
+			User_id:    userResult.Id,
+			Expires_at: &expiresAt,
+			Created_at: &now,
+		})
+
+		challenge := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"id":      userResult.Id,
+			"jti":     challengeId,
+			"purpose": "2fa_challenge",
+			"exp":     expiresAt.Unix(),
+		})
+
+		challengeString, err := challenge.SignedString(secretKeyByte)
+		if err != nil {
+			respErr := errors.New("failed to sign a token")
+			usecase.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+
+		return user.UserLoginResponse{Two_factor_required: true, Challenge_token: challengeString}, nil
+	}
+
+	return user.UserLoginResponse{Token: usecase.issueToken(userResult.Id)}, nil
+}
+
+func (usecase *UserUsecase) LoginTwoFactor(ctx context.Context, request user.TwoFactorLoginRequest) (string, error) {
+	err := usecase.Validate.Struct(request)
+	if err != nil {
+		respErr := errors.New("invalid request body")
+		usecase.Log.Warn().Err(respErr).Msg(err.Error())
+		return "", respErr
+	}
+
+	secretKeyByte := []byte(usecase.Config.String("SECRET_KEY"))
+
+	challenge, err := jwt.Parse(request.Challenge_token, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return secretKeyByte, nil
+	})
+
+	// a malformed token parses to a nil token
+	if err != nil || challenge == nil || !challenge.Valid {
+		respErr := errors.New("invalid or expired challenge token")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return "", respErr
+	}
+
+	claims, ok := challenge.Claims.(jwt.MapClaims)
+	userId, _ := claims["id"].(string)
+	challengeId, _ := claims["jti"].(string)
+	if !ok || claims["purpose"] != "2fa_challenge" || userId == "" || challengeId == "" {
+		respErr := errors.New("invalid or expired challenge token")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return "", respErr
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	challengeResult, err := usecase.UserRepository.FindTwoFactorChallenge(ctx, tx, challengeId)
+	if err != nil || challengeResult.User_id != userId || time.Now().After(*challengeResult.Expires_at) {
+		respErr := errors.New("invalid or expired challenge token")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return "", respErr
+	}
+
+	// the failed attempt is committed together with the error, the transaction only rolls back on a panic
+	err = verifyTwoFactorCode(ctx, tx, usecase.UserRepository, usecase.Config.String("TWO_FACTOR_KEY"), userId, request.Code, true)
+	if err != nil {
+		if challengeResult.Attempts+1 >= maxTwoFactorAttempts {
+			usecase.UserRepository.DeleteTwoFactorChallenge(ctx, tx, challengeId)
+			respErr := errors.New("too many wrong codes, log in again")
+			usecase.Log.Warn().Err(err).Msg(respErr.Error())
+			return "", respErr
+		}
+		usecase.UserRepository.IncrementTwoFactorChallengeAttempts(ctx, tx, challengeId)
+		usecase.Log.Warn().Msg(err.Error())
+		return "", err
+	}
+
+	usecase.UserRepository.DeleteTwoFactorChallenge(ctx, tx, challengeId)
+
+	return usecase.issueToken(userId), nil
+}
+
+func (usecase *UserUsecase) EnrollTwoFactor(ctx context.Context, uuid string) (user.TwoFactorEnrollResponse, error) {
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	userResult, err := usecase.UserRepository.FindTwoFactorById(ctx, tx, uuid)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return user.TwoFactorEnrollResponse{}, err
+	}
+
+	if userResult.Totp_enabled {
+		respErr := errors.New("two factor authentication is already enabled")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return user.TwoFactorEnrollResponse{}, respErr
+	}
+
+	secret, err := helper.GenerateTOTPSecret()
+	if err != nil {
+		respErr := errors.New("failed to generate two factor secret")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	encryptedSecret, err := helper.Encrypt(usecase.Config.String("TWO_FACTOR_KEY"), []byte(secret))
+	if err != nil {
+		respErr := errors.New("failed to encrypt two factor secret")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	storedSecret := base64.StdEncoding.EncodeToString(encryptedSecret)
+
+	userDomain := domain.User{
+		Id:          uuid,
+		Totp_secret: &storedSecret,
+	}
+
+	usecase.UserRepository.UpdateTwoFactorSecret(ctx, tx, userDomain)
+
+	return user.TwoFactorEnrollResponse{
+		Secret:      secret,
+		Otpauth_uri: helper.TOTPProvisioningURI("CosplayRent", userResult.Email, secret),
+	}, nil
+}
+
+func (usecase *UserUsecase) ConfirmTwoFactor(ctx context.Context, request user.TwoFactorCodeRequest, uuid string) (user.TwoFactorRecoveryCodesResponse, error) {
+	err := usecase.Validate.Struct(request)
+	if err != nil {
+		respErr := errors.New("invalid request body")
+		usecase.Log.Warn().Err(respErr).Msg(err.Error())
+		return user.TwoFactorRecoveryCodesResponse{}, respErr
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	userResult, err := usecase.UserRepository.FindTwoFactorById(ctx, tx, uuid)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return user.TwoFactorRecoveryCodesResponse{}, err
+	}
+
+	if userResult.Totp_enabled {
+		respErr := errors.New("two factor authentication is already enabled")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return user.TwoFactorRecoveryCodesResponse{}, respErr
+	}
+
+	if userResult.Totp_secret == nil {
+		respErr := errors.New("two factor enrollment not started")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return user.TwoFactorRecoveryCodesResponse{}, respErr
+	}
+
+	secret := decryptTwoFactorSecret(usecase.Log, usecase.Config.String("TWO_FACTOR_KEY"), *userResult.Totp_secret)
+
+	// the failed attempt is committed together with the error, the transaction only rolls back on a panic
+	step, ok := helper.ValidateTOTP(secret, request.Code, time.Now())
+	if !ok {
+		if userResult.Totp_attempts+1 >= maxTwoFactorAttempts {
+			usecase.UserRepository.UpdateTwoFactorSecret(ctx, tx, domain.User{Id: uuid})
+			respErr := errors.New("too many wrong codes, enroll again")
+			usecase.Log.Warn().Msg(respErr.Error())
+			return user.TwoFactorRecoveryCodesResponse{}, respErr
+		}
+		usecase.UserRepository.UpdateTwoFactorAttempts(ctx, tx, domain.User{Id: uuid, Totp_attempts: userResult.Totp_attempts + 1})
+		respErr := errors.New("invalid two factor code")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return user.TwoFactorRecoveryCodesResponse{}, respErr
+	}
+
+	userDomain := domain.User{
+		Id:             uuid,
+		Totp_last_step: &step,
+	}
+
+	usecase.UserRepository.EnableTwoFactor(ctx, tx, userDomain)
+	usecase.UserRepository.DeleteRecoveryCodes(ctx, tx, uuid)
+
+	now := time.Now()
+	recoveryCodes := make([]string, 10)
+
+	for i := range recoveryCodes {
+		firstHalf, err := generateVerificationCode()
+		if err != nil {
+			respErr := errors.New("failed to generate recovery code")
+			usecase.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		secondHalf, err := generateVerificationCode()
+		if err != nil {
+			respErr := errors.New("failed to generate recovery code")
+			usecase.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		recoveryCodes[i] = firstHalf + "-" + secondHalf
+
+		hashedCode, err := bcrypt.GenerateFromPassword([]byte(recoveryCodes[i]), bcrypt.DefaultCost)
+		if err != nil {
+			respErr := errors.New("error generating recovery code hash")
+			usecase.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+
+		usecase.UserRepository.CreateRecoveryCode(ctx, tx, domain.UserRecoveryCode{
+			User_id:    uuid,
+			Code_hash:  string(hashedCode),
+			Created_at: &now,
+		})
+	}
+
+	return user.TwoFactorRecoveryCodesResponse{Recovery_codes: recoveryCodes}, nil
+}
+
+func (usecase *UserUsecase) DisableTwoFactor(ctx context.Context, request user.TwoFactorCodeRequest, uuid string) error {
+	err := usecase.Validate.Struct(request)
+	if err != nil {
+		respErr := errors.New("invalid request body")
+		usecase.Log.Warn().Err(respErr).Msg(err.Error())
+		return respErr
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	userResult, err := usecase.UserRepository.FindTwoFactorById(ctx, tx, uuid)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return err
+	}
+
+	if !userResult.Totp_enabled {
+		respErr := errors.New("two factor authentication is not enabled")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return respErr
+	}
+
+	err = verifyTwoFactorCode(ctx, tx, usecase.UserRepository, usecase.Config.String("TWO_FACTOR_KEY"), uuid, request.Code, true)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return err
+	}
+
+	usecase.UserRepository.DisableTwoFactor(ctx, tx, uuid)
+	usecase.UserRepository.DeleteRecoveryCodes(ctx, tx, uuid)
+
+	return nil
+}
+
+func (usecase *UserUsecase) VerifyTwoFactor(ctx context.Context, uuid string, code string) error {
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	err = verifyTwoFactorCode(ctx, tx, usecase.UserRepository, usecase.Config.String("TWO_FACTOR_KEY"), uuid, code, false)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (usecase *UserUsecase) issueToken(userId string) string {
+	secretKey := usecase.Config.String("SECRET_KEY")
+	secretKeyByte := []byte(secretKey)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"id":      userId,
+		"expired": time.Date(2030, 10, 10, 12, 0, 0, 0, time.UTC).Unix(),
+	})
+
+	tokenString, err := token.SignedString(secretKeyByte)
+	if err != nil {
+		respErr := errors.New("failed to sign a token")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return tokenString
+}
+
+// verifyTwoFactorCode passes when the user has not enabled two factor authentication.
+// Recovery codes are only accepted when allowRecovery is set, wallet actions require a live TOTP code.
+// Wrong codes are counted on the account, so login, disabling and wallet actions share one budget, and the failed
+// attempt is committed together with the error, the transaction only rolls back on a panic.
+func verifyTwoFactorCode(ctx context.Context, tx *sql.Tx, userRepository *repository.UserRepository, key string, uuid string, code string, allowRecovery bool) error {
+	userResult, err := userRepository.FindTwoFactorById(ctx, tx, uuid)
+	if err != nil {
+		return err
+	}
+
+	if !userResult.Totp_enabled {
+		return nil
+	}
+
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return errors.New("two factor code required")
+	}
+
+	now := time.Now()
+
+	err = checkTwoFactorLockout(userResult, now)
+	if err != nil {
+		return err
+	}
+
+	err = matchTwoFactorCode(ctx, tx, userRepository, key, userResult, code, allowRecovery, now)
+	if err != nil {
+		userResult = countWrongTwoFactorCode(userResult, now)
+		userRepository.UpdateTwoFactorAttempts(ctx, tx, userResult)
+		if lockoutErr := checkTwoFactorLockout(userResult, now); lockoutErr != nil {
+			return lockoutErr
+		}
+		return err
+	}
+
+	if userResult.Totp_attempts > 0 || userResult.Totp_locked_until != nil {
+		userRepository.UpdateTwoFactorAttempts(ctx, tx, domain.User{Id: uuid})
+	}
+
+	return nil
+}
+
+func matchTwoFactorCode(ctx context.Context, tx *sql.Tx, userRepository *repository.UserRepository, key string, userResult domain.User, code string, allowRecovery bool, now time.Time) error {
+	secret := decryptTwoFactorSecret(userRepository.Log, key, *userResult.Totp_secret)
+	step, ok := helper.ValidateTOTP(secret, code, now)
+	if ok {
+		if userResult.Totp_last_step != nil && step <= *userResult.Totp_last_step {
+			return errors.New("two factor code already used")
+		}
+		userRepository.UpdateTwoFactorLastStep(ctx, tx, domain.User{Id: userResult.Id, Totp_last_step: &step})
+		return nil
+	}
+
+	if allowRecovery {
+		for _, recoveryCode := range userRepository.FindUnusedRecoveryCodes(ctx, tx, userResult.Id) {
+			if bcrypt.CompareHashAndPassword([]byte(recoveryCode.Code_hash), []byte(code)) == nil {
+				recoveryCode.Used_at = &now
+				userRepository.UseRecoveryCode(ctx, tx, recoveryCode)
+				return nil
+			}
+		}
+	}
+
+	return errors.New("invalid two factor code")
+}
+
+// checkTwoFactorLockout refuses every code while the account is locked, a right one included, so guessing cannot go
+// on past maxTwoFactorAttempts.
+func checkTwoFactorLockout(userResult domain.User, now time.Time) error {
+	if userResult.Totp_locked_until != nil && now.Before(*userResult.Totp_locked_until) {
+		return errors.New("too many wrong codes, try again later")
+	}
+	return nil
+}
+
+// countWrongTwoFactorCode adds a wrong code to the account, the maxTwoFactorAttempts-th in a row locks it for
+// twoFactorLockout and starts the count again.
+func countWrongTwoFactorCode(userResult domain.User, now time.Time) domain.User {
+	userResult.Totp_attempts++
+	if userResult.Totp_attempts >= maxTwoFactorAttempts {
+		lockedUntil := now.Add(twoFactorLockout)
+		userResult.Totp_attempts = 0
+		userResult.Totp_locked_until = &lockedUntil
+	}
+	return userResult
+}
+
+func decryptTwoFactorSecret(log *zerolog.Logger, key string, storedSecret string) string {
+	encryptedSecret, err := base64.StdEncoding.DecodeString(storedSecret)
+	if err != nil {
+		respErr := errors.New("failed to decode two factor secret")
+		log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	secret, err := helper.Decrypt(key, encryptedSecret)
+	if err != nil {
+		respErr := errors.New("failed to decrypt two factor secret")
+		log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return string(secret)
+}
+
+func (usecase *UserUsecase) FindByUUID(ctx context.Context, uuid string) (user.UserResponse, error) {
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	user := user.UserResponse{}
+	user, err = usecase.UserRepository.FindByUUID(ctx, tx, uuid)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return user, err
+	}
+
+	if user.Profile_picture != nil {
+		user.Profile_picture_variants = helper.ImageVariantURLs(usecase.Storage, user.Profile_picture)
+		value := usecase.Storage.URL(*user.Profile_picture)
+		user.Profile_picture = &value
+	}
+
+	return user, nil
+}
+
+func (usecase *UserUsecase) CheckUserExistance(ctx context.Context, uuid string) error {
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	err = usecase.UserRepository.CheckUserExistance(ctx, tx, uuid)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (usecase *UserUsecase) CheckUserExistanceForNonActivated(ctx context.Context, uuid string) error {
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	err = usecase.UserRepository.CheckUserExistanceForNonActivated(ctx, tx, uuid)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (usecase *UserUsecase) VerifyCode(ctx context.Context, request user.UserVerificationCode, uuid string) error {
+	err := usecase.Validate.Struct(request)
+	if err != nil {
+		respErr := errors.New("invalid request body")
+		usecase.Log.Warn().Err(respErr).Msg(err.Error())
+		return respErr
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	user, err := usecase.UserRepository.VerifyCode(ctx, tx, uuid)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return err
+	}
+
+	if request.Code == user.Verification_code {
+		now := time.Now()
+		fmt.Println("now:", &now)
+		fmt.Println("verification code expired_at:", user.Expired_at)
+		if now.After(*user.Expired_at) {
+			respErr := errors.New("verification code expired")
+			usecase.Log.Warn().Err(respErr).Msg(respErr.Error())
+			return respErr
+		}
+		usecase.UserRepository.ChangeVerificationStatus(ctx, tx, uuid)
+		return nil
+	} else {
+		respErr := errors.New("invalid verification code")
+		usecase.Log.Warn().Err(respErr).Msg(respErr.Error())
+		return respErr
+	}
+}
+
+func (usecase *UserUsecase) FindAll(ctx context.Context, uuid string) ([]user.UserResponse, error) {
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	user := []user.UserResponse{}
+
+	user, err = usecase.UserRepository.FindAll(ctx, tx, uuid)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return user, err
+	}
+
+	for i := range user {
+		if user[i].Profile_picture != nil {
+			user[i].Profile_picture_variants = helper.ImageVariantURLs(usecase.Storage, user[i].Profile_picture)
+			value := usecase.Storage.URL(*user[i].Profile_picture)
+			user[i].Profile_picture = &value
+		}
+	}
+
+	return user, nil
+}
+
+func (usecase *UserUsecase) Update(ctx context.Context, userRequest user.UserPatchRequest, uuid string) (user.UserResponse, error) {
+	userResult, replacedPicture, err := usecase.updateRow(ctx, userRequest, uuid)
+	if err != nil {
+		return userResult, err
+	}
+
+	// the row points at the new picture once updateRow has committed, a file that fails to go is only left unreferenced
+	if replacedPicture != nil {
+		err = helper.RemoveImage(ctx, usecase.Storage, *replacedPicture)
+		if err != nil {
+			respErr := errors.New("failed to remove profile picture")
+			usecase.Log.Warn().Err(respErr).Msg(err.Error())
+		}
+	}
+
+	return userResult, nil
+}
+
+// updateRow applies the update and returns the updated user with the profile picture it replaced or cleared, the files
+// are left for the caller to delete after the commit.
+func (usecase *UserUsecase) updateRow(ctx context.Context, userRequest user.UserPatchRequest, uuid string) (user.UserResponse, *string, error) {
+	patchErrors := []error{
+		helper.ValidatePatchField(usecase.Validate, "name", userRequest.Name, false, "min=5,max=20"),
+		helper.ValidatePatchField(usecase.Validate, "address", userRequest.Address, true, "max=100"),
+		helper.ValidatePatchField(usecase.Validate, "profile_picture", userRequest.Profile_picture, true, "max=255"),
+		helper.ValidatePatchField(usecase.Validate, "origin_province_name", userRequest.Origin_province_name, true, "max=30"),
+		helper.ValidatePatchField(usecase.Validate, "origin_province_id", userRequest.Origin_province_id, true, "min=1"),
+		helper.ValidatePatchField(usecase.Validate, "origin_city_name", userRequest.Origin_city_name, true, "max=30"),
+		helper.ValidatePatchField(usecase.Validate, "origin_city_id", userRequest.Origin_city_id, true, "min=1"),
+	}
+	for _, err := range patchErrors {
+		if err != nil {
+			usecase.Log.Warn().Msg(err.Error())
+			return user.UserResponse{}, nil, err
+		}
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	if userRequest.Name.Set {
+		err = usecase.UserRepository.CheckNameUnique(ctx, tx, userRequest.Name.Value, uuid)
+		if err != nil {
+			usecase.Log.Warn().Msg(err.Error())
+			return user.UserResponse{}, nil, err
+		}
+	}
+
+	oldProfilePicture, _ := usecase.UserRepository.FindProfileById(ctx, tx, uuid)
+
+	now := time.Now()
+
+	usecase.UserRepository.Update(ctx, tx, uuid, userRequest, &now)
+
+	var replacedPicture *string
+	if userRequest.Profile_picture.Set && oldProfilePicture != nil && *oldProfilePicture != userRequest.Profile_picture.Value {
+		replacedPicture = oldProfilePicture
+	}
+
+	userResult, err := usecase.UserRepository.FindByUUID(ctx, tx, uuid)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return userResult, nil, err
+	}
+
+	if userResult.Profile_picture != nil {
+		userResult.Profile_picture_variants = helper.ImageVariantURLs(usecase.Storage, userResult.Profile_picture)
+		value := usecase.Storage.URL(*userResult.Profile_picture)
+		userResult.Profile_picture = &value
+	}
+
+	return userResult, replacedPicture, nil
+}
+
+func (usecase *UserUsecase) ChangePassword(ctx context.Context, userRequest user.UserChangePasswordRequest, uuid string) error {
+	err := usecase.Validate.Struct(userRequest)
+	if err != nil {
+		respErr := errors.New("invalid request body")
+		usecase.Log.Warn().Err(respErr).Msg(err.Error())
+		return respErr
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	currentPassword, err := usecase.UserRepository.FindPasswordById(ctx, tx, uuid)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return err
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(currentPassword), []byte(userRequest.Current_password))
+	if err != nil {
+		respErr := errors.New("wrong password")
+		usecase.Log.Warn().Err(respErr).Msg(err.Error())
+		return respErr
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(userRequest.New_password), bcrypt.DefaultCost)
+	if err != nil {
+		respErr := errors.New("error generating password hash")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	now := time.Now()
+
+	user := domain.User{
+		Id:         uuid,
+		Password:   string(hashedPassword),
+		Updated_at: &now,
+	}
+
+	usecase.UserRepository.UpdatePassword(ctx, tx, user)
+
+	return nil
+}
+
+func (usecase *UserUsecase) RequestEmailChange(ctx context.Context, userRequest user.UserChangeEmailRequest, uuid string) error {
+	err := usecase.Validate.Struct(userRequest)
+	if err != nil {
+		respErr := errors.New("invalid request body")
+		usecase.Log.Warn().Err(respErr).Msg(err.Error())
+		return respErr
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	userResult, err := usecase.UserRepository.FindBasicInfo(ctx, tx, uuid)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return err
+	}
+
+	err = usecase.UserRepository.CheckEmailUnique(ctx, tx, userRequest.Email)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return err
+	}
+
+	code, err := generateVerificationCode()
+	if err != nil {
+		respErr := errors.New("failed to generate verification code")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	now := time.Now()
+	expiredAt := now.Add(5 * time.Minute)
+
+	emailChange := domain.EmailChange{
+		User_id:           uuid,
+		New_email:         userRequest.Email,
+		Verification_code: code,
+		Created_at:        &now,
+		Expired_at:        &expiredAt,
+	}
+
+	usecase.UserRepository.CreateOrUpdateEmailChange(ctx, tx, emailChange)
+
+	usecase.NotificationUsecase.SendEmailChangeVerification(ctx, tx, userResult.Name, userRequest.Email, code)
+
+	return nil
+}
+
+func (usecase *UserUsecase) VerifyEmailChange(ctx context.Context, request user.UserVerificationCode, uuid string) error {
+	err := usecase.Validate.Struct(request)
+	if err != nil {
+		respErr := errors.New("invalid request body")
+		usecase.Log.Warn().Err(respErr).Msg(err.Error())
+		return respErr
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	emailChange, err := usecase.UserRepository.FindEmailChange(ctx, tx, uuid)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return err
+	}
+
+	// the failed attempt is committed together with the error, the transaction only rolls back on a panic
+	if request.Code != emailChange.Verification_code {
+		if emailChange.Attempts+1 >= maxEmailChangeAttempts {
+			usecase.UserRepository.DeleteEmailChange(ctx, tx, uuid)
+			respErr := errors.New("too many wrong codes, request a new email change")
+			usecase.Log.Warn().Msg(respErr.Error())
+			return respErr
+		}
+		usecase.UserRepository.IncrementEmailChangeAttempts(ctx, tx, uuid)
+		respErr := errors.New("invalid verification code")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return respErr
+	}
+
+	now := time.Now()
+	if now.After(*emailChange.Expired_at) {
+		respErr := errors.New("verification code expired")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return respErr
+	}
+
+	err = usecase.UserRepository.CheckEmailUnique(ctx, tx, emailChange.New_email)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return err
+	}
+
+	userResult, err := usecase.UserRepository.FindBasicInfo(ctx, tx, uuid)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return err
+	}
+
+	user := domain.User{
+		Id:         uuid,
+		Email:      emailChange.New_email,
+		Updated_at: &now,
+	}
+
+	usecase.UserRepository.UpdateEmail(ctx, tx, user)
+	usecase.UserRepository.DeleteEmailChange(ctx, tx, uuid)
+
+	usecase.NotificationUsecase.SendEmailChangedNotice(ctx, tx, userResult.Name, userResult.Email, emailChange.New_email)
+
+	return nil
+}
+
+func (usecase *UserUsecase) Delete(ctx context.Context, uuid string) {
+	userProfile := usecase.deleteRow(ctx, uuid)
+
+	// the user is gone once deleteRow has committed, a file that fails to go is only left unreferenced
+	if userProfile != nil {
+		err := helper.RemoveImage(ctx, usecase.Storage, *userProfile)
+		if err != nil {
+			respErr := errors.New("failed to remove profile picture")
+			usecase.Log.Warn().Err(respErr).Msg(err.Error())
+		}
+	}
+}
+
+// deleteRow removes the user and returns their profile picture, the files are left for the caller to delete after the
+// commit.
+func (usecase *UserUsecase) deleteRow(ctx context.Context, uuid string) *string {
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	userProfile, err := usecase.UserRepository.FindProfileById(ctx, tx, uuid)
+	if err != nil {
+		respErr := errors.New("profile picture not found")
+		usecase.Log.Warn().Err(respErr).Msg(err.Error())
+	}
+
+	usecase.UserRepository.Delete(ctx, tx, uuid)
+
+	return userProfile
+}
+
+func (usecase *UserUsecase) AddOrUpdateIdentityCard(ctx context.Context, uuid string, userRequest user.IdentityCardRequest) error {
+	err := usecase.Validate.Struct(userRequest)
+	if err != nil {
+		respErr := errors.New("identity card cannot be empty")
+		usecase.Log.Warn().Err(respErr).Msg(err.Error())
+		return respErr
+	}
+
+	encryptedFile, err := helper.Encrypt(usecase.Config.String("IDENTITY_CARD_KEY"), userRequest.IdentityCard_file)
+	if err != nil {
+		respErr := errors.New("failed to encrypt identity card")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	fileName := googleuuid.New().String() + ".enc"
+
+	err = usecase.PrivateStorage.Put(ctx, fileName, encryptedFile, "application/octet-stream")
+	if err != nil {
+		respErr := errors.New("failed to store identity card")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	previous := usecase.saveIdentityCard(ctx, uuid, fileName)
+
+	// the row points at the new card once saveIdentityCard has committed, so the previous scan is no longer referenced
+	if previous != "" {
+		usecase.removeIdentityCard(ctx, previous)
+	}
+
+	return nil
+}
+
+// saveIdentityCard points the user at a newly stored card and returns the card it replaced.
+func (usecase *UserUsecase) saveIdentityCard(ctx context.Context, uuid string, fileName string) string {
+	tx, err := usecase.DB.Begin()
 	if err != nil {
-		respErr := errors.New("failed to sign a token")
+		respErr := errors.New("failed to start transaction")
 		usecase.Log.Panic().Err(err).Msg(respErr.Error())
 	}
 
-	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	defer helper.CommitOrRollback(tx)
 
-	code := make([]byte, 5)
-	for i := range code {
-		randomIndex, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
-		if err != nil {
-			return "", err
-		}
-		code[i] = charset[randomIndex.Int64()]
-	}
+	previous, _ := usecase.UserRepository.GetIdentityCard(ctx, tx, uuid)
 
-	expiredAt := now.Add(5 * time.Minute)
+	now := time.Now()
 
-	UserVerification := domain.UserVerification{
-		User_id:           user.Id,
-		Verification_code: string(code),
-		Created_at:        &now,
-		Updated_at:        &now,
-		Expired_at:        &expiredAt,
+	user := domain.User{
+		Id:                    uuid,
+		Identity_card_picture: fileName,
+		Updated_at:            &now,
 	}
 
-	usecase.UserRepository.CreateUserVerification(ctx, tx, UserVerification)
-
-	usecase.NotificationUsecase.SendRegisterNotification(ctx, tx, user.Name, user.Email, string(code))
+	usecase.UserRepository.AddOrUpdateIdentityCard(ctx, tx, user)
 
-	return tokenString, nil
+	return previous
 }
 
-func (usecase *UserUsecase) Login(ctx context.Context, request user.UserLoginRequest) (string, error) {
-	err := usecase.Validate.Struct(request)
+// removeIdentityCard deletes a card that no row refers to anymore, from the public store for scans uploaded before
+// encryption and from the private store otherwise.
+func (usecase *UserUsecase) removeIdentityCard(ctx context.Context, identityCard string) {
+	var err error
+	if strings.HasPrefix(identityCard, "/static/") {
+		err = usecase.Storage.Delete(ctx, strings.TrimPrefix(identityCard, "/static/"))
+	} else {
+		err = usecase.PrivateStorage.Delete(ctx, identityCard)
+	}
+
 	if err != nil {
-		respErr := errors.New("invalid request body")
+		respErr := errors.New("failed to remove identity card")
 		usecase.Log.Warn().Err(respErr).Msg(err.Error())
-		return "", respErr
 	}
+}
+
+// EncryptLegacyIdentityCards moves the identity cards uploaded before encryption out of the public store and returns
+// how many were moved and how many are still public. Each card is encrypted into the private store and its row
+// repointed before the plaintext scan is deleted, so running it again after a failure picks up where it stopped.
+func (usecase *UserUsecase) EncryptLegacyIdentityCards(ctx context.Context) (int, int) {
+	legacyCards := usecase.findLegacyIdentityCards(ctx)
+
+	moved := 0
+	for _, legacyCard := range legacyCards {
+		if usecase.encryptLegacyIdentityCard(ctx, legacyCard.Id, legacyCard.Identity_card_picture) {
+			moved++
+		}
+	}
+
+	return moved, len(usecase.findLegacyIdentityCards(ctx))
+}
 
+func (usecase *UserUsecase) findLegacyIdentityCards(ctx context.Context) []domain.User {
 	tx, err := usecase.DB.Begin()
 	if err != nil {
 		respErr := errors.New("failed to start transaction")
@@ -144,43 +1117,56 @@ func (usecase *UserUsecase) Login(ctx context.Context, request user.UserLoginReq
 
 	defer helper.CommitOrRollback(tx)
 
-	userRequest := domain.User{
-		Email:    request.Email,
-		Password: request.Password,
+	return usecase.UserRepository.FindLegacyIdentityCards(ctx, tx)
+}
+
+func (usecase *UserUsecase) encryptLegacyIdentityCard(ctx context.Context, uuid string, legacyCard string) bool {
+	legacyFile, err := usecase.Storage.Get(ctx, strings.TrimPrefix(legacyCard, "/static/"))
+	if err != nil {
+		respErr := errors.New("failed to read identity card")
+		usecase.Log.Warn().Err(respErr).Str("user", uuid).Msg(err.Error())
+		return false
 	}
 
-	user := domain.User{}
-	user, err = usecase.UserRepository.Login(ctx, tx, userRequest.Email)
+	encryptedFile, err := helper.Encrypt(usecase.Config.String("IDENTITY_CARD_KEY"), legacyFile)
 	if err != nil {
-		usecase.Log.Warn().Msg(err.Error())
-		return "", err
+		respErr := errors.New("failed to encrypt identity card")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(userRequest.Password))
+	fileName := googleuuid.New().String() + ".enc"
+
+	err = usecase.PrivateStorage.Put(ctx, fileName, encryptedFile, "application/octet-stream")
 	if err != nil {
-		respErr := errors.New("wrong password")
-		usecase.Log.Warn().Err(respErr).Msg(err.Error())
-		return "", respErr
+		respErr := errors.New("failed to store identity card")
+		usecase.Log.Warn().Err(respErr).Str("user", uuid).Msg(err.Error())
+		return false
 	}
 
-	secretKey := usecase.Config.String("SECRET_KEY")
-	secretKeyByte := []byte(secretKey)
+	if !usecase.replaceIdentityCard(ctx, uuid, legacyCard, fileName) {
+		// the user uploaded a new card in the meantime, which already removed the legacy scan
+		usecase.removeIdentityCard(ctx, fileName)
+		return false
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"id":      user.Id,
-		"expired": time.Date(2030, 10, 10, 12, 0, 0, 0, time.UTC).Unix(),
-	})
+	usecase.removeIdentityCard(ctx, legacyCard)
 
-	tokenString, err := token.SignedString(secretKeyByte)
+	return true
+}
+
+func (usecase *UserUsecase) replaceIdentityCard(ctx context.Context, uuid string, from string, to string) bool {
+	tx, err := usecase.DB.Begin()
 	if err != nil {
-		respErr := errors.New("failed to sign a token")
+		respErr := errors.New("failed to start transaction")
 		usecase.Log.Panic().Err(err).Msg(respErr.Error())
 	}
 
-	return tokenString, nil
+	defer helper.CommitOrRollback(tx)
+
+	return usecase.UserRepository.ReplaceIdentityCard(ctx, tx, uuid, from, to)
 }
 
-func (usecase *UserUsecase) FindByUUID(ctx context.Context, uuid string) (user.UserResponse, error) {
+func (usecase *UserUsecase) GetIdentityCard(ctx context.Context, uuid string) (user.IdentityCardResponse, error) {
 	tx, err := usecase.DB.Begin()
 	if err != nil {
 		respErr := errors.New("failed to start transaction")
@@ -189,24 +1175,26 @@ func (usecase *UserUsecase) FindByUUID(ctx context.Context, uuid string) (user.U
 
 	defer helper.CommitOrRollback(tx)
 
-	user := user.UserResponse{}
-	user, err = usecase.UserRepository.FindByUUID(ctx, tx, uuid)
+	user := user.IdentityCardResponse{}
+	_, err = usecase.UserRepository.GetIdentityCard(ctx, tx, uuid)
 	if err != nil {
 		usecase.Log.Warn().Msg(err.Error())
 		return user, err
 	}
 
-	imageEnv := usecase.Config.String("IMAGE_ENV")
-
-	if user.Profile_picture != nil {
-		value := imageEnv + *user.Profile_picture
-		user.Profile_picture = &value
+	identityCardStatus, err := usecase.UserRepository.FindIdentityCardStatus(ctx, tx, uuid)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return user, err
 	}
 
+	user.IdentityCard_picture = identityCardURL(usecase.Config.String("APP_URL"), uuid)
+	user.IdentityCard_status = identityCardStatus.Identity_card_status
+	user.IdentityCard_reason = identityCardStatus.Identity_card_reason
 	return user, nil
 }
 
-func (usecase *UserUsecase) CheckUserExistance(ctx context.Context, uuid string) error {
+func (usecase *UserUsecase) GetIdentityCardImage(ctx context.Context, accessorUUID string, ownerUUID string) ([]byte, error) {
 	tx, err := usecase.DB.Begin()
 	if err != nil {
 		respErr := errors.New("failed to start transaction")
@@ -215,16 +1203,74 @@ func (usecase *UserUsecase) CheckUserExistance(ctx context.Context, uuid string)
 
 	defer helper.CommitOrRollback(tx)
 
-	err = usecase.UserRepository.CheckUserExistance(ctx, tx, uuid)
+	identityCardResult, err := usecase.UserRepository.GetIdentityCard(ctx, tx, ownerUUID)
 	if err != nil {
 		usecase.Log.Warn().Msg(err.Error())
-		return err
+		return nil, err
 	}
 
-	return nil
+	accessRole := ""
+	if accessorUUID == ownerUUID {
+		accessRole = "Owner"
+	} else if role, err := usecase.UserRepository.FindRoleById(ctx, tx, accessorUUID); err == nil && role == "Admin" {
+		accessRole = "Admin"
+	} else if usecase.OrderRepository.CheckActiveOrderBetween(ctx, tx, accessorUUID, ownerUUID) == nil {
+		accessRole = "Seller"
+	}
+
+	now := time.Now()
+
+	accessLog := domain.IdentityCardAccessLog{
+		Owner_id:    ownerUUID,
+		Accessor_id: accessorUUID,
+		Access_role: accessRole,
+		Granted:     accessRole != "",
+		Created_at:  &now,
+	}
+
+	if !accessLog.Granted {
+		accessLog.Access_role = "None"
+	}
+
+	usecase.UserRepository.CreateIdentityCardAccessLog(ctx, tx, accessLog)
+
+	if !accessLog.Granted {
+		respErr := errors.New("identity card access denied")
+		usecase.Log.Warn().Str("accessor", accessorUUID).Str("owner", ownerUUID).Msg(respErr.Error())
+		return nil, respErr
+	}
+
+	// identity cards uploaded before encryption was introduced stay in the public store until the move at startup or
+	// encrypt-identity-cards has taken them
+	if strings.HasPrefix(identityCardResult, "/static/") {
+		legacyFile, err := usecase.Storage.Get(ctx, strings.TrimPrefix(identityCardResult, "/static/"))
+		if err != nil {
+			respErr := errors.New("failed to read identity card")
+			usecase.Log.Panic().Err(err).Msg(respErr.Error())
+		}
+		return legacyFile, nil
+	}
+
+	encryptedFile, err := usecase.PrivateStorage.Get(ctx, identityCardResult)
+	if err != nil {
+		respErr := errors.New("failed to read identity card")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	identityCardFile, err := helper.Decrypt(usecase.Config.String("IDENTITY_CARD_KEY"), encryptedFile)
+	if err != nil {
+		respErr := errors.New("failed to decrypt identity card")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	return identityCardFile, nil
 }
 
-func (usecase *UserUsecase) CheckUserExistanceForNonActivated(ctx context.Context, uuid string) error {
+func identityCardURL(appURL string, userUUID string) string {
+	return appURL + "/api/identitycard/" + userUUID
+}
+
+func (usecase *UserUsecase) GetMeasurements(ctx context.Context, uuid string) (user.UserMeasurementResponse, error) {
 	tx, err := usecase.DB.Begin()
 	if err != nil {
 		respErr := errors.New("failed to start transaction")
@@ -233,21 +1279,21 @@ func (usecase *UserUsecase) CheckUserExistanceForNonActivated(ctx context.Contex
 
 	defer helper.CommitOrRollback(tx)
 
-	err = usecase.UserRepository.CheckUserExistanceForNonActivated(ctx, tx, uuid)
+	measurement, err := usecase.MeasurementRepository.FindByUserId(ctx, tx, uuid)
 	if err != nil {
 		usecase.Log.Warn().Msg(err.Error())
-		return err
+		return user.UserMeasurementResponse{}, err
 	}
 
-	return nil
+	return userMeasurementResponse(measurement), nil
 }
 
-func (usecase *UserUsecase) VerifyCode(ctx context.Context, request user.UserVerificationCode, uuid string) error {
-	err := usecase.Validate.Struct(request)
+func (usecase *UserUsecase) SaveMeasurements(ctx context.Context, uuid string, userRequest user.UserMeasurementRequest) (user.UserMeasurementResponse, error) {
+	err := usecase.Validate.Struct(userRequest)
 	if err != nil {
 		respErr := errors.New("invalid request body")
 		usecase.Log.Warn().Err(respErr).Msg(err.Error())
-		return respErr
+		return user.UserMeasurementResponse{}, respErr
 	}
 
 	tx, err := usecase.DB.Begin()
@@ -258,31 +1304,24 @@ func (usecase *UserUsecase) VerifyCode(ctx context.Context, request user.UserVer
 
 	defer helper.CommitOrRollback(tx)
 
-	user, err := usecase.UserRepository.VerifyCode(ctx, tx, uuid)
-	if err != nil {
-		usecase.Log.Warn().Msg(err.Error())
-		return err
-	}
+	now := time.Now()
 
-	if request.Code == user.Verification_code {
-		now := time.Now()
-		fmt.Println("now:", &now)
-		fmt.Println("verification code expired_at:", user.Expired_at)
-		if now.After(*user.Expired_at) {
-			respErr := errors.New("verification code expired")
-			usecase.Log.Warn().Err(respErr).Msg(respErr.Error())
-			return respErr
-		}
-		usecase.UserRepository.ChangeVerificationStatus(ctx, tx, uuid)
-		return nil
-	} else {
-		respErr := errors.New("invalid verification code")
-		usecase.Log.Warn().Err(respErr).Msg(respErr.Error())
-		return respErr
+	measurement := domain.UserMeasurement{
+		User_id:    uuid,
+		Chest:      userRequest.Chest,
+		Waist:      userRequest.Waist,
+		Hip:        userRequest.Hip,
+		Height:     userRequest.Height,
+		Shoe_size:  userRequest.Shoe_size,
+		Updated_at: &now,
 	}
+
+	usecase.MeasurementRepository.Save(ctx, tx, measurement)
+
+	return userMeasurementResponse(measurement), nil
 }
 
-func (usecase *UserUsecase) FindAll(ctx context.Context, uuid string) ([]user.UserResponse, error) {
+func (usecase *UserUsecase) GetVacation(ctx context.Context, uuid string) (user.UserVacationResponse, error) {
 	tx, err := usecase.DB.Begin()
 	if err != nil {
 		respErr := errors.New("failed to start transaction")
@@ -291,34 +1330,74 @@ func (usecase *UserUsecase) FindAll(ctx context.Context, uuid string) ([]user.Us
 
 	defer helper.CommitOrRollback(tx)
 
-	user := []user.UserResponse{}
-
-	user, err = usecase.UserRepository.FindAll(ctx, tx, uuid)
+	userResult, err := usecase.UserRepository.FindVacationById(ctx, tx, uuid)
 	if err != nil {
 		usecase.Log.Warn().Msg(err.Error())
-		return user, err
-	}
-
-	imageEnv := usecase.Config.String("IMAGE_ENV")
-
-	for i := range user {
-		if user[i].Profile_picture != nil {
-			value := imageEnv + *user[i].Profile_picture
-			user[i].Profile_picture = &value
-		}
+		return user.UserVacationResponse{}, err
 	}
 
-	return user, nil
+	return userVacationResponse(userResult), nil
 }
 
-func (usecase *UserUsecase) Update(ctx context.Context, userRequest user.UserPatchRequest, uuid string) error {
+func (usecase *UserUsecase) UpdateVacation(ctx context.Context, uuid string, userRequest user.UserVacationRequest) (user.UserVacationResponse, error) {
 	err := usecase.Validate.Struct(userRequest)
 	if err != nil {
 		respErr := errors.New("invalid request body")
 		usecase.Log.Warn().Err(respErr).Msg(err.Error())
-		return respErr
+		return user.UserVacationResponse{}, respErr
+	}
+
+	start, end, err := helper.ParseRentalDates(userRequest.Start_date, userRequest.End_date)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return user.UserVacationResponse{}, err
+	}
+
+	now := time.Now()
+
+	if end.Before(helper.RentalDay(now)) {
+		respErr := errors.New("end_date must not be in the past")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return user.UserVacationResponse{}, respErr
+	}
+
+	if end.After(start.AddDate(0, 0, maxVacationDays-1)) {
+		respErr := fmt.Errorf("a vacation can last at most %d days", maxVacationDays)
+		usecase.Log.Warn().Msg(respErr.Error())
+		return user.UserVacationResponse{}, respErr
+	}
+
+	if userRequest.Message != nil {
+		message := strings.TrimSpace(*userRequest.Message)
+		userRequest.Message = &message
+		if message == "" {
+			userRequest.Message = nil
+		}
+	}
+
+	tx, err := usecase.DB.Begin()
+	if err != nil {
+		respErr := errors.New("failed to start transaction")
+		usecase.Log.Panic().Err(err).Msg(respErr.Error())
+	}
+
+	defer helper.CommitOrRollback(tx)
+
+	userResult := domain.User{
+		Id:               uuid,
+		Vacation_start:   &start,
+		Vacation_end:     &end,
+		Vacation_message: userRequest.Message,
+		Updated_at:       &now,
 	}
 
+	usecase.UserRepository.UpdateVacation(ctx, tx, userResult)
+
+	return userVacationResponse(userResult), nil
+}
+
+// EndVacation reopens the shop right away, orders placed before the vacation are left as they are.
+func (usecase *UserUsecase) EndVacation(ctx context.Context, uuid string) {
 	tx, err := usecase.DB.Begin()
 	if err != nil {
 		respErr := errors.New("failed to start transaction")
@@ -329,43 +1408,45 @@ func (usecase *UserUsecase) Update(ctx context.Context, userRequest user.UserPat
 
 	now := time.Now()
 
-	if userRequest.Profile_picture != nil {
-		user := domain.User{
-			Id:                   uuid,
-			Name:                 *userRequest.Name,
-			Email:                *userRequest.Email,
-			Address:              *userRequest.Address,
-			Profile_picture:      userRequest.Profile_picture,
-			Origin_province_name: *userRequest.Origin_province_name,
-			Origin_province_id:   *userRequest.Origin_province_id,
-			Origin_city_name:     *userRequest.Origin_city_name,
-			Origin_city_id:       *userRequest.Origin_city_id,
-			Updated_at:           &now,
-		}
+	usecase.UserRepository.UpdateVacation(ctx, tx, domain.User{Id: uuid, Updated_at: &now})
+}
 
-		usecase.UserRepository.Update(ctx, tx, user)
+func userVacationResponse(seller domain.User) user.UserVacationResponse {
+	today := helper.RentalDay(time.Now())
 
-		return nil
-	} else {
-		user := domain.User{
-			Id:                   uuid,
-			Name:                 *userRequest.Name,
-			Email:                *userRequest.Email,
-			Address:              *userRequest.Address,
-			Origin_province_name: *userRequest.Origin_province_name,
-			Origin_province_id:   *userRequest.Origin_province_id,
-			Origin_city_name:     *userRequest.Origin_city_name,
-			Origin_city_id:       *userRequest.Origin_city_id,
-			Updated_at:           &now,
-		}
+	start, end, ok := helper.VacationWindow(seller, today)
+	if !ok {
+		return user.UserVacationResponse{}
+	}
 
-		usecase.UserRepository.Update(ctx, tx, user)
+	startDate := start.Format(helper.RentalDateLayout)
+	endDate := end.Format(helper.RentalDateLayout)
 
-		return nil
+	return user.UserVacationResponse{
+		Start_date: &startDate,
+		End_date:   &endDate,
+		Message:    seller.Vacation_message,
+		Active:     !start.After(today),
 	}
 }
 
-func (usecase *UserUsecase) Delete(ctx context.Context, uuid string) {
+func userMeasurementResponse(measurement domain.UserMeasurement) user.UserMeasurementResponse {
+	response := user.UserMeasurementResponse{
+		Chest:     measurement.Chest,
+		Waist:     measurement.Waist,
+		Hip:       measurement.Hip,
+		Height:    measurement.Height,
+		Shoe_size: measurement.Shoe_size,
+	}
+	if measurement.Updated_at != nil {
+		updatedAt := measurement.Updated_at.Format("2006-01-02 15:04:05")
+		response.Updated_at = &updatedAt
+	}
+
+	return response
+}
+
+func (usecase *UserUsecase) FindPendingIdentityCards(ctx context.Context) ([]user.PendingIdentityCardResponse, error) {
 	tx, err := usecase.DB.Begin()
 	if err != nil {
 		respErr := errors.New("failed to start transaction")
@@ -374,32 +1455,49 @@ func (usecase *UserUsecase) Delete(ctx context.Context, uuid string) {
 
 	defer helper.CommitOrRollback(tx)
 
-	userProfile, err := usecase.UserRepository.FindProfileById(ctx, tx, uuid)
+	pendingResult, err := usecase.UserRepository.FindPendingIdentityCards(ctx, tx)
 	if err != nil {
-		respErr := errors.New("profile picture not found")
-		usecase.Log.Warn().Err(respErr).Msg(err.Error())
+		usecase.Log.Warn().Msg(err.Error())
+		return pendingResult, err
 	}
 
-	if userProfile != nil {
-		finalProfilePicturePath := ".." + *userProfile
-		err = os.Remove(finalProfilePicturePath)
-		if err != nil {
-			respErr := errors.New("failed to remove profile picture")
-			usecase.Log.Warn().Err(respErr).Msg(err.Error())
-		}
+	for i := range pendingResult {
+		pendingResult[i].IdentityCard_picture = identityCardURL(usecase.Config.String("APP_URL"), pendingResult[i].User_id)
 	}
 
-	usecase.UserRepository.Delete(ctx, tx, uuid)
+	return pendingResult, nil
 }
 
-func (usecase *UserUsecase) AddOrUpdateIdentityCard(ctx context.Context, uuid string, userRequest user.IdentityCardRequest) error {
-	err := usecase.Validate.Struct(userRequest)
+func (usecase *UserUsecase) ApproveIdentityCard(ctx context.Context, adminUUID string, userUUID string) error {
+	return usecase.reviewIdentityCard(ctx, adminUUID, userUUID, "Approved", nil)
+}
+
+func (usecase *UserUsecase) RejectIdentityCard(ctx context.Context, adminUUID string, userUUID string, request user.IdentityCardRejectRequest) error {
+	err := usecase.Validate.Struct(request)
 	if err != nil {
-		respErr := errors.New("identity card cannot be empty")
+		respErr := errors.New("invalid request body")
 		usecase.Log.Warn().Err(respErr).Msg(err.Error())
 		return respErr
 	}
 
+	return usecase.reviewIdentityCard(ctx, adminUUID, userUUID, "Rejected", &request.Reason)
+}
+
+func (usecase *UserUsecase) reviewIdentityCard(ctx context.Context, adminUUID string, userUUID string, status string, reason *string) error {
+	email, err := usecase.reviewIdentityCardRow(ctx, adminUUID, userUUID, status, reason)
+	if err != nil {
+		return err
+	}
+
+	// a failed email is only logged, the decision has committed and stands
+	usecase.NotificationUsecase.SendEmails([]*gomail.Message{email})
+
+	return nil
+}
+
+// reviewIdentityCardRow records the decision and returns the email telling the user, left for the caller to send after
+// the commit.
+func (usecase *UserUsecase) reviewIdentityCardRow(ctx context.Context, adminUUID string, userUUID string, status string, reason *string) (*gomail.Message, error) {
 	tx, err := usecase.DB.Begin()
 	if err != nil {
 		respErr := errors.New("failed to start transaction")
@@ -408,17 +1506,39 @@ func (usecase *UserUsecase) AddOrUpdateIdentityCard(ctx context.Context, uuid st
 
 	defer helper.CommitOrRollback(tx)
 
-	user := domain.User{
-		Id:                    uuid,
-		Identity_card_picture: *userRequest.IdentityCard_picture,
+	userResult, err := usecase.UserRepository.FindIdentityCardStatus(ctx, tx, userUUID)
+	if err != nil {
+		usecase.Log.Warn().Msg(err.Error())
+		return nil, err
 	}
 
-	usecase.UserRepository.AddOrUpdateIdentityCard(ctx, tx, user)
+	if userResult.Identity_card_status != "Submitted" {
+		respErr := errors.New("identity card is not waiting for review")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return nil, respErr
+	}
 
-	return nil
+	now := time.Now()
+
+	userDomain := domain.User{
+		Id:                     userUUID,
+		Identity_card_status:   status,
+		Identity_card_reason:   reason,
+		Identity_card_reviewer: adminUUID,
+		Identity_card_reviewed: &now,
+	}
+
+	usecase.UserRepository.UpdateIdentityCardStatus(ctx, tx, userDomain)
+
+	var finalReason string
+	if reason != nil {
+		finalReason = *reason
+	}
+
+	return usecase.NotificationUsecase.CreateIdentityCardNotification(ctx, tx, userResult.Name, userResult.Email, status, finalReason), nil
 }
 
-func (usecase *UserUsecase) GetIdentityCard(ctx context.Context, uuid string) (user.IdentityCardResponse, error) {
+func (usecase *UserUsecase) CheckAdmin(ctx context.Context, uuid string) error {
 	tx, err := usecase.DB.Begin()
 	if err != nil {
 		respErr := errors.New("failed to start transaction")
@@ -427,17 +1547,19 @@ func (usecase *UserUsecase) GetIdentityCard(ctx context.Context, uuid string) (u
 
 	defer helper.CommitOrRollback(tx)
 
-	user := user.IdentityCardResponse{}
-	identityCardResult, err := usecase.UserRepository.GetIdentityCard(ctx, tx, uuid)
+	role, err := usecase.UserRepository.FindRoleById(ctx, tx, uuid)
 	if err != nil {
 		usecase.Log.Warn().Msg(err.Error())
-		return user, err
+		return err
 	}
 
-	imageEnv := usecase.Config.String("IMAGE_ENV")
+	if role != "Admin" {
+		respErr := errors.New("admin access required")
+		usecase.Log.Warn().Msg(respErr.Error())
+		return respErr
+	}
 
-	user.IdentityCard_picture = imageEnv + identityCardResult
-	return user, nil
+	return nil
 }
 
 func (usecase *UserUsecase) GetEMoneyAmount(ctx context.Context, uuid string) user.UserEmoneyResponse {
@@ -578,3 +1700,18 @@ func (usecase *UserUsecase) FindSellerAddressDetailByCostumeId(ctx context.Conte
 //
 //	return statusResult
 //}
+
+func generateVerificationCode() (string, error) {
+	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+	code := make([]byte, 5)
+	for i := range code {
+		randomIndex, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+		if err != nil {
+			return "", err
+		}
+		code[i] = charset[randomIndex.Int64()]
+	}
+
+	return string(code), nil
+}
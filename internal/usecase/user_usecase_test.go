@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"cosplayrent/internal/helper"
+	"cosplayrent/internal/model/domain"
+	"testing"
+	"time"
+)
+
+func TestTwoFactorLockout(t *testing.T) {
+	// the RFC 6238 SHA1 test key, whose code at unix time 59 is 287082
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	now := time.Unix(59, 0)
+	if _, ok := helper.ValidateTOTP(secret, "287082", now); !ok {
+		t.Fatal("the RFC code was rejected by ValidateTOTP")
+	}
+
+	userResult := domain.User{Id: "user", Totp_enabled: true}
+	for attempt := 1; attempt <= maxTwoFactorAttempts; attempt++ {
+		if err := checkTwoFactorLockout(userResult, now); err != nil {
+			t.Fatalf("code %d was refused after %d wrong codes: %v", attempt, attempt-1, err)
+		}
+		userResult = countWrongTwoFactorCode(userResult, now)
+	}
+
+	// the 6th code is right, but verifyTwoFactorCode checks the lockout before it looks at the code
+	if err := checkTwoFactorLockout(userResult, now); err == nil {
+		t.Fatalf("a right code was accepted after %d wrong codes", maxTwoFactorAttempts)
+	}
+	if userResult.Totp_attempts != 0 {
+		t.Errorf("attempts = %d after the lockout started, want 0", userResult.Totp_attempts)
+	}
+
+	if err := checkTwoFactorLockout(userResult, now.Add(twoFactorLockout)); err != nil {
+		t.Errorf("codes are still refused once the lockout has passed: %v", err)
+	}
+}
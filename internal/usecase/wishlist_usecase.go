@@ -3,10 +3,14 @@ package usecase
 import (
 	"context"
 	"cosplayrent/internal/helper"
+	"cosplayrent/internal/model/domain"
 	"cosplayrent/internal/model/web/wishlist"
 	"cosplayrent/internal/repository"
+	"cosplayrent/internal/storage"
 	"database/sql"
 	"errors"
+	"time"
+
 	"github.com/go-playground/validator"
 	"github.com/knadh/koanf/v2"
 	"github.com/rs/zerolog"
@@ -15,16 +19,20 @@ import (
 type WishlistUsecase struct {
 	WishlistRepository *repository.WishlistRepository
 	CostumeRepository  *repository.CostumeRepository
+	EventRepository    *repository.CostumeEventRepository
+	Storage            storage.Storage
 	DB                 *sql.DB
 	Validate           *validator.Validate
 	Log                *zerolog.Logger
 	Config             *koanf.Koanf
 }
 
-func NewWishlistUsecase(wishlistRepository *repository.WishlistRepository, costumeRepository *repository.CostumeRepository, DB *sql.DB, validate *validator.Validate, zerolog *zerolog.Logger, koanf *koanf.Koanf) *WishlistUsecase {
+func NewWishlistUsecase(wishlistRepository *repository.WishlistRepository, costumeRepository *repository.CostumeRepository, eventRepository *repository.CostumeEventRepository, storage storage.Storage, DB *sql.DB, validate *validator.Validate, zerolog *zerolog.Logger, koanf *koanf.Koanf) *WishlistUsecase {
 	return &WishlistUsecase{
 		WishlistRepository: wishlistRepository,
 		CostumeRepository:  costumeRepository,
+		EventRepository:    eventRepository,
+		Storage:            storage,
 		DB:                 DB,
 		Validate:           validate,
 		Log:                zerolog,
@@ -48,6 +56,15 @@ func (usecase *WishlistUsecase) AddWishlist(ctx context.Context, uuid string, co
 	}
 
 	usecase.WishlistRepository.AddWishlist(ctx, tx, uuid, costumeid)
+
+	now := time.Now()
+	usecase.EventRepository.Create(ctx, tx, domain.CostumeEvent{
+		Costume_id: costumeid,
+		Type:       "wishlist",
+		User_id:    &uuid,
+		Created_at: &now,
+	})
+
 	return nil
 }
 
@@ -66,7 +83,16 @@ func (usecase *WishlistUsecase) DeleteWishlist(ctx context.Context, uuid string,
 		return err
 	}
 
-	usecase.WishlistRepository.DeleteWishlist(ctx, tx, uuid, costumeid)
+	if usecase.WishlistRepository.DeleteWishlist(ctx, tx, uuid, costumeid) {
+		now := time.Now()
+		usecase.EventRepository.Create(ctx, tx, domain.CostumeEvent{
+			Costume_id: costumeid,
+			Type:       "wishlist_remove",
+			User_id:    &uuid,
+			Created_at: &now,
+		})
+	}
+
 	return nil
 }
 
@@ -102,8 +128,6 @@ func (usecase *WishlistUsecase) FindAllWishListByUserId(ctx context.Context, uui
 		return wishlistResponse, err
 	}
 
-	imageEnv := usecase.Config.String("IMAGE_ENV")
-
 	for i := range wishlistResponse {
 		costumeResult, err := usecase.CostumeRepository.FindById(ctx, tx, wishlistResponse[i].Costume_id)
 		if err != nil {
@@ -115,7 +139,7 @@ func (usecase *WishlistUsecase) FindAllWishListByUserId(ctx context.Context, uui
 		wishlistResponse[i].Costume_price = costumeResult.Price
 		wishlistResponse[i].Costume_size = *costumeResult.Ukuran
 		if costumeResult.Picture != nil {
-			value := imageEnv + *costumeResult.Picture
+			value := usecase.Storage.URL(*costumeResult.Picture)
 			wishlistResponse[i].Costume_picture = &value
 		}
 	}